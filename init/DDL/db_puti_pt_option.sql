@@ -29,7 +29,7 @@ CREATE TABLE `pt_option` (
   `autoload` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否自动加载;默认0不自动加载',
   PRIMARY KEY (`id`) USING BTREE,
   UNIQUE KEY `option_name` (`option_name`) USING BTREE
-) ENGINE=InnoDB AUTO_INCREMENT=36 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci ROW_FORMAT=DYNAMIC;
+) ENGINE=InnoDB AUTO_INCREMENT=48 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci ROW_FORMAT=DYNAMIC;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
 --
@@ -38,7 +38,7 @@ CREATE TABLE `pt_option` (
 
 LOCK TABLES `pt_option` WRITE;
 /*!40000 ALTER TABLE `pt_option` DISABLE KEYS */;
-INSERT INTO `pt_option` VALUES (1,'blog_name','gogogo',1),(2,'blog_description','一个新的 Puti 站点',1),(3,'site_url','http://puti.com',1),(4,'admin_email','example@example.com',1),(5,'users_can_register','off',1),(6,'timezone_string','Asia/Shanghai',1),(7,'default_category','1',0),(8,'default_link_category','0',0),(9,'show_on_front','article',1),(10,'show_on_front_page','about',1),(11,'posts_per_page','10',1),(12,'open_XML','on',1),(13,'article_comment_status','open',1),(14,'page_comment_status','open',1),(15,'comment_need_register','no',1),(16,'show_comment_page','on',1),(17,'comment_per_page','15',1),(18,'comment_page_first','last',1),(19,'comment_page_top','new',1),(20,'comment_before_show','directly',1),(21,'show_avatar','on',1),(22,'image_thumbnail_width','150',0),(23,'image_thumbnail_height','150',0),(24,'image_medium_width','300',0),(25,'image_medium_height','300',0),(26,'image_large_width','1024',0),(27,'image_large_height','1024',0),(28,'site_description','一个新的 Puti 站点。',1),(29,'site_keywords','独立博客,Puti,PutiProject',1),(30,'footer_copyright','<p> Copyright © 2017 <a target=\"_blank\" href=\"https://github.com/puti-projects\">Puti</a> All Rights Reserved. Powered by <a href=\"https://github.com/puti-projects/puti\" target=\"_blank\" rel=\"nofollow\">Puti</a></p>',1),(31,'show_project','1',1),(32,'github_user','',0),(33,'github_show_repo','',0),(34,'site_language','简体中文',1),(35,'current_theme','Lin',1);
+INSERT INTO `pt_option` VALUES (1,'blog_name','gogogo',1),(2,'blog_description','一个新的 Puti 站点',1),(3,'site_url','http://puti.com',1),(4,'admin_email','example@example.com',1),(5,'users_can_register','off',1),(6,'timezone_string','Asia/Shanghai',1),(7,'default_category','1',0),(8,'default_link_category','0',0),(9,'show_on_front','article',1),(10,'show_on_front_page','about',1),(11,'posts_per_page','10',1),(12,'open_XML','on',1),(13,'article_comment_status','open',1),(14,'page_comment_status','open',1),(15,'comment_need_register','no',1),(16,'show_comment_page','on',1),(17,'comment_per_page','15',1),(18,'comment_page_first','last',1),(19,'comment_page_top','new',1),(20,'comment_before_show','directly',1),(21,'show_avatar','on',1),(22,'image_thumbnail_width','150',0),(23,'image_thumbnail_height','150',0),(24,'image_medium_width','300',0),(25,'image_medium_height','300',0),(26,'image_large_width','1024',0),(27,'image_large_height','1024',0),(28,'site_description','一个新的 Puti 站点。',1),(29,'site_keywords','独立博客,Puti,PutiProject',1),(30,'footer_copyright','<p> Copyright © 2017 <a target=\"_blank\" href=\"https://github.com/puti-projects\">Puti</a> All Rights Reserved. Powered by <a href=\"https://github.com/puti-projects/puti\" target=\"_blank\" rel=\"nofollow\">Puti</a></p>',1),(31,'show_project','1',1),(32,'github_user','',0),(33,'github_show_repo','',0),(34,'site_language','简体中文',1),(35,'current_theme','Lin',1),(36,'robots_txt','',1),(37,'default_role','subscriber',1),(38,'image_strip_location','on',0),(39,'revisions_to_keep','50',0),(40,'markdown_gfm_table','on',1),(41,'markdown_footnotes','on',1),(42,'markdown_toc','off',1),(43,'markdown_emoji','on',1),(44,'markdown_math','on',1),(45,'markdown_mermaid','on',1),(46,'markdown_heading_anchor','off',1),(47,'markdown_code_style','github',1);
 /*!40000 ALTER TABLE `pt_option` ENABLE KEYS */;
 UNLOCK TABLES;
 /*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;
@@ -31,7 +31,7 @@ CREATE TABLE `pt_post` (
   `content_html` longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT 'html格式文章内容',
   `slug` varchar(200) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '缩略名（用于url中展示）',
   `parent_id` int unsigned NOT NULL DEFAULT '0' COMMENT '父id（如果有）',
-  `status` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT 'publish' COMMENT '状态:publish,draft,deleted',
+  `status` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT 'publish' COMMENT '状态:publish,scheduled,draft,deleted',
   `comment_status` tinyint(1) NOT NULL DEFAULT '1' COMMENT '评论状态(是否开启);默认1开启；0关闭',
   `if_top` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否置顶；1置顶',
   `guid` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '唯一链接',
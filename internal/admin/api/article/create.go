@@ -4,16 +4,13 @@ import (
 	"github.com/puti-projects/puti/internal/admin/api"
 	"github.com/puti-projects/puti/internal/admin/service"
 	"github.com/puti-projects/puti/internal/pkg/errno"
-	"github.com/puti-projects/puti/internal/pkg/token"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Create create article (published or draft) handler
 func Create(c *gin.Context) {
-	// get token and parse
-	t := c.Query("token")
-	userContext, err := token.ParseToken(t)
+	loginUser := api.GetLoginUser(c)
 
 	var r service.ArticleCreateRequest
 	svc := service.New(c.Request.Context())
@@ -28,7 +25,13 @@ func Create(c *gin.Context) {
 		return
 	}
 
-	rsp, err := svc.CreateArticle(&r, userContext.ID)
+	// contributor can only save article as draft
+	if err := svc.CheckPublishPermission(r.Status, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	rsp, err := svc.CreateArticle(&r, loginUser.ID)
 	if err != nil {
 		api.SendResponse(c, errno.ErrArticleCreateFailed, nil)
 		return
@@ -50,7 +53,7 @@ func checkCreateParam(r *service.ArticleCreateRequest) error {
 		return errno.New(errno.ErrValidation, nil).Add("Status can not be empty.")
 	}
 
-	if r.Status != "publish" && r.Status != "draft" {
+	if r.Status != "publish" && r.Status != "scheduled" && r.Status != "draft" {
 		return errno.New(errno.ErrValidation, nil).Add("Status is incorrect.")
 	}
 
@@ -15,6 +15,12 @@ func Delete(c *gin.Context) {
 	articleID, _ := strconv.Atoi(c.Param("id"))
 
 	svc := service.New(c.Request.Context())
+	loginUser := api.GetLoginUser(c)
+	if err := svc.CheckPostPermission(uint64(articleID), loginUser.ID, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
 	if err := svc.DeletePost("article", uint64(articleID)); err != nil {
 		api.SendResponse(c, errno.ErrDatabase, nil)
 		return
@@ -15,6 +15,12 @@ func Get(c *gin.Context) {
 	articleID, _ := strconv.Atoi(c.Param("id"))
 
 	svc := service.New(c.Request.Context())
+	loginUser := api.GetLoginUser(c)
+	if err := svc.CheckPostPermission(uint64(articleID), loginUser.ID, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
 	article, err := svc.GetArticleDetail(uint64(articleID))
 	if err != nil {
 		api.SendResponse(c, errno.ErrArticleNotFount, nil)
@@ -0,0 +1,78 @@
+package article
+
+import (
+	"strconv"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListRevision get revision list of the article
+func ListRevision(c *gin.Context) {
+	articleID, _ := strconv.Atoi(c.Param("id"))
+
+	svc := service.New(c.Request.Context())
+	loginUser := api.GetLoginUser(c)
+	if err := svc.CheckPostPermission(uint64(articleID), loginUser.ID, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	revisions, err := svc.ListPostRevisions(model.PostTypeArticle, uint64(articleID))
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, revisions)
+}
+
+// DiffRevision compare two revisions of the article by the query params "from" and "to"
+func DiffRevision(c *gin.Context) {
+	articleID, _ := strconv.Atoi(c.Param("id"))
+	from, _ := strconv.Atoi(c.Query("from"))
+	to, _ := strconv.Atoi(c.Query("to"))
+	if from <= 0 || to <= 0 {
+		api.SendResponse(c, errno.New(errno.ErrValidation, nil).Add("need revision id of from and to."), nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	loginUser := api.GetLoginUser(c)
+	if err := svc.CheckPostPermission(uint64(articleID), loginUser.ID, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	rsp, err := svc.DiffPostRevisions(model.PostTypeArticle, uint64(articleID), uint64(from), uint64(to))
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
+
+// RestoreRevision restore the article to the revision
+func RestoreRevision(c *gin.Context) {
+	articleID, _ := strconv.Atoi(c.Param("id"))
+	revisionID, _ := strconv.Atoi(c.Param("rid"))
+
+	svc := service.New(c.Request.Context())
+	loginUser := api.GetLoginUser(c)
+	if err := svc.CheckPostPermission(uint64(articleID), loginUser.ID, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	if err := svc.RestorePostRevision(model.PostTypeArticle, uint64(articleID), uint64(revisionID), loginUser.ID); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
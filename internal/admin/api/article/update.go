@@ -31,6 +31,17 @@ func Update(c *gin.Context) {
 		return
 	}
 
+	// authors can only edit their own articles, and contributors can not publish
+	loginUser := api.GetLoginUser(c)
+	if err := svc.CheckPostPermission(articleID, loginUser.ID, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+	if err := svc.CheckPublishPermission(r.Status, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
 	if r.Status == "deleted" {
 		if err := svc.TrashPost(articleID); err != nil {
 			api.SendResponse(c, err, nil)
@@ -42,7 +53,7 @@ func Update(c *gin.Context) {
 			return
 		}
 	} else {
-		if err := svc.UpdateArticle(&r); err != nil {
+		if err := svc.UpdateArticle(&r, loginUser.ID); err != nil {
 			api.SendResponse(c, err, nil)
 			return
 		}
@@ -65,7 +76,7 @@ func checkUpdateParam(r *service.ArticleUpdateRequest, articleID uint64) error {
 		return errno.New(errno.ErrValidation, nil).Add("need status.")
 	}
 
-	if r.Status != "publish" && r.Status != "draft" && r.Status != "deleted" && r.Status != "restore" {
+	if r.Status != "publish" && r.Status != "scheduled" && r.Status != "draft" && r.Status != "deleted" && r.Status != "restore" {
 		return errno.New(errno.ErrValidation, nil).Add("error status.")
 	}
 
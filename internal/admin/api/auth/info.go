@@ -8,12 +8,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Info get an user by the user identifier.
+// Info get the login user.
 func Info(c *gin.Context) {
-	t := c.Query("token")
+	loginUser := api.GetLoginUser(c)
 
 	svc := service.New(c.Request.Context())
-	user, err := svc.GetUserByToken(t)
+	user, err := svc.GetUser(loginUser.Username)
 	if err != nil {
 		api.SendResponse(c, errno.ErrUserNotFound, nil)
 		return
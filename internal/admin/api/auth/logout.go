@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logout revoke the token of the request and the refresh token handler
+func Logout(c *gin.Context) {
+	// refresh token is optional
+	var r service.LogoutRequest
+	_ = c.ShouldBind(&r)
+
+	ctx, err := token.ParseRequest(c)
+	if err != nil {
+		api.SendResponse(c, errno.ErrTokenInvalid, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	svc.Logout(ctx, r.RefreshToken)
+
+	api.SendResponse(c, nil, nil)
+}
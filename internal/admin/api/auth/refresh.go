@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Refresh get a new token pair by the refresh token handler
+func Refresh(c *gin.Context) {
+	var r service.RefreshTokenRequest
+	if err := c.ShouldBind(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	token, err := svc.RefreshToken(c, r.RefreshToken)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, token)
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register is the public registration handler
+func Register(c *gin.Context) {
+	var r service.RegisterRequest
+	if err := c.ShouldBind(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	rsp, err := svc.Register(&r)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
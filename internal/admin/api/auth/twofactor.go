@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginTwoFactor the second login step handler, verify the code and issue the token
+func LoginTwoFactor(c *gin.Context) {
+	var r service.TwoFactorLoginRequest
+	if err := c.ShouldBind(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	token, err := svc.LoginTwoFactor(c, &r)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, token)
+}
+
+// TwoFactorStatus two-factor authentication status of login user handler
+func TwoFactorStatus(c *gin.Context) {
+	svc := service.New(c.Request.Context())
+	rsp, err := svc.GetTwoFactorStatus(api.GetLoginUser(c).ID)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
+
+// TwoFactorEnroll generate TOTP secret for login user handler
+func TwoFactorEnroll(c *gin.Context) {
+	loginUser := api.GetLoginUser(c)
+
+	svc := service.New(c.Request.Context())
+	rsp, err := svc.EnrollTwoFactor(loginUser.ID, loginUser.Username)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
+
+// TwoFactorActivate enable two-factor authentication of login user handler
+func TwoFactorActivate(c *gin.Context) {
+	var r service.TwoFactorCodeRequest
+	if err := c.ShouldBind(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	rsp, err := svc.ActivateTwoFactor(api.GetLoginUser(c).ID, r.Code)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
+
+// TwoFactorRecoveryCodes regenerate recovery codes of login user handler
+func TwoFactorRecoveryCodes(c *gin.Context) {
+	var r service.TwoFactorCodeRequest
+	if err := c.ShouldBind(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	rsp, err := svc.RegenerateRecoveryCodes(api.GetLoginUser(c).ID, r.Code)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
+
+// TwoFactorDisable disable two-factor authentication of login user handler
+func TwoFactorDisable(c *gin.Context) {
+	var r service.TwoFactorCodeRequest
+	if err := c.ShouldBind(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	if err := svc.DisableTwoFactor(api.GetLoginUser(c).ID, r.Code); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
@@ -0,0 +1,23 @@
+package comment
+
+import (
+	"strconv"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Delete delete the comment permanently; replies are moved to its parent
+func Delete(c *gin.Context) {
+	commentID, _ := strconv.Atoi(c.Param("id"))
+
+	svc := service.New(c.Request.Context())
+	if err := svc.DeleteComment(uint64(commentID)); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
@@ -0,0 +1,25 @@
+package comment
+
+import (
+	"strconv"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Detail get comment detail handler
+func Detail(c *gin.Context) {
+	commentID, _ := strconv.Atoi(c.Param("id"))
+
+	svc := service.New(c.Request.Context())
+	comment, err := svc.GetCommentDetail(uint64(commentID))
+	if err != nil {
+		api.SendResponse(c, errno.ErrCommentNotFound, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, comment)
+}
@@ -0,0 +1,27 @@
+package comment
+
+import (
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// List comment list handler
+func List(c *gin.Context) {
+	var r service.CommentListRequest
+	if err := c.ShouldBind(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	rsp, err := svc.ListComment(&r)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
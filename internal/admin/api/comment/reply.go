@@ -0,0 +1,33 @@
+package comment
+
+import (
+	"strconv"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reply reply comment as the login user handler
+func Reply(c *gin.Context) {
+	commentID, _ := strconv.Atoi(c.Param("id"))
+
+	loginUser := api.GetLoginUser(c)
+
+	var r service.CommentReplyRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	ID, err := svc.ReplyComment(uint64(commentID), &r, loginUser.ID, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, map[string]uint64{"id": ID})
+}
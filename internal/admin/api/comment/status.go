@@ -0,0 +1,26 @@
+package comment
+
+import (
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Status approve, unapprove, spam or trash comments in bulk handler
+func Status(c *gin.Context) {
+	var r service.CommentStatusRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	if err := svc.UpdateCommentStatus(&r); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
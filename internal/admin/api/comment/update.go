@@ -0,0 +1,30 @@
+package comment
+
+import (
+	"strconv"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Update edit comment handler
+func Update(c *gin.Context) {
+	commentID, _ := strconv.Atoi(c.Param("id"))
+
+	var r service.CommentUpdateRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	if err := svc.UpdateComment(uint64(commentID), &r); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,6 +16,43 @@ type Response struct {
 	Data    interface{} `json:"data"`
 }
 
+// LoginUserKey the key of login user in gin context, set by the auth middleware
+const LoginUserKey = "LoginUser"
+
+// LoginUser the user who sends the api request
+// Scopes is only set when the request is authenticated by personal api token
+type LoginUser struct {
+	ID       uint64
+	Username string
+	Role     string
+	Scopes   []string
+}
+
+// Can check if the login user has the capability
+func (u *LoginUser) Can(capability rbac.Capability) bool {
+	return rbac.Can(u.Role, capability)
+}
+
+// Allow check if the login user has the capability and the request is allowed by the api token scopes
+func (u *LoginUser) Allow(capability rbac.Capability, write bool) bool {
+	if !u.Can(capability) {
+		return false
+	}
+
+	return u.Scopes == nil || rbac.ScopesAllow(u.Scopes, capability, write)
+}
+
+// GetLoginUser get the login user from gin context
+// it returns an empty user without any capability if the request is not authenticated
+func GetLoginUser(c *gin.Context) *LoginUser {
+	if v, exists := c.Get(LoginUserKey); exists {
+		if u, ok := v.(*LoginUser); ok {
+			return u
+		}
+	}
+	return &LoginUser{}
+}
+
 // SendResponse send request's response
 func SendResponse(c *gin.Context, err error, data interface{}) {
 	code, message := errno.DecodeErr(err)
@@ -5,18 +5,11 @@ import (
 	"github.com/puti-projects/puti/internal/admin/api"
 	"github.com/puti-projects/puti/internal/admin/service"
 	"github.com/puti-projects/puti/internal/pkg/errno"
-	"github.com/puti-projects/puti/internal/pkg/token"
 )
 
 // Create create knowledge item handler
 func Create(c *gin.Context) {
-	// get token and parse
-	t := c.Query("token")
-	userContext, err := token.ParseToken(t)
-	if err != nil {
-		api.SendResponse(c, errno.ErrTokenInvalid, nil)
-		return
-	}
+	loginUser := api.GetLoginUser(c)
 
 	var r service.KnowledgeItemCreateRequest
 	if err := c.ShouldBind(&r); err != nil {
@@ -31,7 +24,7 @@ func Create(c *gin.Context) {
 	}
 
 	svc := service.New(c.Request.Context())
-	rsp, err := svc.CreateKnowledgeItem(&r, userContext.ID)
+	rsp, err := svc.CreateKnowledgeItem(&r, loginUser.ID)
 	if err != nil {
 		api.SendResponse(c, errno.ErrKnowledgeItemCreateFailed, nil)
 		return
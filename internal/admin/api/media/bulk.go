@@ -0,0 +1,76 @@
+package media
+
+import (
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Move move media into a folder in bulk handler
+func Move(c *gin.Context) {
+	var r service.MediaBulkRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	if err := checkBulkPermission(c, svc, r.IDs); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	if err := svc.MoveMedia(&r); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
+
+// BulkDelete delete media with their files in bulk handler
+func BulkDelete(c *gin.Context) {
+	var r service.MediaBulkRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	if err := checkBulkPermission(c, svc, r.IDs); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	if err := svc.BulkDeleteMedia(&r); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
+
+// Folder list folders which have media handler
+func Folder(c *gin.Context) {
+	svc := service.New(c.Request.Context())
+	folders, err := svc.ListMediaFolder()
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, folders)
+}
+
+// checkBulkPermission check the login user can edit all the media, nothing is done if any is denied
+func checkBulkPermission(c *gin.Context, svc service.Service, mediaIDs []uint64) error {
+	loginUser := api.GetLoginUser(c)
+	for _, id := range mediaIDs {
+		if err := svc.CheckMediaPermission(id, loginUser.ID, loginUser.Role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
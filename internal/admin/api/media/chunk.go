@@ -0,0 +1,101 @@
+package media
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateChunkUpload start a chunked upload
+// The client sends the chunks by AppendChunkUpload, and calls CompleteChunkUpload when all chunks are sent.
+func CreateChunkUpload(c *gin.Context) {
+	var r service.ChunkUploadCreateRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	info, err := svc.CreateChunkUpload(api.GetLoginUser(c).ID, &r)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, info)
+}
+
+// ChunkUploadStatus get the offset of the chunked upload to resume it
+func ChunkUploadStatus(c *gin.Context) {
+	svc := service.New(c.Request.Context())
+	info, err := svc.GetChunkUpload(api.GetLoginUser(c).ID, c.Param("id"))
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, info)
+}
+
+// AppendChunkUpload append the chunk in the request body
+// The offset of the chunk is in the Upload-Offset header, and the optional Upload-Checksum header is
+// "sha256 <hex>" of the chunk.
+func AppendChunkUpload(c *gin.Context) {
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		api.SendResponse(c, errno.New(errno.ErrValidation, nil).Add("Upload-Offset is required."), nil)
+		return
+	}
+
+	var checksum string
+	if h := c.GetHeader("Upload-Checksum"); h != "" {
+		parts := strings.Fields(h)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "sha256") {
+			api.SendResponse(c, errno.New(errno.ErrValidation, nil).Add("Upload-Checksum must be sha256."), nil)
+			return
+		}
+		checksum = parts[1]
+	}
+
+	svc := service.New(c.Request.Context())
+	info, err := svc.AppendChunkUpload(api.GetLoginUser(c).ID, c.Param("id"), offset, checksum, c.Request.Body)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, info)
+}
+
+// CompleteChunkUpload verify the uploaded file and save it as media
+func CompleteChunkUpload(c *gin.Context) {
+	svc := service.New(c.Request.Context())
+	ID, GUID, err := svc.CompleteChunkUpload(api.GetLoginUser(c).ID, c.Param("id"))
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	rsp := service.MediaUploadResponse{
+		ID:  ID,
+		URL: storage.URL(GUID),
+	}
+	api.SendResponse(c, nil, rsp)
+}
+
+// AbortChunkUpload cancel the chunked upload
+func AbortChunkUpload(c *gin.Context) {
+	svc := service.New(c.Request.Context())
+	if err := svc.AbortChunkUpload(api.GetLoginUser(c).ID, c.Param("id")); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
@@ -9,11 +9,17 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Delete delete the media info with deleted_at field (not file delete)
+// Delete delete the media info with deleted_at field, and remove the file and its image sizes
 func Delete(c *gin.Context) {
 	mediaID, _ := strconv.Atoi(c.Param("id"))
 
 	svc := service.New(c.Request.Context())
+	loginUser := api.GetLoginUser(c)
+	if err := svc.CheckMediaPermission(uint64(mediaID), loginUser.ID, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
 	if err := svc.DeleteMedia(uint64(mediaID)); err != nil {
 		api.SendResponse(c, err, nil)
 		return
@@ -17,7 +17,7 @@ func List(c *gin.Context) {
 	}
 
 	svc := service.New(c.Request.Context())
-	infos, count, err := svc.ListMedia(r.Limit, r.Page)
+	infos, count, err := svc.ListMedia(&r)
 	if err != nil {
 		api.SendResponse(c, err, nil)
 		return
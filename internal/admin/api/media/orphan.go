@@ -0,0 +1,39 @@
+package media
+
+import (
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Orphan scan orphan files and media whose file is missing
+func Orphan(c *gin.Context) {
+	svc := service.New(c.Request.Context())
+	result, err := svc.ScanMediaOrphans()
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, result)
+}
+
+// CleanOrphan remove the orphan files and delete the media whose file is missing
+func CleanOrphan(c *gin.Context) {
+	var r service.MediaOrphanCleanRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	result, err := svc.CleanMediaOrphans(&r)
+	if err != nil {
+		api.SendResponse(c, err, result)
+		return
+	}
+
+	api.SendResponse(c, nil, result)
+}
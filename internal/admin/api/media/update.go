@@ -27,6 +27,12 @@ func Update(c *gin.Context) {
 	}
 
 	svc := service.New(c.Request.Context())
+	loginUser := api.GetLoginUser(c)
+	if err := svc.CheckMediaPermission(uint64(userID), loginUser.ID, loginUser.Role); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
 	if err := svc.UpdateMedia(&r, userID); err != nil {
 		api.SendResponse(c, err, nil)
 	}
@@ -1,16 +1,19 @@
 package media
 
 import (
+	"strconv"
+
 	"github.com/puti-projects/puti/internal/admin/api"
 	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Upload file upload handler
 func Upload(c *gin.Context) {
-	// get userId and file
-	userID := c.PostForm("userId")
+	// the uploader is always the login user
+	userID := strconv.FormatUint(api.GetLoginUser(c).ID, 10)
 	usage := c.DefaultPostForm("usage", "common")
 	file, _ := c.FormFile("file")
 
@@ -18,11 +21,12 @@ func Upload(c *gin.Context) {
 	ID, GUID, err := svc.UploadMedia(c, userID, usage, file)
 	if err != nil {
 		api.SendResponse(c, err, nil)
+		return
 	}
 
 	rsp := service.MediaUploadResponse{
 		ID:  ID,
-		URL: GUID,
+		URL: storage.URL(GUID),
 	}
 	api.SendResponse(c, nil, rsp)
 }
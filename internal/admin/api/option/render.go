@@ -0,0 +1,20 @@
+package option
+
+import (
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Render render all articles and pages from markdown again with the current options
+func Render(c *gin.Context) {
+	svc := service.New(c.Request.Context())
+	rsp, err := svc.RenderPosts()
+	if err != nil {
+		api.SendResponse(c, err, rsp)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
@@ -4,16 +4,13 @@ import (
 	"github.com/puti-projects/puti/internal/admin/api"
 	"github.com/puti-projects/puti/internal/admin/service"
 	"github.com/puti-projects/puti/internal/pkg/errno"
-	"github.com/puti-projects/puti/internal/pkg/token"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Create add new page
 func Create(c *gin.Context) {
-	// get token and parse
-	t := c.Query("token")
-	userContext, err := token.ParseToken(t)
+	loginUser := api.GetLoginUser(c)
 
 	var r service.PageCreateRequest
 	if err := c.Bind(&r); err != nil {
@@ -29,7 +26,7 @@ func Create(c *gin.Context) {
 		return
 	}
 
-	rsp, err := svc.CreatePage(&r, userContext.ID)
+	rsp, err := svc.CreatePage(&r, loginUser.ID)
 	if err != nil {
 		api.SendResponse(c, errno.ErrPageCreateFailed, nil)
 		return
@@ -51,7 +48,7 @@ func checkCreateParam(svc *service.Service, r *service.PageCreateRequest) error
 		return errno.New(errno.ErrValidation, nil).Add("Status can not be empty.")
 	}
 
-	if r.Status != "publish" && r.Status != "draft" {
+	if r.Status != "publish" && r.Status != "scheduled" && r.Status != "draft" {
 		return errno.New(errno.ErrValidation, nil).Add("Status is incorrect.")
 	}
 
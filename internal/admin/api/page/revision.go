@@ -0,0 +1,60 @@
+package page
+
+import (
+	"strconv"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListRevision get revision list of the page
+func ListRevision(c *gin.Context) {
+	pageID, _ := strconv.Atoi(c.Param("id"))
+
+	svc := service.New(c.Request.Context())
+	revisions, err := svc.ListPostRevisions(model.PostTypePage, uint64(pageID))
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, revisions)
+}
+
+// DiffRevision compare two revisions of the page by the query params "from" and "to"
+func DiffRevision(c *gin.Context) {
+	pageID, _ := strconv.Atoi(c.Param("id"))
+	from, _ := strconv.Atoi(c.Query("from"))
+	to, _ := strconv.Atoi(c.Query("to"))
+	if from <= 0 || to <= 0 {
+		api.SendResponse(c, errno.New(errno.ErrValidation, nil).Add("need revision id of from and to."), nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	rsp, err := svc.DiffPostRevisions(model.PostTypePage, uint64(pageID), uint64(from), uint64(to))
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
+
+// RestoreRevision restore the page to the revision
+func RestoreRevision(c *gin.Context) {
+	pageID, _ := strconv.Atoi(c.Param("id"))
+	revisionID, _ := strconv.Atoi(c.Param("rid"))
+
+	svc := service.New(c.Request.Context())
+	if err := svc.RestorePostRevision(model.PostTypePage, uint64(pageID), uint64(revisionID), api.GetLoginUser(c).ID); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
@@ -43,7 +43,7 @@ func Update(c *gin.Context) {
 			return
 		}
 	} else {
-		if err := svc.UpdatePage(&r); err != nil {
+		if err := svc.UpdatePage(&r, api.GetLoginUser(c).ID); err != nil {
 			api.SendResponse(c, err, nil)
 			return
 		}
@@ -66,11 +66,11 @@ func checkUpdateParam(svc *service.Service, r *service.PageUpdateRequest, pageID
 		return errno.New(errno.ErrValidation, nil).Add("need status.")
 	}
 
-	if r.Status != "publish" && r.Status != "draft" && r.Status != "deleted" && r.Status != "restore" {
+	if r.Status != "publish" && r.Status != "scheduled" && r.Status != "draft" && r.Status != "deleted" && r.Status != "restore" {
 		return errno.New(errno.ErrValidation, nil).Add("error status.")
 	}
 
-	if r.Status == "publish" || r.Status == "draft" {
+	if r.Status == "publish" || r.Status == "scheduled" || r.Status == "draft" {
 		if isExist := svc.CheckPageSlugExist(r.ID, r.Slug); isExist == true {
 			return errno.New(errno.ErrSlugExist, nil)
 		}
@@ -1,8 +1,12 @@
 package user
 
 import (
+	"strconv"
+
 	"github.com/puti-projects/puti/internal/admin/api"
 	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +16,13 @@ func Avatar(c *gin.Context) {
 	userID := c.PostForm("userId")
 	file, _ := c.FormFile("img")
 
+	// users can only change their own avatar unless they can edit users
+	loginUser := api.GetLoginUser(c)
+	if userID != strconv.FormatUint(loginUser.ID, 10) && !loginUser.Can(rbac.CapEditUsers) {
+		api.SendResponse(c, errno.ErrPermissionDenied, nil)
+		return
+	}
+
 	svc := service.New(c.Request.Context())
 	if err := svc.UpdateUserAvatar(c, userID, file); err != nil {
 		api.SendResponse(c, err, nil)
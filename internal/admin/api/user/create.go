@@ -4,6 +4,7 @@ import (
 	"github.com/puti-projects/puti/internal/admin/api"
 	"github.com/puti-projects/puti/internal/admin/service"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -62,7 +63,7 @@ func checkParam(r *service.UserCreateRequest) error {
 		return errno.New(errno.ErrValidation, nil).Add("role is empty.")
 	}
 
-	if r.Role != "administrator" && r.Role != "writer" && r.Role != "subscriber" {
+	if !rbac.IsRole(r.Role) {
 		return errno.New(errno.ErrValidation, nil).Add("role is incorrect.")
 	}
 
@@ -4,6 +4,7 @@ import (
 	"github.com/puti-projects/puti/internal/admin/api"
 	"github.com/puti-projects/puti/internal/admin/service"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +13,13 @@ import (
 func Get(c *gin.Context) {
 	username := c.Param("username")
 
+	// users can only get their own profile unless they can edit users
+	loginUser := api.GetLoginUser(c)
+	if username != loginUser.Username && !loginUser.Can(rbac.CapEditUsers) {
+		api.SendResponse(c, errno.ErrPermissionDenied, nil)
+		return
+	}
+
 	svc := service.New(c.Request.Context())
 	user, err := svc.GetUser(username)
 	if err != nil {
@@ -0,0 +1,95 @@
+package user
+
+import (
+	"strconv"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListToken personal api token list of user handler
+func ListToken(c *gin.Context) {
+	userID, err := getTokenUserID(c)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	infos, err := svc.ListAPIToken(userID)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, infos)
+}
+
+// CreateToken create personal api token for user handler
+// the token is only shown in the response once
+func CreateToken(c *gin.Context) {
+	userID, err := getTokenUserID(c)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	var r service.APITokenCreateRequest
+	if err := c.ShouldBind(&r); err != nil {
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	rsp, err := svc.CreateAPIToken(userID, &r)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, rsp)
+}
+
+// DeleteToken delete personal api token of user handler
+func DeleteToken(c *gin.Context) {
+	userID, err := getTokenUserID(c)
+	if err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	tokenID, _ := strconv.Atoi(c.Param("tokenId"))
+
+	svc := service.New(c.Request.Context())
+	if err := svc.DeleteAPIToken(userID, uint64(tokenID)); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
+
+// getTokenUserID get the user id in path and check if the login user can manage the tokens of the user
+// the param is named "username" in GET and POST routes since it shares the wildcard with "/user/:username"
+func getTokenUserID(c *gin.Context) (uint64, error) {
+	param := c.Param("id")
+	if param == "" {
+		param = c.Param("username")
+	}
+	userID, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, errno.New(errno.ErrValidation, err).Add("error id.")
+	}
+
+	// users can only manage their own tokens unless they can edit users
+	loginUser := api.GetLoginUser(c)
+	if userID != loginUser.ID && !loginUser.Can(rbac.CapEditUsers) {
+		return 0, errno.ErrPermissionDenied
+	}
+
+	return userID, nil
+}
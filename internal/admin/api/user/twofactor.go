@@ -0,0 +1,23 @@
+package user
+
+import (
+	"strconv"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResetTwoFactor remove two-factor authentication of user handler, use for the user who lost the authenticator
+func ResetTwoFactor(c *gin.Context) {
+	userID, _ := strconv.Atoi(c.Param("id"))
+
+	svc := service.New(c.Request.Context())
+	if err := svc.ResetTwoFactor(uint64(userID)); err != nil {
+		api.SendResponse(c, err, nil)
+		return
+	}
+
+	api.SendResponse(c, nil, nil)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/puti-projects/puti/internal/admin/api"
 	"github.com/puti-projects/puti/internal/admin/service"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -16,12 +17,32 @@ func Update(c *gin.Context) {
 	// Get user id
 	userID, _ := strconv.Atoi(c.Param("id"))
 
+	// users can only update their own profile unless they can edit users
+	loginUser := api.GetLoginUser(c)
+	canEditUsers := loginUser.Can(rbac.CapEditUsers)
+	if uint64(userID) != loginUser.ID && !canEditUsers {
+		api.SendResponse(c, errno.ErrPermissionDenied, nil)
+		return
+	}
+
 	var r service.UserUpdateRequest
 	var s service.UserUpdateStatusRequest
 	var p service.UserUpdatePasswordRequest
 
 	svc := service.New(c.Request.Context())
 	if err := c.ShouldBindBodyWith(&r, binding.JSON); err == nil {
+		// only the user who can edit users is able to change role
+		if r.Role != loginUser.Role {
+			if !canEditUsers {
+				api.SendResponse(c, errno.ErrPermissionDenied, nil)
+				return
+			}
+			if !rbac.IsRole(r.Role) {
+				api.SendResponse(c, errno.New(errno.ErrValidation, nil).Add("role is incorrect."), nil)
+				return
+			}
+		}
+
 		if err := svc.UpdateUser(&r, userID); err != nil {
 			api.SendResponse(c, err, nil)
 			return
@@ -30,6 +51,11 @@ func Update(c *gin.Context) {
 		api.SendResponse(c, nil, nil)
 		return
 	} else if errStatus := c.ShouldBindBodyWith(&s, binding.JSON); errStatus == nil {
+		if !canEditUsers {
+			api.SendResponse(c, errno.ErrPermissionDenied, nil)
+			return
+		}
+
 		if err := svc.UpdateUserStatus(&s, userID); err != nil {
 			api.SendResponse(c, err, nil)
 			return
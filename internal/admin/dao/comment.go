@@ -0,0 +1,231 @@
+package dao
+
+import (
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// GetCommentByID get comment by ID
+func (d *Dao) GetCommentByID(commentID uint64) (*model.Comment, error) {
+	comment := &model.Comment{Model: model.Model{ID: commentID}}
+	if err := comment.GetByID(d.db); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// ListComment returns the comment list in condition
+func (d *Dao) ListComment(status string, postID uint64, keyword string, page, number int) ([]*model.Comment, int64, error) {
+	where := "`deleted_time` is null"
+	whereArgs := []interface{}{}
+	if "" != status {
+		where += " AND `approved` = ?"
+		whereArgs = append(whereArgs, status)
+	}
+	if 0 != postID {
+		where += " AND `post_id` = ?"
+		whereArgs = append(whereArgs, postID)
+	}
+	if "" != keyword {
+		where += " AND (`content` LIKE ? OR `commenter_name` LIKE ? OR `commenter_email` LIKE ?)"
+		whereArgs = append(whereArgs, "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+	}
+
+	comment := &model.Comment{}
+	count, err := comment.Count(d.db, where, whereArgs)
+	if err != nil {
+		return nil, count, err
+	}
+
+	offset := (page - 1) * number
+	comments, err := comment.List(d.db, where, whereArgs, offset, number, "`comment_date` DESC")
+	if err != nil {
+		return nil, count, err
+	}
+
+	return comments, count, nil
+}
+
+// CountCommentByStatus count comments group by status
+func (d *Dao) CountCommentByStatus() (map[string]int64, error) {
+	rows, err := d.db.Model(&model.Comment{}).
+		Select("`approved`, count(*)").
+		Where("`deleted_time` is null").
+		Group("`approved`").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statusCount := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		statusCount[status] = count
+	}
+
+	return statusCount, nil
+}
+
+// GetPostTitles get post titles by post ids, the key of map is post id
+func (d *Dao) GetPostTitles(postIDs []uint64) (map[uint64]*model.Post, error) {
+	var posts []*model.Post
+	if err := d.db.Select("`id`, `title`, `guid`, `post_type`").Where("`id` IN (?)", postIDs).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	postMap := make(map[uint64]*model.Post, len(posts))
+	for _, p := range posts {
+		postMap[p.ID] = p
+	}
+	return postMap, nil
+}
+
+// CreateComment create a comment (reply in console) and sync the comment count
+func (d *Dao) CreateComment(comment *model.Comment) error {
+	tx := d.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := comment.Create(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := comment.SyncPostCommentCount(tx, comment.PostID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// UpdateComment update comment info
+func (d *Dao) UpdateComment(comment *model.Comment) error {
+	return comment.Save(d.db)
+}
+
+// UpdateCommentStatus set status of comments in bulk and sync the comment count of their posts
+// It returns the post ids that affected
+func (d *Dao) UpdateCommentStatus(commentIDs []uint64, status string) ([]uint64, error) {
+	tx := d.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	postIDs, err := getCommentPostIDs(tx, commentIDs)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(&model.Comment{}).Where("`id` IN (?)", commentIDs).Update("approved", status).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := syncCommentCount(tx, postIDs); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return postIDs, tx.Commit().Error
+}
+
+// DeleteComment delete comments directly without soft delete
+// Replies of the deleted comment will be moved to its parent
+// It returns the post ids that affected
+func (d *Dao) DeleteComment(commentIDs []uint64) ([]uint64, error) {
+	tx := d.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	postIDs, err := getCommentPostIDs(tx, commentIDs)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, ID := range commentIDs {
+		comment := &model.Comment{Model: model.Model{ID: ID}}
+		if err := comment.GetByID(tx); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.Model(&model.Comment{}).Where("`parent_id` = ?", comment.ID).Update("parent_id", comment.ParentID).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := comment.HardDelete(tx); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := syncCommentCount(tx, postIDs); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return postIDs, tx.Commit().Error
+}
+
+// getCommentPostIDs get distinct post ids of comments
+func getCommentPostIDs(tx *gorm.DB, commentIDs []uint64) ([]uint64, error) {
+	var postIDs []uint64
+	err := tx.Model(&model.Comment{}).Distinct("post_id").Where("`id` IN (?)", commentIDs).Pluck("post_id", &postIDs).Error
+	return postIDs, err
+}
+
+// syncCommentCount recount comment count of the posts
+func syncCommentCount(tx *gorm.DB, postIDs []uint64) error {
+	comment := &model.Comment{}
+	for _, postID := range postIDs {
+		if err := comment.SyncPostCommentCount(tx, postID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTotalComments get total number of approved comments
+func (d *Dao) GetTotalComments() (totalComment int64) {
+	var err error
+
+	comment := &model.Comment{}
+	totalComment, err = comment.TotalNumber(d.db)
+	if err != nil {
+		logger.Errorf("statistics error: get total comment failed, %v", err)
+	}
+
+	return
+}
@@ -69,6 +69,13 @@ func (d *Dao) GetKnowledgeItemSymbolByID(kiID uint64) (uint64, error) {
 	return kItem.Symbol, err
 }
 
+// ListKnowledgeItemSymbol get symbols of all knowledge items
+func (d *Dao) ListKnowledgeItemSymbol() ([]uint64, error) {
+	var symbols []uint64
+	err := d.db.Model(&model.KnowledgeItem{}).Pluck("`symbol`", &symbols).Error
+	return symbols, err
+}
+
 // GetKnowledgeItemLastContent get last version of item content
 func (d *Dao) GetKnowledgeItemLastContent(ki *model.KnowledgeItem) (*model.KnowledgeItemContent, error) {
 	itemContents, err := ki.GetItemContent(d.db, "", "updated_time desc")
@@ -1,29 +1,63 @@
 package dao
 
 import (
+	"strings"
+	"time"
+
 	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/errno"
 	"github.com/puti-projects/puti/internal/pkg/logger"
-	"github.com/puti-projects/puti/internal/utils"
 )
 
-// CreateMedia crate new media record after uploaded
-func (d *Dao) CreateMedia(uID int, filename, fileNameWithoutExt, fileExt, pathName, usage string) (uint64, string, error) {
+// CreateMedia crate new media record after uploaded, with the content hash as meta data
+func (d *Dao) CreateMedia(uID int, filename, fileNameWithoutExt, mimeType, pathName, usage, contentHash string) (uint64, string, error) {
 	media := &model.Media{
 		UserID:   uint64(uID),
 		Title:    filename,
 		Slug:     fileNameWithoutExt,
 		GUID:     pathName,
-		MimeType: utils.GetFileMimeTypeByExt(fileExt),
+		MimeType: mimeType,
 		Usage:    usage,
 	}
 
-	if err := media.Create(d.db); err != nil {
+	tx := d.db.Begin()
+	if err := tx.Error; err != nil {
+		return 0, "", errno.New(errno.ErrDatabase, err)
+	}
+
+	if err := media.Create(tx); err != nil {
+		tx.Rollback()
+		return 0, "", errno.New(errno.ErrDatabase, err)
+	}
+
+	meta := &model.ResourceMeta{
+		ResourceID: media.ID,
+		MetaKey:    model.ResourceMetaContentHashPrefix + contentHash,
+		MetaValue:  contentHash,
+	}
+	if err := meta.Create(tx); err != nil {
+		tx.Rollback()
+		return 0, "", errno.New(errno.ErrDatabase, err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		return 0, "", errno.New(errno.ErrDatabase, err)
 	}
 	return media.ID, media.GUID, nil
 }
 
+// GetMediaByContentHash get the media which is not deleted by its content hash, uploader and usage
+func (d *Dao) GetMediaByContentHash(contentHash string, userID uint64, usage string) (*model.Media, error) {
+	media := &model.Media{}
+	err := d.db.Table("pt_resource").
+		Select("pt_resource.*").
+		Joins("INNER JOIN pt_resource_meta AS rm ON rm.`resource_id` = pt_resource.`id`").
+		Where("rm.`meta_key` = ? AND pt_resource.`status` = ?", model.ResourceMetaContentHashPrefix+contentHash, model.StatusNormal).
+		Where("pt_resource.`upload_user_id` = ? AND pt_resource.`usage` = ?", userID, usage).
+		First(media).Error
+	return media, err
+}
+
 // UpdateMedia update media info
 func (d *Dao) UpdateMedia(ID uint64, title, slug, description string) error {
 	// Get old media info
@@ -44,18 +78,69 @@ func (d *Dao) UpdateMedia(ID uint64, title, slug, description string) error {
 	return err
 }
 
+// MediaFilter conditions of media list; zero value means no condition
+type MediaFilter struct {
+	Type     string
+	Usage    string
+	MimeType string // mime-type, or the prefix ends with "/" such as "image/"
+	UserID   uint64
+	Start    time.Time
+	End      time.Time // exclusive
+	Keyword  string
+	Folder   string // "/" means media which are not in any folder
+}
+
 // ListMedia get media list
-func (d *Dao) ListMedia(limit, page int) ([]*model.Media, int64, error) {
+func (d *Dao) ListMedia(filter *MediaFilter, limit, page int) ([]*model.Media, int64, error) {
 	where := "deleted_time is null"
+	var whereArgs []interface{}
+	if filter.Type != "" {
+		where += " AND `type` = ?"
+		whereArgs = append(whereArgs, filter.Type)
+	}
+	if filter.Usage != "" {
+		where += " AND `usage` = ?"
+		whereArgs = append(whereArgs, filter.Usage)
+	}
+	if strings.HasSuffix(filter.MimeType, "/") {
+		where += " AND `mime_type` LIKE ?"
+		whereArgs = append(whereArgs, filter.MimeType+"%")
+	} else if filter.MimeType != "" {
+		where += " AND `mime_type` = ?"
+		whereArgs = append(whereArgs, filter.MimeType)
+	}
+	if filter.UserID != 0 {
+		where += " AND `upload_user_id` = ?"
+		whereArgs = append(whereArgs, filter.UserID)
+	}
+	if !filter.Start.IsZero() {
+		where += " AND `created_time` >= ?"
+		whereArgs = append(whereArgs, filter.Start)
+	}
+	if !filter.End.IsZero() {
+		where += " AND `created_time` < ?"
+		whereArgs = append(whereArgs, filter.End)
+	}
+	if filter.Keyword != "" {
+		where += " AND `title` LIKE ?"
+		whereArgs = append(whereArgs, "%"+filter.Keyword+"%")
+	}
+	if filter.Folder == "/" {
+		where += " AND `id` NOT IN (SELECT `resource_id` FROM `pt_resource_meta` WHERE `meta_key` = ?)"
+		whereArgs = append(whereArgs, model.ResourceMetaFolder)
+	} else if filter.Folder != "" {
+		where += " AND `id` IN (SELECT `resource_id` FROM `pt_resource_meta` WHERE `meta_key` = ? AND `meta_value` = ?)"
+		whereArgs = append(whereArgs, model.ResourceMetaFolder, filter.Folder)
+	}
 
 	media := &model.Media{}
-	count, err := media.Count(d.db, where, nil)
+	count, err := media.Count(d.db, where, whereArgs)
 	if err != nil {
 		return nil, count, err
 	}
 
 	offset := (page - 1) * limit
-	medias, err := media.List(d.db, where, nil, offset, limit)
+	medias, err := media.List(d.db, where, whereArgs, offset, limit)
 	if err != nil {
 		return nil, count, err
 	}
@@ -63,6 +148,52 @@ func (d *Dao) ListMedia(limit, page int) ([]*model.Media, int64, error) {
 	return medias, count, nil
 }
 
+// MediaFolderResult folder and the number of media in it
+type MediaFolderResult struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// ListMediaFolder get all folders which have media
+func (d *Dao) ListMediaFolder() ([]*MediaFolderResult, error) {
+	result := make([]*MediaFolderResult, 0)
+	err := d.db.Table("pt_resource_meta AS rm").
+		Select("rm.`meta_value` AS name, COUNT(*) AS count").
+		Joins("INNER JOIN pt_resource AS r ON r.`id` = rm.`resource_id`").
+		Where("r.`deleted_time` IS NULL AND rm.`meta_key` = ?", model.ResourceMetaFolder).
+		Group("rm.`meta_value`").
+		Order("rm.`meta_value` ASC").
+		Find(&result).Error
+	return result, err
+}
+
+// SetMediaFolder move media into the folder; empty folder means moving out of any folder
+func (d *Dao) SetMediaFolder(mediaIDs []uint64, folder string) error {
+	tx := d.db.Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("`resource_id` IN (?) AND `meta_key` = ?", mediaIDs, model.ResourceMetaFolder).
+		Delete(&model.ResourceMeta{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if folder != "" {
+		metas := make([]*model.ResourceMeta, 0, len(mediaIDs))
+		for _, id := range mediaIDs {
+			metas = append(metas, &model.ResourceMeta{ResourceID: id, MetaKey: model.ResourceMetaFolder, MetaValue: folder})
+		}
+		if err := tx.Create(&metas).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
 // GetMediaByID get media by ID
 func (d *Dao) GetMediaByID(ID uint64) (*model.Media, error) {
 	media := &model.Media{
@@ -91,3 +222,83 @@ func (d *Dao) GetTotalMedia() (totalMedia int64) {
 
 	return
 }
+
+// CreateResourceMeta create meta data of the media
+func (d *Dao) CreateResourceMeta(metas []*model.ResourceMeta) error {
+	if len(metas) == 0 {
+		return nil
+	}
+	return d.db.Create(&metas).Error
+}
+
+// ListResourceMetaByPrefix get meta data of the media whose key has the prefix
+func (d *Dao) ListResourceMetaByPrefix(resourceIDs []uint64, prefix string) ([]*model.ResourceMeta, error) {
+	metas := make([]*model.ResourceMeta, 0)
+	if len(resourceIDs) == 0 {
+		return metas, nil
+	}
+
+	err := d.db.Where("`resource_id` IN (?) AND `meta_key` LIKE ?", resourceIDs, prefix+"%").
+		Order("`meta_id` ASC").
+		Find(&metas).Error
+	return metas, err
+}
+
+// DeleteResourceMeta delete meta data of the media whose key has the prefix
+func (d *Dao) DeleteResourceMeta(resourceID uint64, prefix string) error {
+	return d.db.Where("`resource_id` = ? AND `meta_key` LIKE ?", resourceID, prefix+"%").Delete(&model.ResourceMeta{}).Error
+}
+
+// ListAllMedia get all media which are not deleted
+func (d *Dao) ListAllMedia() ([]*model.Media, error) {
+	medias := make([]*model.Media, 0)
+	err := d.db.Order("`id` ASC").Find(&medias).Error
+	return medias, err
+}
+
+// ListAllResourceMetaByPrefix get meta data of all media which are not deleted whose key has the prefix
+func (d *Dao) ListAllResourceMetaByPrefix(prefix string) ([]*model.ResourceMeta, error) {
+	metas := make([]*model.ResourceMeta, 0)
+	err := d.db.Table("pt_resource_meta AS rm").
+		Select("rm.*").
+		Joins("INNER JOIN pt_resource AS r ON r.`id` = rm.`resource_id`").
+		Where("r.`deleted_time` IS NULL AND rm.`meta_key` LIKE ?", prefix+"%").
+		Find(&metas).Error
+	return metas, err
+}
+
+// ListMediaReferenceContents get contents which may refer to media by url,
+// include cover pictures and contents of articles and pages, and current contents of knowledge items
+func (d *Dao) ListMediaReferenceContents() ([]string, error) {
+	var posts []*model.Post
+	if err := d.db.Select("`cover_picture`, `content_markdown`").Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	var itemContents []string
+	if err := d.db.Table("pt_knowledge_item_content AS kic").
+		Joins("INNER JOIN pt_knowledge_item AS ki ON ki.`id` = kic.`knowledge_item_id`").
+		Where("ki.`deleted_time` IS NULL AND kic.`status` = ?", model.KnowledgeItemContentStatusCurrent).
+		Pluck("kic.`content`", &itemContents).Error; err != nil {
+		return nil, err
+	}
+
+	contents := make([]string, 0, len(posts)*2+len(itemContents))
+	for _, p := range posts {
+		contents = append(contents, p.CoverPicture, p.ContentMarkdown)
+	}
+	return append(contents, itemContents...), nil
+}
+
+// ListMediaCoverIDs get media ids which are used as cover image of subjects and knowledge bases
+func (d *Dao) ListMediaCoverIDs() ([]uint64, error) {
+	var subjectCovers, knowledgeCovers []uint64
+	if err := d.db.Model(&model.Subject{}).Where("`cover_image` > 0").Pluck("`cover_image`", &subjectCovers).Error; err != nil {
+		return nil, err
+	}
+	if err := d.db.Model(&model.Knowledge{}).Where("`cover_image` > 0").Pluck("`cover_image`", &knowledgeCovers).Error; err != nil {
+		return nil, err
+	}
+
+	return append(subjectCovers, knowledgeCovers...), nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/logger"
@@ -66,6 +67,12 @@ func (d *Dao) CreatePage(page *model.Post, meta []*model.PostMeta) (*model.Post,
 		return nil, err
 	}
 
+	// record the first revision
+	if err := createPostRevision(tx, page, page.UserID, 0); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	return page, tx.Commit().Error
 }
 
@@ -141,11 +148,13 @@ func (d *Dao) CreateArticle(article *model.Post, meta []*model.PostMeta, categor
 		return nil, err
 	}
 
-	// update taxonomy count
+	// update taxonomy count; scheduled article is counted when it is published
 	insertTaxonomy := append(category, tag...) // combine catogory and tag; they are all taxonomy
-	if err := updateTaxonomyCountByArticleChange(tx, insertTaxonomy, 1); err != nil {
-		tx.Rollback()
-		return nil, err
+	if article.Status != model.PostStatusScheduled {
+		if err := updateTaxonomyCountByArticleChange(tx, insertTaxonomy, 1); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	// if upload subject id
@@ -172,18 +181,26 @@ func (d *Dao) CreateArticle(article *model.Post, meta []*model.PostMeta, categor
 		}
 
 		// update subject
-		if err := updateSubjectInfoByArticleChange(tx, subject, 1, true); err != nil {
-			tx.Rollback()
-			return nil, err
+		if article.Status != model.PostStatusScheduled {
+			if err := updateSubjectInfoByArticleChange(tx, subject, 1, true); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
 		}
 	}
 
+	// record the first revision
+	if err := createPostRevision(tx, article, article.UserID, 0); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// commit
 	return article, tx.Commit().Error
 }
 
-// UpdatePage update page
-func (d *Dao) UpdatePage(page *model.Post, description, pageTemplate string) error {
+// UpdatePage update page and record the revision saved by the user
+func (d *Dao) UpdatePage(page *model.Post, userID uint64, keepRevisions int, description, pageTemplate string) error {
 	// ======================================================
 	// ================== Transaction Start =================
 	// ======================================================
@@ -205,6 +222,10 @@ func (d *Dao) UpdatePage(page *model.Post, description, pageTemplate string) err
 		tx.Rollback()
 		return err
 	}
+	if err := createPostRevision(tx, page, userID, keepRevisions); err != nil {
+		tx.Rollback()
+		return err
+	}
 
 	// ======================================================
 	// Update page meta data
@@ -241,8 +262,8 @@ func (d *Dao) UpdatePage(page *model.Post, description, pageTemplate string) err
 	return tx.Commit().Error
 }
 
-// UpdateArticle update article
-func (d *Dao) UpdateArticle(article *model.Post, description string, category, tag, subject []uint64) error {
+// UpdateArticle update article and record the revision saved by the user
+func (d *Dao) UpdateArticle(article *model.Post, userID uint64, keepRevisions int, description string, category, tag, subject []uint64) error {
 	// ======================================================
 	// ================== Transaction Start =================
 	// ======================================================
@@ -260,10 +281,23 @@ func (d *Dao) UpdateArticle(article *model.Post, description string, category, t
 	// ======================================================
 	// Udapte article
 	// ======================================================
+	// scheduled article is not counted by taxonomy and subject
+	var oldStatus []string
+	if err := tx.Model(&model.Post{}).Where("`id` = ?", article.ID).Pluck("`status`", &oldStatus).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	oldCounted := len(oldStatus) != 0 && oldStatus[0] != model.PostStatusScheduled
+	newCounted := article.Status != model.PostStatusScheduled
+
 	if err := article.Save(tx); err != nil {
 		tx.Rollback()
 		return err
 	}
+	if err := createPostRevision(tx, article, userID, keepRevisions); err != nil {
+		tx.Rollback()
+		return err
+	}
 
 	// ======================================================
 	// Update article meta data description
@@ -329,8 +363,7 @@ func (d *Dao) UpdateArticle(article *model.Post, description string, category, t
 	}
 
 	// calculate taxonomy diff
-	deleteTaxonomy := utils.SliceDiffUint64(oldTaxonomy, newTaxonomy)
-	insertTaxonomy := utils.SliceDiffUint64(newTaxonomy, oldTaxonomy)
+	insertTaxonomy, deleteTaxonomy := articleCountChange(oldTaxonomy, newTaxonomy, oldCounted, newCounted)
 	// update count
 	if err := updateTaxonomyCountByArticleChange(tx, insertTaxonomy, 1); err != nil {
 		tx.Rollback()
@@ -383,8 +416,7 @@ func (d *Dao) UpdateArticle(article *model.Post, description string, category, t
 	}
 
 	// calculate subject diff
-	deleteSubject := utils.SliceDiffUint64(oldSubject, subject)
-	insertSubject := utils.SliceDiffUint64(subject, oldSubject)
+	insertSubject, deleteSubject := articleCountChange(oldSubject, subject, oldCounted, newCounted)
 
 	// update subject's info
 	if err := updateSubjectInfoByArticleChange(tx, insertSubject, 1, true); err != nil {
@@ -409,9 +441,95 @@ func (d *Dao) TrashPost(postID uint64) error {
 		return err
 	}
 
-	// set status to deleted
+	if post.PostType != model.PostTypeArticle || post.Status != model.PostStatusScheduled {
+		// set status to deleted
+		post.Status = model.PostStatusDeleted
+		return post.Save(d.db)
+	}
+
+	// trashed article is counted until it is deleted, but scheduled article is not counted yet
+	tx := d.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+	if err := tx.Error; err != nil {
+		return err
+	}
+
 	post.Status = model.PostStatusDeleted
-	return post.Save(d.db)
+	if err := post.Save(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := updateArticleCount(tx, post.ID, 1, false); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ListScheduledPost get scheduled posts whose posted time is not after the time
+func (d *Dao) ListScheduledPost(t time.Time) ([]*model.Post, error) {
+	posts := make([]*model.Post, 0)
+	err := d.db.Select("`id`, `post_type`, `status`, `posted_time`").
+		Where("`status` = ? AND `posted_time` <= ?", model.PostStatusScheduled, t).
+		Order("`posted_time` ASC").
+		Find(&posts).Error
+	return posts, err
+}
+
+// PublishScheduledPost set the scheduled post status to "publish" and count the article
+// It returns false if the post is not scheduled any more, e.g. it was published by another instance.
+func (d *Dao) PublishScheduledPost(post *model.Post) (bool, error) {
+	tx := d.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+	if err := tx.Error; err != nil {
+		return false, err
+	}
+
+	result := tx.Model(&model.Post{}).
+		Where("`id` = ? AND `status` = ?", post.ID, model.PostStatusScheduled).
+		Update("status", model.PostStatusPublish)
+	if result.Error != nil {
+		tx.Rollback()
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if post.PostType == model.PostTypeArticle {
+		if err := updateArticleCount(tx, post.ID, 1, true); err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+
+	return true, tx.Commit().Error
+}
+
+// ListPostContent get at most limit posts whose id is greater than afterID, with title and contents
+func (d *Dao) ListPostContent(afterID uint64, limit int) ([]*model.Post, error) {
+	posts := make([]*model.Post, 0)
+	err := d.db.Select("`id`, `post_type`, `title`, `content_markdown`, `content_html`").
+		Where("`id` > ?", afterID).
+		Order("`id` ASC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+// UpdatePostContentHTML update the rendered HTML of the post; the updated time is not changed
+func (d *Dao) UpdatePostContentHTML(postID uint64, contentHTML string) error {
+	return d.db.Model(&model.Post{}).Where("`id` = ?", postID).UpdateColumn("content_html", contentHTML).Error
 }
 
 // RestorePost set post status to "draft"
@@ -455,8 +573,59 @@ func (d *Dao) DeletePost(postType string, articleID uint64) error {
 	return tx.Commit().Error
 }
 
+// articleCountChange get the taxonomies or subjects whose count should be increased or decreased after the article changes
+// old and new are the taxonomies or subjects of the article before and after the change,
+// and counted tells if the article is counted before and after the change.
+func articleCountChange(old, new []uint64, oldCounted, newCounted bool) (insert, delete []uint64) {
+	switch {
+	case oldCounted && newCounted:
+		return utils.SliceDiffUint64(new, old), utils.SliceDiffUint64(old, new)
+	case oldCounted:
+		return nil, old
+	case newCounted:
+		return new, nil
+	}
+	return nil, nil
+}
+
+// updateArticleCount update count of all taxonomies and subjects of the article
+func updateArticleCount(tx *gorm.DB, articleID uint64, countDiff int64, updateLastUpdated bool) error {
+	articleTaxonomy, err := getArticleTaxonomyByArticleID(tx, articleID)
+	if err != nil {
+		return err
+	}
+	taxonomy := make([]uint64, 0, len(articleTaxonomy))
+	for _, item := range articleTaxonomy {
+		taxonomy = append(taxonomy, item.TermID)
+	}
+	if err := updateTaxonomyCountByArticleChange(tx, taxonomy, countDiff); err != nil {
+		return err
+	}
+
+	sr := &model.SubjectRelationships{}
+	articleSubject, err := sr.GetAllByObjectID(tx, articleID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	subject := make([]uint64, 0, len(articleSubject))
+	for _, item := range articleSubject {
+		subject = append(subject, item.SubjectID)
+	}
+	return updateSubjectInfoByArticleChange(tx, subject, countDiff, updateLastUpdated)
+}
+
 // deleteArticleElse delete extra data which only article have
 func (d *Dao) deleteArticleElse(tx *gorm.DB, articleID uint64) error {
+	// scheduled article is not counted by taxonomy and subject
+	var status []string
+	if err := tx.Model(&model.Post{}).Where("`id` = ?", articleID).Pluck("`status`", &status).Error; err != nil {
+		return err
+	}
+	countDiff := int64(-1)
+	if len(status) != 0 && status[0] == model.PostStatusScheduled {
+		countDiff = 0
+	}
+
 	// get article taxonomy by id
 	articleTaxonomy, err := d.GetArticleTaxonomy(tx, articleID)
 	if err != nil {
@@ -471,9 +640,9 @@ func (d *Dao) deleteArticleElse(tx *gorm.DB, articleID uint64) error {
 
 	// recount and update taxonomy count
 	taxonomy := append(articleTaxonomy["category"], articleTaxonomy["tag"]...)
-	if len(taxonomy) != 0 {
+	if len(taxonomy) != 0 && countDiff != 0 {
 		// update category count
-		err := updateTaxonomyCountByArticleChange(tx, taxonomy, -1)
+		err := updateTaxonomyCountByArticleChange(tx, taxonomy, countDiff)
 		if err != nil {
 			return err
 		}
@@ -495,9 +664,9 @@ func (d *Dao) deleteArticleElse(tx *gorm.DB, articleID uint64) error {
 	for _, subject := range articleSubject {
 		subjectIDGroup = append(subjectIDGroup, subject.SubjectID)
 	}
-	if len(subjectIDGroup) != 0 {
+	if len(subjectIDGroup) != 0 && countDiff != 0 {
 		// update subject count
-		if err := updateSubjectInfoByArticleChange(tx, subjectIDGroup, -1, false); err != nil {
+		if err := updateSubjectInfoByArticleChange(tx, subjectIDGroup, countDiff, false); err != nil {
 			return err
 		}
 	}
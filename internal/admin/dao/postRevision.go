@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"errors"
+
+	"github.com/puti-projects/puti/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ListPostRevision get revisions of the post from the newest; contents are not selected
+func (d *Dao) ListPostRevision(postID uint64) ([]*model.PostRevision, error) {
+	revisions := make([]*model.PostRevision, 0)
+	err := d.db.Select("`id`, `post_id`, `user_id`, `title`, `created_time`").
+		Where("`post_id` = ?", postID).
+		Order("`id` DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// GetPostRevision get the revision of the post
+func (d *Dao) GetPostRevision(postID, revisionID uint64) (*model.PostRevision, error) {
+	revision := &model.PostRevision{}
+	err := d.db.Where("`id` = ? AND `post_id` = ?", revisionID, postID).First(revision).Error
+	return revision, err
+}
+
+// RestorePostRevision restore title and content of the post to the revision
+// the restored content is recorded as a new revision
+func (d *Dao) RestorePostRevision(post *model.Post, revision *model.PostRevision, userID uint64, keep int) error {
+	tx := d.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	post.Title = revision.Title
+	post.ContentMarkdown = revision.ContentMarkdown
+	post.ContentHTML = revision.ContentHTML
+	if err := post.Save(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := createPostRevision(tx, post, userID, keep); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// createPostRevision record the title and content of the post as a revision saved by the user
+// Nothing is recorded if they are the same as the latest revision.
+// Only the newest keep revisions are kept, and all revisions are kept if keep is 0.
+func createPostRevision(tx *gorm.DB, post *model.Post, userID uint64, keep int) error {
+	latest := &model.PostRevision{}
+	err := tx.Where("`post_id` = ?", post.ID).Order("`id` DESC").First(latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && latest.Title == post.Title && latest.ContentMarkdown == post.ContentMarkdown {
+		return nil
+	}
+
+	revision := &model.PostRevision{
+		PostID:          post.ID,
+		UserID:          userID,
+		Title:           post.Title,
+		ContentMarkdown: post.ContentMarkdown,
+		ContentHTML:     post.ContentHTML,
+	}
+	if err := revision.Create(tx); err != nil {
+		return err
+	}
+
+	if keep <= 0 {
+		return nil
+	}
+	var expired []uint64
+	if err := tx.Model(&model.PostRevision{}).
+		Where("`post_id` = ?", post.ID).
+		Order("`id` DESC").
+		Offset(keep).
+		Limit(1).
+		Pluck("`id`", &expired).Error; err != nil {
+		return err
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	return tx.Where("`post_id` = ? AND `id` <= ?", post.ID, expired[0]).Delete(&model.PostRevision{}).Error
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/constvar"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"gorm.io/gorm"
 )
 
 // GetUser get user by username
@@ -26,6 +28,20 @@ func (d *Dao) GetUserByID(userID uint64) (*model.User, error) {
 	return user, err
 }
 
+// CheckAccountExist check if the account is registered, include the deleted users
+func (d *Dao) CheckAccountExist(account string) bool {
+	var count int64
+	d.db.Unscoped().Model(&model.User{}).Where("`account` = ?", account).Count(&count)
+	return count > 0
+}
+
+// CheckEmailExist check if the email is registered, include the deleted users
+func (d *Dao) CheckEmailExist(email string) bool {
+	var count int64
+	d.db.Unscoped().Model(&model.User{}).Where("`email` = ?", email).Count(&count)
+	return count > 0
+}
+
 // CreateUser create a new user
 func (d *Dao) CreateUser(user *model.User) error {
 	if err := user.Encrypt(); err != nil {
@@ -171,3 +187,84 @@ func (d *Dao) DeleteUser(userID uint64) error {
 	err := user.Delete(d.db)
 	return err
 }
+
+// GetUserMeta get the meta value of user by meta key; it is empty if the meta does not exist
+func (d *Dao) GetUserMeta(userID uint64, metaKey string) (string, error) {
+	var metas []*model.UserMeta
+	if err := d.db.Where("`user_id` = ? AND `meta_key` = ?", userID, metaKey).Limit(1).Find(&metas).Error; err != nil {
+		return "", err
+	}
+
+	if len(metas) == 0 {
+		return "", nil
+	}
+	return metas[0].MetaValue, nil
+}
+
+// SetUserMeta set the meta value of user; create the meta if it does not exist
+func (d *Dao) SetUserMeta(userID uint64, metaKey, metaValue string) error {
+	result := d.db.Model(&model.UserMeta{}).
+		Where("`user_id` = ? AND `meta_key` = ?", userID, metaKey).
+		Update("meta_value", metaValue)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// value may be not changed, check before create
+		var count int64
+		if err := d.db.Model(&model.UserMeta{}).Where("`user_id` = ? AND `meta_key` = ?", userID, metaKey).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			meta := &model.UserMeta{UserID: userID, MetaKey: metaKey, MetaValue: metaValue}
+			return meta.Create(d.db)
+		}
+	}
+
+	return nil
+}
+
+// IncrUserMetaBelow add one to the numeric meta value of user if it is less than the limit
+// It returns false if the meta does not exist or the value reached the limit.
+func (d *Dao) IncrUserMetaBelow(userID uint64, metaKey string, limit int) (bool, error) {
+	result := d.db.Model(&model.UserMeta{}).
+		Where("`user_id` = ? AND `meta_key` = ? AND CAST(`meta_value` AS UNSIGNED) < ?", userID, metaKey, limit).
+		Update("meta_value", gorm.Expr("CAST(`meta_value` AS UNSIGNED) + 1"))
+	return result.RowsAffected != 0, result.Error
+}
+
+// ListUserMetaByPrefix get metas of user whose meta key has the prefix
+func (d *Dao) ListUserMetaByPrefix(userID uint64, prefix string) ([]*model.UserMeta, error) {
+	var metas []*model.UserMeta
+	err := d.db.Where("`user_id` = ? AND `meta_key` LIKE ?", userID, prefix+"%").Order("`id` ASC").Find(&metas).Error
+	return metas, err
+}
+
+// GetUserMetaByKey get the user meta by meta key which is unique among all users
+func (d *Dao) GetUserMetaByKey(metaKey string) (*model.UserMeta, error) {
+	meta := &model.UserMeta{}
+	err := d.db.Where("`meta_key` = ?", metaKey).First(meta).Error
+	return meta, err
+}
+
+// CreateUserMeta create a user meta
+func (d *Dao) CreateUserMeta(meta *model.UserMeta) error {
+	return meta.Create(d.db)
+}
+
+// UpdateUserMetaValueByID update the meta value by meta ID
+func (d *Dao) UpdateUserMetaValueByID(metaID uint64, metaValue string) error {
+	return d.db.Model(&model.UserMeta{}).Where("`id` = ?", metaID).Update("meta_value", metaValue).Error
+}
+
+// DeleteUserMetaByPrefix delete the meta of user by meta ID, the meta key must have the prefix
+func (d *Dao) DeleteUserMetaByPrefix(userID, metaID uint64, prefix string) (int64, error) {
+	result := d.db.Where("`id` = ? AND `user_id` = ? AND `meta_key` LIKE ?", metaID, userID, prefix+"%").Delete(&model.UserMeta{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteUserMeta delete the metas of user by meta keys
+func (d *Dao) DeleteUserMeta(userID uint64, metaKeys ...string) error {
+	return d.db.Where("`user_id` = ? AND `meta_key` IN (?)", userID, metaKeys).Delete(&model.UserMeta{}).Error
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
+	"github.com/puti-projects/puti/internal/pkg/token"
+	"github.com/puti-projects/puti/internal/utils"
+)
+
+// APITokenCreateRequest is the create personal api token request params struct
+type APITokenCreateRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// APITokenInfo is the personal api token struct for token list; the token itself is never shown again
+type APITokenInfo struct {
+	ID           uint64   `json:"id"`
+	Name         string   `json:"name"`
+	Scopes       []string `json:"scopes"`
+	CreatedTime  string   `json:"created_time"`
+	LastUsedTime string   `json:"last_used_time"`
+}
+
+// APITokenCreateResponse return the new token, it is only shown once
+type APITokenCreateResponse struct {
+	*APITokenInfo
+	Token string `json:"token"`
+}
+
+// apiTokenMeta the meta value of personal api token in pt_user_meta
+type apiTokenMeta struct {
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  int64    `json:"created_at"`
+	LastUsedAt int64    `json:"last_used_at"`
+}
+
+const (
+	apiTokenNameMaxLength = 60
+	// apiTokenLastUsedInterval the last used time is only updated after the interval to avoid writing on every request
+	apiTokenLastUsedInterval = 10 * time.Minute
+)
+
+// ListAPIToken get personal api tokens of the user
+func (svc Service) ListAPIToken(userID uint64) ([]*APITokenInfo, error) {
+	metas, err := svc.dao.ListUserMetaByPrefix(userID, model.UserMetaAPITokenPrefix)
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+
+	infos := make([]*APITokenInfo, 0, len(metas))
+	for _, meta := range metas {
+		var m apiTokenMeta
+		if err := json.Unmarshal([]byte(meta.MetaValue), &m); err != nil {
+			logger.Errorf("unmarshal api token meta %d failed. %s", meta.ID, err)
+			continue
+		}
+		infos = append(infos, newAPITokenInfo(meta.ID, &m))
+	}
+
+	return infos, nil
+}
+
+// CreateAPIToken create a new personal api token for the user
+func (svc Service) CreateAPIToken(userID uint64, r *APITokenCreateRequest) (*APITokenCreateResponse, error) {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return nil, errno.New(errno.ErrValidation, nil).Add("name can not be empty.")
+	}
+	if len([]rune(r.Name)) > apiTokenNameMaxLength {
+		return nil, errno.New(errno.ErrValidation, nil).Add("name is too long.")
+	}
+	if len(r.Scopes) == 0 {
+		return nil, errno.New(errno.ErrValidation, nil).Add("scopes can not be empty.")
+	}
+	for _, scope := range r.Scopes {
+		if !rbac.IsScope(scope) {
+			return nil, errno.New(errno.ErrValidation, nil).Addf("scope %s is incorrect.", scope)
+		}
+	}
+
+	t, err := token.GenerateAPIToken()
+	if err != nil {
+		return nil, errno.New(errno.ErrToken, err)
+	}
+
+	m := &apiTokenMeta{Name: r.Name, Scopes: r.Scopes, CreatedAt: time.Now().Unix()}
+	value, err := json.Marshal(m)
+	if err != nil {
+		return nil, errno.New(errno.ErrToken, err)
+	}
+
+	meta := &model.UserMeta{
+		UserID:    userID,
+		MetaKey:   model.UserMetaAPITokenPrefix + token.HashAPIToken(t),
+		MetaValue: string(value),
+	}
+	if err := svc.dao.CreateUserMeta(meta); err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+
+	return &APITokenCreateResponse{APITokenInfo: newAPITokenInfo(meta.ID, m), Token: t}, nil
+}
+
+// DeleteAPIToken delete the personal api token of the user
+func (svc Service) DeleteAPIToken(userID, tokenID uint64) error {
+	affected, err := svc.dao.DeleteUserMetaByPrefix(userID, tokenID, model.UserMetaAPITokenPrefix)
+	if err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+	if affected == 0 {
+		return errno.ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+// CheckAPIToken check the personal api token and return its user and scopes
+// the user must be enabled
+func (svc Service) CheckAPIToken(t string) (*model.User, []string, error) {
+	meta, err := svc.dao.GetUserMetaByKey(model.UserMetaAPITokenPrefix + token.HashAPIToken(t))
+	if err != nil {
+		return nil, nil, errno.New(errno.ErrTokenInvalid, err)
+	}
+
+	var m apiTokenMeta
+	if err := json.Unmarshal([]byte(meta.MetaValue), &m); err != nil {
+		return nil, nil, errno.New(errno.ErrTokenInvalid, err)
+	}
+
+	u, err := svc.GetEnabledUserByID(meta.UserID)
+	if err != nil {
+		return nil, nil, errno.New(errno.ErrTokenInvalid, err)
+	}
+
+	now := time.Now()
+	if now.Sub(time.Unix(m.LastUsedAt, 0)) > apiTokenLastUsedInterval {
+		m.LastUsedAt = now.Unix()
+		if value, err := json.Marshal(&m); err == nil {
+			if err := svc.dao.UpdateUserMetaValueByID(meta.ID, string(value)); err != nil {
+				logger.Errorf("update last used time of api token %d failed. %s", meta.ID, err)
+			}
+		}
+	}
+
+	return u, m.Scopes, nil
+}
+
+func newAPITokenInfo(ID uint64, m *apiTokenMeta) *APITokenInfo {
+	info := &APITokenInfo{
+		ID:     ID,
+		Name:   m.Name,
+		Scopes: m.Scopes,
+	}
+
+	createdAt := time.Unix(m.CreatedAt, 0)
+	info.CreatedTime = utils.GetFormatTime(&createdAt, "2006-01-02 15:04:05")
+	if m.LastUsedAt > 0 {
+		lastUsedAt := time.Unix(m.LastUsedAt, 0)
+		info.LastUsedTime = utils.GetFormatTime(&lastUsedAt, "2006-01-02 15:04:05")
+	}
+
+	return info
+}
@@ -1,8 +1,16 @@
 package service
 
 import (
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/auth"
+	"github.com/puti-projects/puti/internal/pkg/cache"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
 	"github.com/puti-projects/puti/internal/pkg/token"
 
 	"github.com/gin-gonic/gin"
@@ -15,9 +23,25 @@ type LoginRequest struct {
 }
 
 // Token represents a JSON view token.
+// Token is the access token for api requests, RefreshToken is used to get a new token pair after Token expired.
+// If the user enabled two-factor authentication, only TwoFactorToken is returned by the first login step
 type Token struct {
-	Username string `json:"username"`
-	Token    string `json:"token"`
+	Username          string `json:"username"`
+	Token             string `json:"token,omitempty"`
+	ExpiresAt         int64  `json:"expires_at,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	TwoFactorToken    string `json:"two_factor_token,omitempty"`
+}
+
+// RefreshTokenRequest is the refresh token request params struct
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest is the logout request params struct; the refresh token is revoked too if it is given
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // LoginAuth user login authentication
@@ -31,10 +55,194 @@ func (svc *Service) LoginAuth(c *gin.Context, username string, password string)
 		return nil, errno.New(errno.ErrPasswordIncorrect, err)
 	}
 
-	t, err := token.Sign(c, token.Context{ID: u.ID, Username: u.Username}, "")
+	// the final token is issued by LoginTwoFactor after the code verified
+	secret, err := svc.dao.GetUserMeta(u.ID, model.UserMetaTOTPSecret)
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	if secret != "" {
+		if err := svc.dao.SetUserMeta(u.ID, model.UserMetaTOTPLoginAttempts, "0"); err != nil {
+			return nil, errno.New(errno.ErrDatabase, err)
+		}
+		t, err := token.Sign(c, token.Context{ID: u.ID, Username: u.Username, Type: token.TypeTwoFactor}, "")
+		if err != nil {
+			return nil, errno.New(errno.ErrToken, err)
+		}
+		return &Token{Username: u.Username, TwoFactorRequired: true, TwoFactorToken: t}, nil
+	}
+
+	return signTokenPair(c, u)
+}
+
+// RefreshToken get a new token pair by the refresh token
+// the refresh token is rotated, the old one can not be used again
+func (svc Service) RefreshToken(c *gin.Context, refreshToken string) (*Token, error) {
+	ctx, err := token.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, errno.New(errno.ErrTokenInvalid, err)
+	}
+
+	u, err := svc.CheckLoginToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Revoke(ctx)
+	return signTokenPair(c, u)
+}
+
+// Logout revoke the access token and the refresh token of the same user
+func (svc Service) Logout(access *token.Context, refreshToken string) {
+	token.Revoke(access)
+
+	if refresh, err := token.ParseRefreshToken(refreshToken); err == nil && refresh.ID == access.ID {
+		token.Revoke(refresh)
+	}
+}
+
+// CheckLoginToken check if the token is still valid for the user, and return the user
+// the user must be enabled, and the token must be issued after the sessions of user were revoked
+func (svc Service) CheckLoginToken(ctx *token.Context) (*model.User, error) {
+	u, err := svc.GetEnabledUserByID(ctx.ID)
+	if err != nil {
+		return nil, errno.New(errno.ErrTokenInvalid, err)
+	}
+
+	validAfter, err := svc.dao.GetUserMeta(u.ID, model.UserMetaTokenValidAfter)
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	if validAfter != "" {
+		ms, _ := strconv.ParseInt(validAfter, 10, 64)
+		if !ctx.IssuedAt.After(time.Unix(0, ms*int64(time.Millisecond))) {
+			return nil, errno.ErrTokenInvalid
+		}
+	}
+
+	return u, nil
+}
+
+// RevokeUserTokens make all tokens of the user issued before now invalid
+// The time is in milliseconds, so a token issued in the same second before the revoke is invalid too.
+func (svc Service) RevokeUserTokens(userID uint64) error {
+	validAfter := time.Now().UnixNano() / int64(time.Millisecond)
+	return svc.dao.SetUserMeta(userID, model.UserMetaTokenValidAfter, strconv.FormatInt(validAfter, 10))
+}
+
+// signTokenPair sign a new access token and refresh token for the user
+func signTokenPair(c *gin.Context, u *model.User) (*Token, error) {
+	access := token.Context{ID: u.ID, Username: u.Username, Type: token.TypeAccess}
+	t, err := token.Sign(c, access, "")
+	if err != nil {
+		return nil, errno.New(errno.ErrToken, err)
+	}
+
+	refresh := token.Context{ID: u.ID, Username: u.Username, Type: token.TypeRefresh}
+	rt, err := token.Sign(c, refresh, "")
 	if err != nil {
 		return nil, errno.New(errno.ErrToken, err)
 	}
 
-	return &Token{Username: u.Username, Token: t}, nil
+	return &Token{
+		Username:     u.Username,
+		Token:        t,
+		ExpiresAt:    time.Now().Add(token.Lifetime(token.TypeAccess)).Unix(),
+		RefreshToken: rt,
+	}, nil
+}
+
+// RegisterRequest is the public registration request params struct
+type RegisterRequest struct {
+	Account       string `json:"account" form:"account"`
+	Nickname      string `json:"nickname" form:"nickname"`
+	Email         string `json:"email" form:"email"`
+	Password      string `json:"password" form:"password"`
+	PasswordAgain string `json:"password_again" form:"password_again"`
+}
+
+// RegisterResponse return the new account
+type RegisterResponse struct {
+	Account  string `json:"account"`
+	Nickname string `json:"nickname"`
+}
+
+const (
+	registerAccountMaxLength  = 60
+	registerPasswordMinLength = 6
+)
+
+// Register create a new user by public registration
+// it is only allowed when the users_can_register option is on; the role of new user is the default_role option
+func (svc Service) Register(r *RegisterRequest) (*RegisterResponse, error) {
+	if cache.Options.Get("users_can_register") != "on" {
+		return nil, errno.ErrRegisterClosed
+	}
+
+	r.Account = strings.TrimSpace(r.Account)
+	r.Nickname = strings.TrimSpace(r.Nickname)
+	r.Email = strings.TrimSpace(r.Email)
+	if err := checkRegisterParam(r); err != nil {
+		return nil, err
+	}
+
+	if svc.dao.CheckAccountExist(r.Account) {
+		return nil, errno.ErrAccountExist
+	}
+	if svc.dao.CheckEmailExist(r.Email) {
+		return nil, errno.ErrEmailExist
+	}
+
+	if r.Nickname == "" {
+		r.Nickname = r.Account
+	}
+
+	user := &model.User{
+		Username: r.Account,
+		Password: r.Password,
+		Nickname: r.Nickname,
+		Email:    r.Email,
+		Status:   1,
+		Roles:    getRegisterRole(),
+	}
+	// password is encrypted in dao
+	if err := svc.dao.CreateUser(user); err != nil {
+		return nil, err
+	}
+
+	return &RegisterResponse{Account: user.Username, Nickname: user.Nickname}, nil
+}
+
+// getRegisterRole get the role of the registered user from the default_role option
+// administrator can never be given by public registration
+func getRegisterRole() string {
+	role := cache.Options.Get("default_role")
+	if !rbac.IsRole(role) || role == rbac.RoleAdministrator {
+		return rbac.RoleSubscriber
+	}
+	return role
+}
+
+func checkRegisterParam(r *RegisterRequest) error {
+	if r.Account == "" {
+		return errno.New(errno.ErrValidation, nil).Add("account is empty.")
+	}
+	if len([]rune(r.Account)) > registerAccountMaxLength {
+		return errno.New(errno.ErrValidation, nil).Add("account is too long.")
+	}
+
+	if r.Email == "" {
+		return errno.New(errno.ErrValidation, nil).Add("Email is empty.")
+	}
+	if _, err := mail.ParseAddress(r.Email); err != nil {
+		return errno.New(errno.ErrValidation, nil).Add("Email is incorrect.")
+	}
+
+	if len(r.Password) < registerPasswordMinLength {
+		return errno.New(errno.ErrValidation, nil).Add("password is too short.")
+	}
+	if r.Password != r.PasswordAgain {
+		return errno.New(errno.ErrValidation, nil).Add("check password is incorrect.")
+	}
+
+	return nil
 }
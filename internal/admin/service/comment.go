@@ -0,0 +1,299 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/utils"
+)
+
+// CommentListRequest is the comment list request struct
+type CommentListRequest struct {
+	Status  string `form:"status"`
+	PostID  uint64 `form:"post_id"`
+	Keyword string `form:"keyword"`
+	Limit   int    `form:"limit"`
+	Page    int    `form:"page"`
+}
+
+// CommentListResponse returns total number of comments and current page of comments
+type CommentListResponse struct {
+	TotalCount     int64            `json:"totalCount"`
+	StatusCount    map[string]int64 `json:"statusCount"`
+	NeedModeration bool             `json:"needModeration"`
+	CommentList    []*CommentInfo   `json:"commentList"`
+}
+
+// CommentInfo is the comment struct for comment list
+type CommentInfo struct {
+	ID             uint64 `json:"id"`
+	PostID         uint64 `json:"post_id"`
+	PostTitle      string `json:"post_title"`
+	PostURL        string `json:"post_url"`
+	ParentID       uint64 `json:"parent_id"`
+	Content        string `json:"content"`
+	CommenterName  string `json:"commenter_name"`
+	CommenterEmail string `json:"commenter_email"`
+	CommenterURL   string `json:"commenter_url"`
+	CommenterIP    string `json:"commenter_ip"`
+	IfVisitor      uint64 `json:"if_visitor"`
+	Status         string `json:"status"`
+	CommentDate    string `json:"comment_date"`
+}
+
+// CommentStatusRequest is the bulk comment status update request struct
+type CommentStatusRequest struct {
+	IDs    []uint64 `json:"ids"`
+	Status string   `json:"status"`
+}
+
+// CommentUpdateRequest is the update comment request struct
+type CommentUpdateRequest struct {
+	Content        string `json:"content"`
+	CommenterName  string `json:"commenter_name"`
+	CommenterEmail string `json:"commenter_email"`
+	CommenterURL   string `json:"commenter_url"`
+}
+
+// CommentReplyRequest is the reply comment request struct
+type CommentReplyRequest struct {
+	Content string `json:"content"`
+}
+
+// commentStatusMap status in request and response to approved field in database
+var commentStatusMap = map[string]string{
+	"approved": model.CommentStatusApproved,
+	"pending":  model.CommentStatusPending,
+	"spam":     model.CommentStatusSpam,
+	"trash":    model.CommentStatusTrash,
+}
+
+// getCommentStatusName get the status name by approved field
+func getCommentStatusName(approved string) string {
+	for name, status := range commentStatusMap {
+		if status == approved {
+			return name
+		}
+	}
+	return approved
+}
+
+// ListComment comment list
+func (svc Service) ListComment(r *CommentListRequest) (*CommentListResponse, error) {
+	status := ""
+	if r.Status != "" {
+		s, ok := commentStatusMap[r.Status]
+		if !ok {
+			return nil, errno.ErrCommentStatus
+		}
+		status = s
+	}
+
+	if r.Page <= 0 {
+		r.Page = 1
+	}
+	if r.Limit <= 0 {
+		r.Limit = 10
+	}
+
+	comments, count, err := svc.dao.ListComment(status, r.PostID, strings.TrimSpace(r.Keyword), r.Page, r.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	statusCount, err := svc.dao.CountCommentByStatus()
+	if err != nil {
+		return nil, err
+	}
+	nameCount := make(map[string]int64, len(commentStatusMap))
+	for name, s := range commentStatusMap {
+		nameCount[name] = statusCount[s]
+	}
+
+	rsp := &CommentListResponse{
+		TotalCount:     count,
+		StatusCount:    nameCount,
+		NeedModeration: cache.Options.Get("comment_before_show") != "directly",
+		CommentList:    make([]*CommentInfo, 0, len(comments)),
+	}
+	if len(comments) == 0 {
+		return rsp, nil
+	}
+
+	postIDs := make([]uint64, 0, len(comments))
+	for _, c := range comments {
+		postIDs = append(postIDs, c.PostID)
+	}
+	posts, err := svc.dao.GetPostTitles(postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range comments {
+		info := getCommentInfo(c)
+		if p, ok := posts[c.PostID]; ok {
+			info.PostTitle = p.Title
+			info.PostURL = p.GUID
+		}
+		rsp.CommentList = append(rsp.CommentList, info)
+	}
+
+	return rsp, nil
+}
+
+// GetCommentDetail get comment info by id
+func (svc Service) GetCommentDetail(ID uint64) (*CommentInfo, error) {
+	comment, err := svc.dao.GetCommentByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := getCommentInfo(comment)
+	posts, err := svc.dao.GetPostTitles([]uint64{comment.PostID})
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := posts[comment.PostID]; ok {
+		info.PostTitle = p.Title
+		info.PostURL = p.GUID
+	}
+
+	return info, nil
+}
+
+func getCommentInfo(c *model.Comment) *CommentInfo {
+	return &CommentInfo{
+		ID:             c.ID,
+		PostID:         c.PostID,
+		ParentID:       c.ParentID,
+		Content:        c.Content,
+		CommenterName:  c.CommenterName,
+		CommenterEmail: c.CommenterEmail,
+		CommenterURL:   c.CommenterURL,
+		CommenterIP:    c.CommenterIP,
+		IfVisitor:      c.IfVisitor,
+		Status:         getCommentStatusName(c.Approved),
+		CommentDate:    utils.GetFormatTime(&c.CommentDate, "2006-01-02 15:04:05"),
+	}
+}
+
+// UpdateCommentStatus approve, unapprove, mark as spam or trash comments in bulk
+func (svc Service) UpdateCommentStatus(r *CommentStatusRequest) error {
+	status, ok := commentStatusMap[r.Status]
+	if !ok {
+		return errno.ErrCommentStatus
+	}
+	if len(r.IDs) == 0 {
+		return errno.New(errno.ErrValidation, nil).Add("ids can not be empty.")
+	}
+
+	postIDs, err := svc.dao.UpdateCommentStatus(r.IDs, status)
+	if err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+
+	svc.cleanCacheAfterEditComment(postIDs)
+	return nil
+}
+
+// UpdateComment edit comment content and commenter info
+func (svc Service) UpdateComment(ID uint64, r *CommentUpdateRequest) error {
+	comment, err := svc.dao.GetCommentByID(ID)
+	if err != nil {
+		return errno.ErrCommentNotFound
+	}
+
+	comment.Content = strings.TrimSpace(r.Content)
+	comment.CommenterName = strings.TrimSpace(r.CommenterName)
+	comment.CommenterEmail = strings.TrimSpace(r.CommenterEmail)
+	comment.CommenterURL = strings.TrimSpace(r.CommenterURL)
+	if comment.Content == "" {
+		return errno.New(errno.ErrValidation, nil).Add("content can not be empty.")
+	}
+	if comment.CommenterName == "" {
+		return errno.New(errno.ErrValidation, nil).Add("commenter name can not be empty.")
+	}
+
+	if err := svc.dao.UpdateComment(comment); err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+
+	svc.cleanCacheAfterEditComment([]uint64{comment.PostID})
+	return nil
+}
+
+// ReplyComment reply the comment as the login user, the reply is approved directly
+// A pending comment will be approved when it is replied
+func (svc Service) ReplyComment(ID uint64, r *CommentReplyRequest, userID uint64, ip, agent string) (uint64, error) {
+	parent, err := svc.dao.GetCommentByID(ID)
+	if err != nil {
+		return 0, errno.ErrCommentNotFound
+	}
+
+	content := strings.TrimSpace(r.Content)
+	if content == "" {
+		return 0, errno.New(errno.ErrValidation, nil).Add("content can not be empty.")
+	}
+
+	user, err := svc.dao.GetUserByID(userID)
+	if err != nil {
+		return 0, errno.New(errno.ErrUserNotFound, err)
+	}
+
+	if parent.Approved == model.CommentStatusPending {
+		if _, err := svc.dao.UpdateCommentStatus([]uint64{parent.ID}, model.CommentStatusApproved); err != nil {
+			return 0, errno.New(errno.ErrDatabase, err)
+		}
+	}
+
+	comment := &model.Comment{
+		ParentID:        parent.ID,
+		PostID:          parent.PostID,
+		Content:         content,
+		IfVisitor:       0,
+		CommenterUserID: user.ID,
+		CommenterName:   user.Nickname,
+		CommenterEmail:  user.Email,
+		CommenterURL:    user.PageURL,
+		CommenterIP:     ip,
+		CommentDate:     time.Now(),
+		Approved:        model.CommentStatusApproved,
+		Agent:           agent,
+	}
+	if len(comment.Agent) > 255 {
+		comment.Agent = comment.Agent[:255]
+	}
+
+	if err := svc.dao.CreateComment(comment); err != nil {
+		return 0, errno.New(errno.ErrCommentCreateFailed, err)
+	}
+
+	svc.cleanCacheAfterEditComment([]uint64{comment.PostID})
+	return comment.ID, nil
+}
+
+// DeleteComment delete the comment permanently
+func (svc Service) DeleteComment(ID uint64) error {
+	if _, err := svc.dao.GetCommentByID(ID); err != nil {
+		return errno.ErrCommentNotFound
+	}
+
+	postIDs, err := svc.dao.DeleteComment([]uint64{ID})
+	if err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+
+	svc.cleanCacheAfterEditComment(postIDs)
+	return nil
+}
+
+// cleanCacheAfterEditComment clean detail cache of posts whose comments were changed
+func (svc Service) cleanCacheAfterEditComment(postIDs []uint64) {
+	for _, postID := range postIDs {
+		svc.CleanCacheAfterEditArticle(postID)
+		svc.CleanCacheAfterEditPage(postID)
+	}
+}
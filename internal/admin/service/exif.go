@@ -0,0 +1,142 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"io/ioutil"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/exif"
+	"github.com/puti-projects/puti/internal/pkg/imaging"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+)
+
+// processJPEG read the EXIF of the uploaded JPEG image, rotate the image upright by its orientation
+// and strip the location in EXIF and XMP unless the option image_strip_location is off
+// It returns the image data to save and the EXIF meta; the meta is nil if the image does not have EXIF.
+// If the location is to be stripped but the meta data can not be read, the image is encoded again without meta data,
+// and the upload fails if the image can not be decoded.
+func processJPEG(file *uploadFile) ([]byte, *model.ExifMeta, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := ioutil.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	strip := isStripImageLocation()
+	e, err := exif.Parse(data)
+	if err != nil && err != exif.ErrNotFound {
+		if !strip {
+			return data, nil, nil
+		}
+		// the location in the broken EXIF can not be found, so all meta data is dropped by encoding the image again
+		data, err = encodeWithoutMeta(data)
+		return data, nil, err
+	}
+
+	// XMP may also contain the location, such as exif:GPSLatitude
+	xmpStripped := false
+	if strip {
+		if data, xmpStripped, err = exif.StripXMP(data); err != nil {
+			data, err = encodeWithoutMeta(data)
+			return data, nil, err
+		}
+	}
+	if e == nil {
+		return data, nil, nil
+	}
+
+	meta := &model.ExifMeta{
+		Camera:      e.Camera(),
+		Width:       e.Width,
+		Height:      e.Height,
+		Orientation: e.Orientation,
+	}
+	if !e.TakenAt.IsZero() {
+		meta.TakenAt = e.TakenAt.Format("2006-01-02 15:04:05")
+	}
+
+	if strip {
+		gpsStripped := e.HasGPS && exif.StripGPS(data)
+		meta.LocationStripped = gpsStripped || xmpStripped
+	}
+
+	if e.Orientation > 1 {
+		if rotated, err := orientJPEG(data, e.Orientation); err != nil {
+			logger.Errorf("rotate image %s failed. %s", file.Filename, err)
+		} else {
+			data = rotated
+		}
+	}
+
+	// the dimensions in EXIF may be missing or not match the image after rotated
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		meta.Width, meta.Height = cfg.Width, cfg.Height
+	}
+
+	return data, meta, nil
+}
+
+// encodeWithoutMeta decode the image and encode it again, all meta data of the image is dropped
+func encodeWithoutMeta(data []byte) ([]byte, error) {
+	img, format, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// orientJPEG rotate the image by the orientation and keep its EXIF with the orientation reset
+func orientJPEG(data []byte, orientation int) ([]byte, error) {
+	img, format, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, imaging.Orient(img, orientation), format); err != nil {
+		return nil, err
+	}
+
+	exif.SetOrientation(data, 1)
+	return exif.Copy(buf.Bytes(), data), nil
+}
+
+// isStripImageLocation location of uploaded images is stripped by default, unless the option is off
+func isStripImageLocation() bool {
+	return cache.Options.Get("image_strip_location") != "off"
+}
+
+// createExifMeta save the EXIF info of the media as meta data
+func (svc Service) createExifMeta(mediaID uint64, meta *model.ExifMeta) error {
+	value, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	return svc.dao.CreateResourceMeta([]*model.ResourceMeta{{
+		ResourceID: mediaID,
+		MetaKey:    model.ResourceMetaExif,
+		MetaValue:  string(value),
+	}})
+}
+
+// getExifMeta get the EXIF info of the media, it returns nil if the media does not have EXIF
+func (svc Service) getExifMeta(mediaID uint64) (*model.ExifMeta, error) {
+	meta := &model.ExifMeta{}
+	if ok, err := svc.getResourceMetaJSON(mediaID, model.ResourceMetaExif, meta); !ok {
+		return nil, err
+	}
+	return meta, nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/imaging"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/storage"
+)
+
+// MediaSize url and dimensions of an image size
+type MediaSize struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// createImageSizes generate derivatives of the uploaded image and save their paths and dimensions as meta data
+// The derivative is skipped if the image is smaller than the size.
+// Only the first frame is used for the derivatives of animated GIF.
+// Images with more pixels than the limit in config are not decoded, they are saved without derivatives.
+// data is the processed image which is saved instead of the uploaded file, it is nil if the file is saved as it is.
+func (svc Service) createImageSizes(mediaID uint64, pathName string, file *uploadFile, data []byte) error {
+	if data == nil {
+		f, err := file.Open()
+		if err != nil {
+			return err
+		}
+		data, err = ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	img, format, err := imaging.Decode(bytes.NewReader(data))
+	if err == imaging.ErrFormat {
+		// such as SVG and WebP
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	createImageVariants(pathName, data, format)
+
+	bounds := img.Bounds()
+	sizes := map[string]*model.ImageSizeMeta{
+		model.ImageSizeFull: {Path: pathName, Width: bounds.Dx(), Height: bounds.Dy()},
+	}
+
+	for _, size := range model.ImageSizes {
+		width, _ := strconv.Atoi(cache.Options.Get("image_" + size + "_width"))
+		height, _ := strconv.Atoi(cache.Options.Get("image_" + size + "_height"))
+
+		resized, ok := resizeImage(img, size, width, height)
+		if !ok {
+			continue
+		}
+
+		b := resized.Bounds()
+		sizePath := imageSizePath(pathName, b.Dx(), b.Dy())
+		sizeData, err := saveImage(sizePath, resized, format)
+		if err != nil {
+			logger.Errorf("save %s size of media %d failed. %s", size, mediaID, err)
+			continue
+		}
+		createImageVariants(sizePath, sizeData, format)
+		sizes[size] = &model.ImageSizeMeta{Path: sizePath, Width: b.Dx(), Height: b.Dy()}
+	}
+
+	metas := make([]*model.ResourceMeta, 0, len(sizes))
+	for _, size := range append([]string{model.ImageSizeFull}, model.ImageSizes...) {
+		if sizes[size] == nil {
+			continue
+		}
+		value, err := json.Marshal(sizes[size])
+		if err != nil {
+			return err
+		}
+		metas = append(metas, &model.ResourceMeta{
+			ResourceID: mediaID,
+			MetaKey:    model.ResourceMetaImageSizePrefix + size,
+			MetaValue:  string(value),
+		})
+	}
+
+	return svc.dao.CreateResourceMeta(metas)
+}
+
+// resizeImage resize the image for the size; thumbnail is cropped and the others are scaled
+func resizeImage(img image.Image, size string, width, height int) (image.Image, bool) {
+	if size == model.ImageSizeThumbnail {
+		return imaging.Fill(img, width, height)
+	}
+	return imaging.Fit(img, width, height)
+}
+
+// imageSizePath the path of image derivative, which is the original path with dimensions before the extension
+// such as /uploads/2020/01/abc-150x150.jpg
+func imageSizePath(pathName string, width, height int) string {
+	ext := ""
+	if i := strings.LastIndex(pathName, "."); i > strings.LastIndex(pathName, "/") {
+		ext = pathName[i:]
+		pathName = pathName[:i]
+	}
+	return pathName + "-" + strconv.Itoa(width) + "x" + strconv.Itoa(height) + ext
+}
+
+// saveImage encode the image and save it to the storage, it returns the encoded data
+func saveImage(pathName string, img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	return data, storage.Engine.Put(pathName, bytes.NewReader(data), int64(len(data)), "image/"+format)
+}
+
+// createImageVariants convert the image to the variant formats which have encoder commands in config
+// the variants are served instead of the image when the browser accepts them; failures are only logged
+func createImageVariants(pathName string, data []byte, format string) {
+	if config.Upload == nil || !imaging.IsVariantSource(format) {
+		return
+	}
+
+	for _, variant := range imaging.VariantFormats {
+		command := config.Upload.ImageVariants[variant]
+		if command == "" {
+			continue
+		}
+
+		result, err := imaging.Convert(command, bytes.NewReader(data), path.Ext(pathName), variant)
+		if err != nil {
+			logger.Errorf("convert %s to %s failed. %s", pathName, variant, err)
+			continue
+		}
+		// the variant is useless if it is not smaller than the source
+		if len(result) >= len(data) {
+			continue
+		}
+
+		variantPath := imaging.VariantPath(pathName, variant)
+		if err := storage.Engine.Put(variantPath, bytes.NewReader(result), int64(len(result)), "image/"+variant); err != nil {
+			logger.Errorf("save %s failed. %s", variantPath, err)
+		}
+	}
+}
+
+// getMediaSizes get image sizes of media by media id
+func (svc Service) getMediaSizes(mediaIDs []uint64) (map[uint64]map[string]*MediaSize, error) {
+	metas, err := svc.dao.ListResourceMetaByPrefix(mediaIDs, model.ResourceMetaImageSizePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64]map[string]*MediaSize)
+	for _, meta := range metas {
+		size := &model.ImageSizeMeta{}
+		if err := json.Unmarshal([]byte(meta.MetaValue), size); err != nil {
+			logger.Errorf("unmarshal image size meta %d failed. %s", meta.ID, err)
+			continue
+		}
+
+		if result[meta.ResourceID] == nil {
+			result[meta.ResourceID] = make(map[string]*MediaSize)
+		}
+		name := strings.TrimPrefix(meta.MetaKey, model.ResourceMetaImageSizePrefix)
+		result[meta.ResourceID][name] = &MediaSize{URL: storage.URL(size.Path), Width: size.Width, Height: size.Height}
+	}
+
+	return result, nil
+}
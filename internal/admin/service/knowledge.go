@@ -8,6 +8,7 @@ import (
 	"github.com/puti-projects/puti/internal/admin/dao"
 	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/storage"
 	"github.com/puti-projects/puti/internal/utils"
 
 	"gorm.io/gorm"
@@ -74,6 +75,8 @@ func (svc *Service) CreateKnowledge(r *KnowledgeCreateRequest) error {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	svc.CleanCacheSitemap()
+
 	return nil
 }
 
@@ -95,6 +98,7 @@ func (svc Service) UpdateKnowledge(r *KnowledgeUpdateRequest) error {
 
 	// update finished. clean cache.
 	svc.CleanCacheAfterUpdateKnowledge(k.Slug)
+	svc.CleanCacheSitemap()
 	return nil
 }
 
@@ -160,7 +164,7 @@ func (svc *Service) GetKnowledgeInfo(kID uint64) (*KnowledgeInfo, error) {
 		}
 		coverImageID = m.ID
 		coverImageName = m.Title
-		coverImageURL = m.GUID
+		coverImageURL = storage.URL(m.GUID)
 	}
 
 	kInfo := &KnowledgeInfo{
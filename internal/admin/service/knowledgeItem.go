@@ -144,6 +144,7 @@ func (svc *Service) CreateKnowledgeItem(r *KnowledgeItemCreateRequest, userID ui
 
 	// clean list cache
 	svc.CleanCacheKnowledgeItemList(kItem.KnowledgeID)
+	svc.CleanCacheSitemap()
 	return rsp, nil
 }
 
@@ -289,6 +290,7 @@ func (svc *Service) UpdateKnowledgeItemContent(cr *KnowledgeItemUpdateContentReq
 	// update finished. clean cache.
 	symbol, _ := svc.dao.GetKnowledgeItemSymbolByID(kItemID)
 	svc.CleanCacheAfterUpdateKnowledgeItemContent(symbol)
+	svc.CleanCacheSitemap()
 	return rsp, nil
 }
 
@@ -316,5 +318,6 @@ func (svc *Service) DeleteKnowledgeItem(kItemID uint64) error {
 
 	// clean list cache
 	svc.CleanCacheKnowledgeItemList(kItem.KnowledgeID)
+	svc.CleanCacheSitemap()
 	return nil
 }
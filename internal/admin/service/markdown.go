@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/markdown"
+)
+
+// codeStyleOption option name of the code highlight style, which is used by themes and does not affect the rendered HTML
+const codeStyleOption = "markdown_code_style"
+
+// codeStylePath dir of the highlight.js styles
+var codeStylePath = config.StaticPath("assets/library/highlight/styles")
+
+// isMarkdownOption check if the option is one of the markdown options
+func isMarkdownOption(optionName string) bool {
+	for _, name := range OptionNamesMap["markdown"] {
+		if name == optionName {
+			return true
+		}
+	}
+	return false
+}
+
+// markdownOptions get the markdown engine options from the site options
+// The default option of the engine is used if the site option is not set.
+func markdownOptions() markdown.Options {
+	on := func(name string, defaultValue bool) bool {
+		switch cache.Options.Get(name) {
+		case "on":
+			return true
+		case "off":
+			return false
+		default:
+			return defaultValue
+		}
+	}
+
+	d := markdown.DefaultOptions()
+	return markdown.Options{
+		GFMTable:      on("markdown_gfm_table", d.GFMTable),
+		Footnotes:     on("markdown_footnotes", d.Footnotes),
+		ToC:           on("markdown_toc", d.ToC),
+		Emoji:         on("markdown_emoji", d.Emoji),
+		Math:          on("markdown_math", d.Math),
+		Mermaid:       on("markdown_mermaid", d.Mermaid),
+		HeadingAnchor: on("markdown_heading_anchor", d.HeadingAnchor),
+	}
+}
+
+// ApplyMarkdownOptions apply the markdown options to the markdown engine
+// It returns true if the engine is changed and the content should be rendered again.
+func ApplyMarkdownOptions() bool {
+	return markdown.SetOptions(markdownOptions())
+}
+
+// ListCodeStyles get names of the code highlight styles in the highlight library
+func ListCodeStyles() []string {
+	styles := make([]string, 0)
+	files, err := ioutil.ReadDir(codeStylePath)
+	if err != nil {
+		logger.Errorf("read code styles failed. %s", err)
+		return styles
+	}
+
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".css" {
+			continue
+		}
+		styles = append(styles, strings.TrimSuffix(f.Name(), ".css"))
+	}
+	return styles
+}
+
+// checkCodeStyle check if the code highlight style exists
+func checkCodeStyle(style string) error {
+	for _, s := range ListCodeStyles() {
+		if s == style {
+			return nil
+		}
+	}
+	return errno.New(errno.ErrValidation, nil).Addf("code style %s does not exist.", style)
+}
+
+// markdownRenderLock renders after the markdown options changed run one by one
+var markdownRenderLock sync.Mutex
+
+// updateMarkdownOptions apply the changed markdown options and render all content again in background
+// Rendering all posts may take long, so the option update does not wait for it;
+// if it fails, the content can be rendered again by /option/render.
+func (svc *Service) updateMarkdownOptions() {
+	if !ApplyMarkdownOptions() {
+		return
+	}
+
+	go func() {
+		markdownRenderLock.Lock()
+		defer markdownRenderLock.Unlock()
+
+		// the request context is done when the request is finished
+		bgSvc := New(context.Background())
+		rsp, err := bgSvc.RenderPosts()
+		if err != nil {
+			logger.Errorf("render posts after markdown options changed failed, render them again by /option/render. %s", err)
+			return
+		}
+		logger.Infof("markdown options changed, %d posts were rendered and %d of them changed", rsp.Total, rsp.Changed)
+	}()
+}
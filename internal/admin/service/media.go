@@ -2,21 +2,29 @@ package service
 
 import (
 	"bytes"
-	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/puti-projects/puti/internal/admin/dao"
 	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/config"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/storage"
 	"github.com/puti-projects/puti/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // MediaUploadResponse is the upload media request's response struct
@@ -35,8 +43,23 @@ type MediaUpdateRequest struct {
 
 // MediaListRequest is the media list request struct
 type MediaListRequest struct {
-	Limit int `form:"limit"`
-	Page  int `form:"page"`
+	Limit     int    `form:"limit"`
+	Page      int    `form:"page"`
+	Type      string `form:"type"`
+	Usage     string `form:"usage"`
+	MimeType  string `form:"mime_type"` // mime-type, or the prefix ends with "/" such as "image/"
+	UserID    uint64 `form:"user_id"`
+	StartDate string `form:"start_date"` // upload date in site timezone, such as 2020-01-02
+	EndDate   string `form:"end_date"`   // inclusive
+	Keyword   string `form:"keyword"`
+	Folder    string `form:"folder"` // "/" means media which are not in any folder
+}
+
+// MediaBulkRequest is the bulk move or delete media request struct
+type MediaBulkRequest struct {
+	IDs []uint64 `json:"ids"`
+	// Folder the folder to move into, empty means moving out of any folder; it is not used by delete
+	Folder string `json:"folder"`
 }
 
 // MediaListResponse returns total number of media and current page of media
@@ -52,6 +75,9 @@ type MediaInfo struct {
 	GUID       string `json:"url"`
 	Type       string `json:"type"`
 	UploadTime string `json:"upload_time"`
+	Folder     string `json:"folder"`
+	// Sizes image sizes by size name, include the full size of image and the poster of video and document
+	Sizes map[string]*MediaSize `json:"sizes,omitempty"`
 }
 
 // MediaDetail media info in detail inclue Mediainfo struct
@@ -59,6 +85,10 @@ type MediaDetail struct {
 	MediaInfo
 	Slug        string `json:"slug"`
 	Description string `json:"description"`
+	// Exif EXIF info read on upload; it is empty if the media is not a photo with EXIF
+	Exif *model.ExifMeta `json:"exif,omitempty"`
+	// Info duration, dimensions or page count of audio, video and document
+	Info *model.MediaInfoMeta `json:"info,omitempty"`
 }
 
 // MediaList media list
@@ -67,96 +97,231 @@ type MediaList struct {
 	IDMap map[uint64]*MediaInfo
 }
 
+// defaultUploadMaxSize max file size in megabytes by file type if it is not configured
+var defaultUploadMaxSize = map[string]int64{
+	model.ResourceTypePicture:  10,
+	model.ResourceTypeAudio:    50,
+	model.ResourceTypeVideo:    200,
+	model.ResourceTypeDocument: 20,
+	model.ResourceTypeText:     2,
+}
+
+// uploadFile the file to be saved as media, which is uploaded in a multipart form or in chunks
+type uploadFile struct {
+	Filename string
+	Ext      string
+	Size     int64
+	open     func() (multipart.File, error)
+}
+
+// Open open the content of the file
+func (f *uploadFile) Open() (multipart.File, error) {
+	return f.open()
+}
+
+// newUploadFile wrap the file uploaded in the multipart form
+func newUploadFile(file *multipart.FileHeader) *uploadFile {
+	return &uploadFile{
+		Filename: file.Filename,
+		Ext:      utils.GetFileExt(file),
+		Size:     file.Size,
+		open:     file.Open,
+	}
+}
+
 // UploadMedia upload media and save record
+// The existing media is returned if the user uploaded a file with the same content for the same usage before.
 func (svc Service) UploadMedia(c *gin.Context, userID, usage string, file *multipart.FileHeader) (ID uint64, GUID string, err error) {
-	fileNameWithoutExt, fileExt, pathName, dst, err := getFileSavePath(usage, file)
+	return svc.createMedia(userID, usage, newUploadFile(file))
+}
+
+// createMedia check the file, save it to the storage and create the media with its meta data
+func (svc Service) createMedia(userID, usage string, file *uploadFile) (ID uint64, GUID string, err error) {
+	mimeType, contentHash, err := checkUploadFile(file)
+	if err != nil {
+		return
+	}
+
+	uID, err := strconv.Atoi(userID)
 	if err != nil {
 		return
 	}
 
-	// Upload the file to specific dst.
-	if err = c.SaveUploadedFile(file, dst); err != nil {
+	// media of other users or usages are not reused, the uploader must be able to edit and delete the returned media
+	media, err := svc.dao.GetMediaByContentHash(contentHash, uint64(uID), usage)
+	if err == nil {
+		return media.ID, media.GUID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		err = errno.New(errno.ErrDatabase, err)
+		return
+	}
+
+	fileNameWithoutExt, _, pathName := getFileSavePath(usage, file, contentHash)
+	if pathName, err = uniqueFilePath(pathName); err != nil {
 		err = errno.New(errno.ErrUploadFile, err)
 		return
 	}
 
-	uID, err := strconv.Atoi(userID)
+	// JPEG photos are rotated upright and their location is stripped before saved
+	var data []byte
+	var exifMeta *model.ExifMeta
+	if mimeType == "image/jpeg" {
+		if data, exifMeta, err = processJPEG(file); err != nil {
+			err = errno.New(errno.ErrUploadFile, err)
+			return
+		}
+	}
+
+	// Upload the file to the storage.
+	if data != nil {
+		err = storage.Engine.Put(pathName, bytes.NewReader(data), int64(len(data)), mimeType)
+	} else {
+		err = saveUploadedFile(file, pathName, mimeType)
+	}
 	if err != nil {
+		err = errno.New(errno.ErrUploadFile, err)
 		return
 	}
 
-	ID, GUID, err = svc.dao.CreateMedia(uID, file.Filename, fileNameWithoutExt, fileExt, pathName, usage)
-	return
+	ID, GUID, err = svc.dao.CreateMedia(uID, file.Filename, fileNameWithoutExt, mimeType, pathName, usage, contentHash)
+	if err != nil {
+		return
+	}
+
+	// the upload is done even if the exif, image sizes and media info can not be saved
+	if exifMeta != nil {
+		if err := svc.createExifMeta(ID, exifMeta); err != nil {
+			logger.Errorf("create exif meta of media %d failed. %s", ID, err)
+		}
+	}
+	switch utils.GetFileType(mimeType) {
+	case model.ResourceTypePicture:
+		if err := svc.createImageSizes(ID, pathName, file, data); err != nil {
+			logger.Errorf("create image sizes of media %d failed. %s", ID, err)
+		}
+	case model.ResourceTypeAudio, model.ResourceTypeVideo, model.ResourceTypeDocument:
+		if err := svc.createMediaInfo(ID, pathName, mimeType, file); err != nil {
+			logger.Errorf("create media info of media %d failed. %s", ID, err)
+		}
+	}
+
+	return ID, GUID, nil
+}
+
+// checkUploadFile check the file type by its content and the size limit of the type
+// it returns the detected mime-type and the sha256 of the file content
+func checkUploadFile(file *uploadFile) (mimeType, contentHash string, err error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", "", errno.New(errno.ErrUploadFile, err)
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", "", errno.New(errno.ErrUploadFile, err)
+	}
+
+	ext := file.Ext
+	mimeType = utils.DetectFileMimeType(head[:n], ext)
+	if !utils.IsAllowedMimeType(mimeType) {
+		return "", "", errno.New(errno.ErrFileTypeNotAllowed, nil).Add(mimeType)
+	}
+	if !utils.IsFileExtMatch(ext, mimeType) {
+		return "", "", errno.New(errno.ErrFileTypeMismatch, nil).Addf("%s is not %s.", ext, mimeType)
+	}
+
+	if err := checkUploadSize(mimeType, file.Size); err != nil {
+		return "", "", err
+	}
+
+	h := sha256.New()
+	h.Write(head[:n])
+	if _, err := io.Copy(h, src); err != nil {
+		return "", "", errno.New(errno.ErrUploadFile, err)
+	}
+
+	return mimeType, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checkUploadSize check the file size by the size limit of the file type
+func checkUploadSize(mimeType string, size int64) error {
+	fileType := utils.GetFileType(mimeType)
+	maxSize := defaultUploadMaxSize[fileType]
+	if config.Upload != nil && config.Upload.MaxSize[fileType] > 0 {
+		maxSize = config.Upload.MaxSize[fileType]
+	}
+	if maxSize > 0 && size > maxSize<<20 {
+		return errno.New(errno.ErrFileTooLarge, nil).Addf("The max size of %s is %dMB.", fileType, maxSize)
+	}
+	return nil
 }
 
-// getFileSavePath general the hole uri for the file
-func getFileSavePath(usage string, file *multipart.FileHeader) (
+// saveUploadedFile save the uploaded file to the storage
+func saveUploadedFile(file *uploadFile, pathName, mimeType string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return storage.Engine.Put(pathName, src, file.Size, mimeType)
+}
+
+// getFileSavePath general the hole uri for the file, the file is named by its content hash
+func getFileSavePath(usage string, file *uploadFile, contentHash string) (
 	fileNameWithoutExt string,
 	fileExt string,
 	pathName string,
-	dst string,
-	err error,
 ) {
 	// General the save path by upload time
-	savePath, err := getSavePath(usage)
-	if err != nil {
-		return
-	}
+	savePath := getSavePath(usage)
 
 	// set variables
-	fileExt = utils.GetFileExt(file)
+	fileExt = file.Ext
 	fileNameWithoutExt = strings.TrimSuffix(file.Filename, fileExt)
-	unixTime := time.Now().Unix()
-
-	// set buf string
-	buf := bytes.NewBufferString(fileNameWithoutExt)
-	buf.Write([]byte(strconv.FormatInt(unixTime, 10))) // add a time string
-	// md5 encode
-	h := md5.New()
-	h.Write([]byte(buf.String())) // encode the buf.String()
-	newFileName := hex.EncodeToString(h.Sum(nil))
 
 	// final save path with file name
-	pathName = savePath + newFileName + fileExt
-	dst = "." + pathName
+	pathName = savePath + contentHash[:32] + fileExt
 
-	return fileNameWithoutExt, fileExt, pathName, dst, nil
+	return fileNameWithoutExt, fileExt, pathName
+}
+
+// uniqueFilePath get a path which is not used by other files
+// The same content uploaded by another user or for another usage is saved as another file,
+// so deleting one media does not remove the file of the other.
+func uniqueFilePath(pathName string) (string, error) {
+	ext := path.Ext(pathName)
+	base := strings.TrimSuffix(pathName, ext)
+	for i := 1; ; i++ {
+		_, err := storage.Engine.Stat(pathName)
+		if errors.Is(err, storage.ErrNotExist) {
+			return pathName, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		pathName = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
 }
 
 // getSavePath general the hole uri by upload time
-func getSavePath(usage string) (string, error) {
+// directories are created by the storage when the file is saved
+func getSavePath(usage string) string {
 	savePathURI := config.UploadPath
 
 	// for cover picture
 	if usage == "cover" {
-		// check cover path exist
-		coverPath := fmt.Sprintf(".%s%s", savePathURI, "cover")
-		if err := utils.CheckPathAndCreate(coverPath); err != nil {
-			return "", err
-		}
-
-		savePath := fmt.Sprintf("%s%s/", savePathURI, "cover")
-		return savePath, nil
+		return fmt.Sprintf("%s%s/", savePathURI, "cover")
 	}
 
 	// for common picture
 	now := time.Now()
-	// handel year path
 	year := utils.GetFormatTime(&now, "2006")
-	yearPath := fmt.Sprintf(".%s%s", savePathURI, year)
-	if err := utils.CheckPathAndCreate(yearPath); err != nil {
-		return "", err
-	}
-
-	// handle month path
 	month := utils.GetFormatTime(&now, "01")
-	monthPath := fmt.Sprintf(".%s%s/%s", savePathURI, year, month)
-	if err := utils.CheckPathAndCreate(monthPath); err != nil {
-		return "", err
-	}
-
-	savePath := fmt.Sprintf("%s%s/%s/", savePathURI, year, month)
-	return savePath, nil
+	return fmt.Sprintf("%s%s/%s/", savePathURI, year, month)
 }
 
 // GetMediaByID get media by ID
@@ -180,7 +345,7 @@ func (svc Service) GetMediaDetail(ID uint64) (*MediaDetail, error) {
 		MediaInfo: MediaInfo{
 			ID:         media.ID,
 			Title:      media.Title,
-			GUID:       media.GUID,
+			GUID:       storage.URL(media.GUID),
 			Type:       media.Type,
 			UploadTime: utils.GetFormatTime(&media.CreatedAt, "2006-01-02 15:04:05"),
 		},
@@ -188,14 +353,40 @@ func (svc Service) GetMediaDetail(ID uint64) (*MediaDetail, error) {
 		Description: media.Description,
 	}
 
+	sizes, err := svc.getMediaSizes([]uint64{media.ID})
+	if err != nil {
+		return nil, err
+	}
+	mediaInfo.Sizes = sizes[media.ID]
+
+	folders, err := svc.getMediaFolders([]uint64{media.ID})
+	if err != nil {
+		return nil, err
+	}
+	mediaInfo.Folder = folders[media.ID]
+
+	if mediaInfo.Exif, err = svc.getExifMeta(media.ID); err != nil {
+		return nil, err
+	}
+	info := &model.MediaInfoMeta{}
+	if ok, err := svc.getResourceMetaJSON(media.ID, model.ResourceMetaMediaInfo, info); err != nil {
+		return nil, err
+	} else if ok {
+		mediaInfo.Info = info
+	}
+
 	return mediaInfo, nil
 }
 
 // ListMedia returns current page media list and the total number of media
-func (svc Service) ListMedia(limit, page int) ([]*MediaInfo, int64, error) {
+func (svc Service) ListMedia(r *MediaListRequest) ([]*MediaInfo, int64, error) {
 	infos := make([]*MediaInfo, 0)
 
-	medias, count, err := svc.dao.ListMedia(limit, page)
+	filter, err := getMediaFilter(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	medias, count, err := svc.dao.ListMedia(filter, r.Limit, r.Page)
 	if err != nil {
 		return nil, count, err
 	}
@@ -205,6 +396,15 @@ func (svc Service) ListMedia(limit, page int) ([]*MediaInfo, int64, error) {
 		ids = append(ids, media.ID)
 	}
 
+	sizes, err := svc.getMediaSizes(ids)
+	if err != nil {
+		return nil, count, err
+	}
+	folders, err := svc.getMediaFolders(ids)
+	if err != nil {
+		return nil, count, err
+	}
+
 	wg := sync.WaitGroup{}
 	mediaList := MediaList{
 		Lock:  new(sync.Mutex),
@@ -224,9 +424,11 @@ func (svc Service) ListMedia(limit, page int) ([]*MediaInfo, int64, error) {
 			mediaList.IDMap[u.ID] = &MediaInfo{
 				ID:         u.ID,
 				Title:      u.Title,
-				GUID:       u.GUID,
+				GUID:       storage.URL(u.GUID),
 				Type:       u.Type,
 				UploadTime: u.CreatedAt.In(config.TimeLoc()).Format("2006-01-02 15:04"),
+				Folder:     folders[u.ID],
+				Sizes:      sizes[u.ID],
 			}
 		}(u)
 	}
@@ -245,6 +447,42 @@ func (svc Service) ListMedia(limit, page int) ([]*MediaInfo, int64, error) {
 	return infos, count, nil
 }
 
+// getMediaFilter convert the request to the filter of media list
+func getMediaFilter(r *MediaListRequest) (*dao.MediaFilter, error) {
+	filter := &dao.MediaFilter{
+		Type:     r.Type,
+		Usage:    r.Usage,
+		MimeType: r.MimeType,
+		UserID:   r.UserID,
+		Keyword:  r.Keyword,
+		Folder:   r.Folder,
+	}
+	if r.Folder != "/" && r.Folder != "" {
+		folder, err := cleanMediaFolder(r.Folder)
+		if err != nil {
+			return nil, err
+		}
+		filter.Folder = folder
+	}
+
+	if r.StartDate != "" {
+		start, err := time.ParseInLocation("2006-01-02", r.StartDate, config.TimeLoc())
+		if err != nil {
+			return nil, errno.New(errno.ErrValidation, err).Add("start_date should be in format of 2006-01-02.")
+		}
+		filter.Start = start.UTC()
+	}
+	if r.EndDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", r.EndDate, config.TimeLoc())
+		if err != nil {
+			return nil, errno.New(errno.ErrValidation, err).Add("end_date should be in format of 2006-01-02.")
+		}
+		filter.End = end.AddDate(0, 0, 1).UTC()
+	}
+
+	return filter, nil
+}
+
 // UpdateMedia update media info
 func (svc Service) UpdateMedia(r *MediaUpdateRequest, userID int) (err error) {
 	if err := svc.dao.UpdateMedia(uint64(userID), r.Title, r.Slug, r.Description); err != nil {
@@ -254,11 +492,101 @@ func (svc Service) UpdateMedia(r *MediaUpdateRequest, userID int) (err error) {
 	return nil
 }
 
-// DeleteMedia delete media
-func (svc Service) DeleteMedia(userID uint64) error {
-	if err := svc.dao.DeleteMediaByID(userID); err != nil {
+// DeleteMedia delete media with its file and image sizes
+// the record is deleted first; files which can not be removed are left as orphan files and reported by the scan
+func (svc Service) DeleteMedia(mediaID uint64) error {
+	media, err := svc.dao.GetMediaByID(mediaID)
+	if err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+	paths, err := svc.mediaFilePaths(media)
+	if err != nil {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	if err := svc.dao.DeleteMediaByID(mediaID); err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+	// all meta data include image sizes and content hash
+	if err := svc.dao.DeleteResourceMeta(mediaID, ""); err != nil {
+		logger.Errorf("delete meta of media %d failed. %s", mediaID, err)
+	}
+
+	removeMediaFiles(paths)
+	svc.CleanCacheImageSize(media.GUID)
+	return nil
+}
+
+// mediaFolderMaxLength max length of the folder name
+const mediaFolderMaxLength = 100
+
+// cleanMediaFolder trim spaces and slashes of the folder name, and remove empty parts of nested folders
+func cleanMediaFolder(folder string) (string, error) {
+	var parts []string
+	for _, part := range strings.Split(folder, "/") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	folder = strings.Join(parts, "/")
+	if utf8.RuneCountInString(folder) > mediaFolderMaxLength {
+		return "", errno.New(errno.ErrValidation, nil).Addf("folder can not be longer than %d characters.", mediaFolderMaxLength)
+	}
+	return folder, nil
+}
+
+// getMediaFolders get folders of media by media id
+func (svc Service) getMediaFolders(mediaIDs []uint64) (map[uint64]string, error) {
+	metas, err := svc.dao.ListResourceMetaByPrefix(mediaIDs, model.ResourceMetaFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make(map[uint64]string, len(metas))
+	for _, meta := range metas {
+		if meta.MetaKey == model.ResourceMetaFolder {
+			folders[meta.ResourceID] = meta.MetaValue
+		}
+	}
+	return folders, nil
+}
+
+// ListMediaFolder get all folders which have media
+func (svc Service) ListMediaFolder() ([]*dao.MediaFolderResult, error) {
+	folders, err := svc.dao.ListMediaFolder()
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	return folders, nil
+}
+
+// MoveMedia move media into the folder in bulk
+func (svc Service) MoveMedia(r *MediaBulkRequest) error {
+	if len(r.IDs) == 0 {
+		return errno.New(errno.ErrValidation, nil).Add("ids can not be empty.")
+	}
+	folder, err := cleanMediaFolder(r.Folder)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.dao.SetMediaFolder(r.IDs, folder); err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+	return nil
+}
+
+// BulkDeleteMedia delete media with their files in bulk
+func (svc Service) BulkDeleteMedia(r *MediaBulkRequest) error {
+	if len(r.IDs) == 0 {
+		return errno.New(errno.ErrValidation, nil).Add("ids can not be empty.")
+	}
+
+	for _, id := range r.IDs {
+		if err := svc.DeleteMedia(id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/imaging"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/storage"
+)
+
+// MediaOrphanResponse result of media file scan
+type MediaOrphanResponse struct {
+	// OrphanFiles files under the upload path which do not belong to any media
+	OrphanFiles []*OrphanFile `json:"orphanFiles"`
+	// MissingFiles media whose file does not exist
+	MissingFiles []*MediaInfo `json:"missingFiles"`
+	// UnusedMedia media which are not referred by any article, page, knowledge item, subject cover or knowledge cover
+	// they are only reported and can be deleted as common media
+	UnusedMedia []*MediaInfo `json:"unusedMedia"`
+}
+
+// OrphanFile file which does not belong to any media
+type OrphanFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime string `json:"mod_time"`
+}
+
+// MediaOrphanCleanRequest orphan files and missing file media to clean up
+type MediaOrphanCleanRequest struct {
+	Files    []string `json:"files"`
+	MediaIDs []uint64 `json:"media_ids"`
+}
+
+// MediaOrphanCleanResponse the number of cleaned up files and media
+type MediaOrphanCleanResponse struct {
+	DeletedFiles int `json:"deletedFiles"`
+	DeletedMedia int `json:"deletedMedia"`
+}
+
+// mediaFilePaths get paths of the media file and its image sizes
+func (svc Service) mediaFilePaths(media *model.Media) ([]string, error) {
+	paths := []string{media.GUID}
+
+	metas, err := svc.dao.ListResourceMetaByPrefix([]uint64{media.ID}, model.ResourceMetaImageSizePrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, meta := range metas {
+		size := &model.ImageSizeMeta{}
+		if err := json.Unmarshal([]byte(meta.MetaValue), size); err != nil {
+			logger.Errorf("unmarshal image size meta %d failed. %s", meta.ID, err)
+			continue
+		}
+		if size.Path != media.GUID {
+			paths = append(paths, size.Path)
+		}
+	}
+
+	return paths, nil
+}
+
+// removeMediaFiles remove files of media from the storage; files which do not exist are ignored
+func removeMediaFiles(paths []string) {
+	for _, path := range paths {
+		if !isMediaFilePath(path) {
+			logger.Errorf("refuse to remove media file out of the upload path: %s", path)
+			continue
+		}
+		if err := storage.Engine.Delete(path); err != nil {
+			logger.Errorf("remove media file %s failed. %s", path, err)
+		}
+		for _, format := range imaging.VariantFormats {
+			if err := storage.Engine.Delete(imaging.VariantPath(path, format)); err != nil {
+				logger.Errorf("remove media file %s failed. %s", imaging.VariantPath(path, format), err)
+			}
+		}
+	}
+}
+
+// isMediaFilePath check if the path is in the upload path and not in the user avatar path
+func isMediaFilePath(path string) bool {
+	return filepath.ToSlash(filepath.Clean("/"+path)) == path &&
+		strings.HasPrefix(path, config.UploadPath) &&
+		!strings.HasPrefix(path, config.UploadUserAvatarPath)
+}
+
+// ScanMediaOrphans scan the upload path and media records
+// files of deleted media are reported as orphan files, and user avatars are skipped
+func (svc Service) ScanMediaOrphans() (*MediaOrphanResponse, error) {
+	medias, err := svc.dao.ListAllMedia()
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	metas, err := svc.dao.ListAllResourceMetaByPrefix(model.ResourceMetaImageSizePrefix)
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+
+	// all paths which belong to media
+	known := make(map[string]bool, len(medias)+len(metas))
+	for _, media := range medias {
+		known[media.GUID] = true
+	}
+	sizePaths := make(map[uint64][]string)
+	for _, meta := range metas {
+		size := &model.ImageSizeMeta{}
+		if err := json.Unmarshal([]byte(meta.MetaValue), size); err != nil {
+			logger.Errorf("unmarshal image size meta %d failed. %s", meta.ID, err)
+			continue
+		}
+		known[size.Path] = true
+		sizePaths[meta.ResourceID] = append(sizePaths[meta.ResourceID], size.Path)
+	}
+
+	result := &MediaOrphanResponse{
+		OrphanFiles:  make([]*OrphanFile, 0),
+		MissingFiles: make([]*MediaInfo, 0),
+		UnusedMedia:  make([]*MediaInfo, 0),
+	}
+
+	files, err := storage.Engine.List(config.UploadPath)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(files))
+	for _, f := range files {
+		exists[f.Path] = true
+		// variants such as WebP belong to the media of their source
+		if known[f.Path] || known[imaging.VariantSourcePath(f.Path)] || strings.HasPrefix(f.Path, config.UploadUserAvatarPath) {
+			continue
+		}
+		result.OrphanFiles = append(result.OrphanFiles, &OrphanFile{
+			Path:    f.Path,
+			Size:    f.Size,
+			ModTime: f.ModTime.In(config.TimeLoc()).Format("2006-01-02 15:04"),
+		})
+	}
+	sort.Slice(result.OrphanFiles, func(i, j int) bool {
+		return result.OrphanFiles[i].Path < result.OrphanFiles[j].Path
+	})
+
+	contents, err := svc.dao.ListMediaReferenceContents()
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	coverIDs, err := svc.dao.ListMediaCoverIDs()
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	covers := make(map[uint64]bool, len(coverIDs))
+	for _, id := range coverIDs {
+		covers[id] = true
+	}
+
+	for _, media := range medias {
+		info := &MediaInfo{
+			ID:         media.ID,
+			Title:      media.Title,
+			GUID:       storage.URL(media.GUID),
+			Type:       media.Type,
+			UploadTime: media.CreatedAt.In(config.TimeLoc()).Format("2006-01-02 15:04"),
+		}
+
+		if !exists[media.GUID] {
+			result.MissingFiles = append(result.MissingFiles, info)
+			continue
+		}
+
+		if !covers[media.ID] && !isMediaReferred(contents, append([]string{media.GUID}, sizePaths[media.ID]...)) {
+			result.UnusedMedia = append(result.UnusedMedia, info)
+		}
+	}
+
+	return result, nil
+}
+
+// isMediaReferred check if any content contains any url of the media
+func isMediaReferred(contents []string, paths []string) bool {
+	for _, content := range contents {
+		for _, path := range paths {
+			if strings.Contains(content, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CleanMediaOrphans remove the orphan files and delete the media whose file is missing
+// Only the files and media which are still reported by a new scan are cleaned up.
+func (svc Service) CleanMediaOrphans(r *MediaOrphanCleanRequest) (*MediaOrphanCleanResponse, error) {
+	scan, err := svc.ScanMediaOrphans()
+	if err != nil {
+		return nil, err
+	}
+
+	orphanFiles := make(map[string]bool, len(scan.OrphanFiles))
+	for _, f := range scan.OrphanFiles {
+		orphanFiles[f.Path] = true
+	}
+	missingFiles := make(map[uint64]*MediaInfo, len(scan.MissingFiles))
+	for _, m := range scan.MissingFiles {
+		missingFiles[m.ID] = m
+	}
+
+	result := &MediaOrphanCleanResponse{}
+	for _, path := range r.Files {
+		if !orphanFiles[path] {
+			continue
+		}
+		if !isMediaFilePath(path) {
+			continue
+		}
+		if err := storage.Engine.Delete(path); err != nil {
+			logger.Errorf("remove orphan file %s failed. %s", path, err)
+			continue
+		}
+		delete(orphanFiles, path)
+		result.DeletedFiles++
+	}
+
+	for _, id := range r.MediaIDs {
+		if _, ok := missingFiles[id]; !ok {
+			continue
+		}
+		if err := svc.DeleteMedia(id); err != nil {
+			return result, err
+		}
+		delete(missingFiles, id)
+		result.DeletedMedia++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/imaging"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/mediainfo"
+	"github.com/puti-projects/puti/internal/pkg/storage"
+	"github.com/puti-projects/puti/internal/utils"
+)
+
+// createMediaInfo read the duration, dimensions or page count of audio, video and document,
+// and generate the poster if its command is configured; they are saved as meta data
+func (svc Service) createMediaInfo(mediaID uint64, pathName, mimeType string, file *uploadFile) error {
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var info *model.MediaInfoMeta
+	if mediainfo.IsSupported(mimeType) {
+		if i, err := mediainfo.Probe(f, file.Size, mimeType); err != nil {
+			logger.Errorf("read media info of media %d failed. %s", mediaID, err)
+		} else {
+			info = &model.MediaInfoMeta{Duration: i.Duration, Width: i.Width, Height: i.Height, Pages: i.Pages}
+		}
+	}
+
+	metas := make([]*model.ResourceMeta, 0, 2)
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if poster, err := createPoster(pathName, utils.GetFileType(mimeType), f); err != nil {
+		logger.Errorf("create poster of media %d failed. %s", mediaID, err)
+	} else if poster != nil {
+		value, err := json.Marshal(poster)
+		if err != nil {
+			return err
+		}
+		metas = append(metas, &model.ResourceMeta{
+			ResourceID: mediaID,
+			MetaKey:    model.ResourceMetaImageSizePrefix + model.ImageSizePoster,
+			MetaValue:  string(value),
+		})
+
+		// the dimensions of video in unknown container are the same as its poster
+		if info != nil && utils.GetFileType(mimeType) == model.ResourceTypeVideo && info.Width == 0 {
+			info.Width, info.Height = poster.Width, poster.Height
+		}
+	}
+
+	if info != nil {
+		value, err := json.Marshal(info)
+		if err != nil {
+			return err
+		}
+		metas = append(metas, &model.ResourceMeta{
+			ResourceID: mediaID,
+			MetaKey:    model.ResourceMetaMediaInfo,
+			MetaValue:  string(value),
+		})
+	}
+
+	return svc.dao.CreateResourceMeta(metas)
+}
+
+// createPoster generate the poster image of the media by the command of its type in config
+// The poster is saved beside the media, such as /uploads/2020/01/abc-poster.jpg.
+// It returns nil if the command is not configured.
+func createPoster(pathName, fileType string, r io.Reader) (*model.ImageSizeMeta, error) {
+	if config.Upload == nil || config.Upload.Posters[fileType] == "" {
+		return nil, nil
+	}
+
+	data, err := imaging.Convert(config.Upload.Posters[fileType], r, path.Ext(pathName), "jpg")
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	posterPath := strings.TrimSuffix(pathName, path.Ext(pathName)) + "-" + model.ImageSizePoster + ".jpg"
+	if err := storage.Engine.Put(posterPath, bytes.NewReader(data), int64(len(data)), "image/jpeg"); err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+	return &model.ImageSizeMeta{Path: posterPath, Width: b.Dx(), Height: b.Dy()}, nil
+}
+
+// getResourceMetaJSON get the meta value of the media by key and unmarshal it into v
+// It returns false if the media does not have the meta.
+func (svc Service) getResourceMetaJSON(mediaID uint64, key string, v interface{}) (bool, error) {
+	metas, err := svc.dao.ListResourceMetaByPrefix([]uint64{mediaID}, key)
+	if err != nil {
+		return false, err
+	}
+
+	for _, m := range metas {
+		if m.MetaKey != key {
+			continue
+		}
+		if err := json.Unmarshal([]byte(m.MetaValue), v); err != nil {
+			logger.Errorf("unmarshal %s meta %d failed. %s", key, m.ID, err)
+			return false, nil
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
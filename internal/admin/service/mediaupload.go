@@ -0,0 +1,421 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/utils"
+)
+
+// ChunkUploadCreateRequest request to start a chunked upload
+type ChunkUploadCreateRequest struct {
+	Filename string `json:"filename" binding:"required"`
+	Size     int64  `json:"size" binding:"required"`
+	// Checksum sha256 of the whole file in hex, it is verified when the upload is completed
+	Checksum string `json:"checksum" binding:"required"`
+	Usage    string `json:"usage"`
+}
+
+// ChunkUploadInfo status of a chunked upload; the next chunk starts at the offset
+type ChunkUploadInfo struct {
+	ID        string `json:"id"`
+	Filename  string `json:"filename"`
+	Size      int64  `json:"size"`
+	Offset    int64  `json:"offset"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// chunkUpload state of a chunked upload, which is saved beside the data file in the temp path
+// The temp path is local to the instance, so with more than one instance behind a load balancer,
+// requests of chunked uploads must be routed to the same instance (sticky sessions) or the temp path must be shared.
+type chunkUpload struct {
+	ID        string    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum"`
+	Usage     string    `json:"usage"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// defaultChunkUploadExpire abandoned chunked uploads are removed after the time since their last chunk
+const defaultChunkUploadExpire = 24 * time.Hour
+
+// ChunkUploadCleanTickerRepeatTime interval of removing the abandoned chunked uploads
+const ChunkUploadCleanTickerRepeatTime = time.Hour
+
+// ChunkUploadCleanTickerStopChan chan for stop the chunked upload clean ticker
+var ChunkUploadCleanTickerStopChan = make(chan bool)
+
+// chunkUploadLocks locks of chunked uploads by id, chunks of an upload are appended one by one
+var chunkUploadLocks sync.Map
+
+// lockChunkUpload lock the chunked upload if it exists
+// Locks are only kept for existing uploads and removed with them, so probing ids does not add locks.
+func lockChunkUpload(id string) (func(), error) {
+	if !isChunkUploadID(id) {
+		return nil, errno.ErrUploadNotFound
+	}
+	if _, err := os.Stat(chunkUploadFile(id, ".json")); os.IsNotExist(err) {
+		return nil, errno.ErrUploadNotFound
+	} else if err != nil {
+		return nil, errno.New(errno.ErrUploadFile, err)
+	}
+
+	v, _ := chunkUploadLocks.LoadOrStore(id, new(sync.Mutex))
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock, nil
+}
+
+// chunkUploadPath the temp path of chunked uploads
+func chunkUploadPath() string {
+	if config.Upload != nil && config.Upload.TempPath != "" {
+		return config.Upload.TempPath
+	}
+	return filepath.Join(os.TempDir(), "puti-uploads")
+}
+
+func chunkUploadExpire() time.Duration {
+	if config.Upload != nil && config.Upload.TempExpire > 0 {
+		return time.Duration(config.Upload.TempExpire) * time.Hour
+	}
+	return defaultChunkUploadExpire
+}
+
+// chunkUploadFile the data file or the state file of the upload
+func chunkUploadFile(id, ext string) string {
+	return filepath.Join(chunkUploadPath(), id+ext)
+}
+
+// isChunkUploadID check the id before it is used in file path
+func isChunkUploadID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// CreateChunkUpload start a chunked upload
+// The file type and size are checked by the file name and the declared size, and the content is checked when completed.
+func (svc Service) CreateChunkUpload(userID uint64, r *ChunkUploadCreateRequest) (*ChunkUploadInfo, error) {
+	ext := path.Ext(r.Filename)
+	mimeType := strings.TrimSpace(strings.Split(utils.GetFileMimeTypeByExt(ext), ";")[0])
+	if !utils.IsAllowedMimeType(mimeType) {
+		return nil, errno.New(errno.ErrFileTypeNotAllowed, nil).Add(ext)
+	}
+	if r.Size <= 0 {
+		return nil, errno.New(errno.ErrValidation, nil).Add("size must be greater than 0.")
+	}
+	if err := checkUploadSize(mimeType, r.Size); err != nil {
+		return nil, err
+	}
+	checksum := strings.ToLower(r.Checksum)
+	if b, err := hex.DecodeString(checksum); err != nil || len(b) != sha256.Size {
+		return nil, errno.New(errno.ErrValidation, nil).Add("checksum must be sha256 in hex.")
+	}
+
+	if err := os.MkdirAll(chunkUploadPath(), 0700); err != nil {
+		return nil, errno.New(errno.ErrUploadFile, err)
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errno.New(errno.ErrUploadFile, err)
+	}
+
+	usage := r.Usage
+	if usage == "" {
+		usage = "common"
+	}
+	now := time.Now()
+	u := &chunkUpload{
+		ID:        hex.EncodeToString(b),
+		UserID:    userID,
+		Filename:  r.Filename,
+		Size:      r.Size,
+		Checksum:  checksum,
+		Usage:     usage,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := ioutil.WriteFile(chunkUploadFile(u.ID, ".part"), nil, 0600); err != nil {
+		return nil, errno.New(errno.ErrUploadFile, err)
+	}
+	if err := saveChunkUpload(u); err != nil {
+		return nil, errno.New(errno.ErrUploadFile, err)
+	}
+
+	return u.info(0), nil
+}
+
+// GetChunkUpload get the status of the chunked upload, so that the client can resume from the offset
+func (svc Service) GetChunkUpload(userID uint64, id string) (*ChunkUploadInfo, error) {
+	unlock, err := lockChunkUpload(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	u, offset, err := loadChunkUpload(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.info(offset), nil
+}
+
+// AppendChunkUpload append the chunk at the offset of the chunked upload
+// The offset must be the current offset, so a chunk which is sent again after a lost response is rejected.
+// If the checksum (sha256 in hex) of the chunk is given and does not match, the chunk is discarded.
+func (svc Service) AppendChunkUpload(userID uint64, id string, offset int64, checksum string, chunk io.Reader) (*ChunkUploadInfo, error) {
+	unlock, err := lockChunkUpload(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	u, current, err := loadChunkUpload(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if offset != current {
+		return nil, errno.New(errno.ErrUploadOffset, nil).Addf("The current offset is %d.", current)
+	}
+
+	f, err := os.OpenFile(chunkUploadFile(id, ".part"), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errno.New(errno.ErrUploadFile, err)
+	}
+	defer f.Close()
+
+	// the data more than the declared size is rejected
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(chunk, u.Size-offset+1))
+	discard := func() {
+		if err := f.Truncate(offset); err != nil {
+			logger.Errorf("truncate chunked upload %s failed. %s", id, err)
+		}
+	}
+	if err != nil {
+		discard()
+		return nil, errno.New(errno.ErrUploadFile, err)
+	}
+	if offset+n > u.Size {
+		discard()
+		return nil, errno.New(errno.ErrFileTooLarge, nil).Addf("The declared size is %d.", u.Size)
+	}
+	if checksum != "" && !strings.EqualFold(checksum, hex.EncodeToString(h.Sum(nil))) {
+		discard()
+		return nil, errno.ErrChecksumMismatch
+	}
+
+	u.UpdatedAt = time.Now()
+	if err := saveChunkUpload(u); err != nil {
+		return nil, errno.New(errno.ErrUploadFile, err)
+	}
+
+	return u.info(offset + n), nil
+}
+
+// CompleteChunkUpload verify the checksum of the whole file and save it as media
+// The file is checked and saved in the same way as the file uploaded in one request.
+func (svc Service) CompleteChunkUpload(userID uint64, id string) (ID uint64, GUID string, err error) {
+	unlock, err := lockChunkUpload(id)
+	if err != nil {
+		return 0, "", err
+	}
+	defer unlock()
+
+	u, offset, err := loadChunkUpload(userID, id)
+	if err != nil {
+		return 0, "", err
+	}
+	if offset != u.Size {
+		return 0, "", errno.New(errno.ErrUploadIncomplete, nil).Addf("%d of %d bytes are received.", offset, u.Size)
+	}
+
+	dataPath := chunkUploadFile(id, ".part")
+	f, err := os.Open(dataPath)
+	if err != nil {
+		return 0, "", errno.New(errno.ErrUploadFile, err)
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		return 0, "", errno.New(errno.ErrUploadFile, err)
+	}
+	// the client should start over since the received data is broken
+	if hex.EncodeToString(h.Sum(nil)) != u.Checksum {
+		removeChunkUpload(id)
+		return 0, "", errno.ErrChecksumMismatch
+	}
+
+	file := &uploadFile{
+		Filename: u.Filename,
+		Ext:      path.Ext(u.Filename),
+		Size:     u.Size,
+		open: func() (multipart.File, error) {
+			return os.Open(dataPath)
+		},
+	}
+	ID, GUID, err = svc.createMedia(strconv.FormatUint(u.UserID, 10), u.Usage, file)
+	if err != nil {
+		return 0, "", err
+	}
+
+	removeChunkUpload(id)
+	return ID, GUID, nil
+}
+
+// AbortChunkUpload cancel the chunked upload and remove its data
+func (svc Service) AbortChunkUpload(userID uint64, id string) error {
+	unlock, err := lockChunkUpload(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, _, err := loadChunkUpload(userID, id); err != nil {
+		return err
+	}
+	removeChunkUpload(id)
+	return nil
+}
+
+func (u *chunkUpload) info(offset int64) *ChunkUploadInfo {
+	return &ChunkUploadInfo{
+		ID:        u.ID,
+		Filename:  u.Filename,
+		Size:      u.Size,
+		Offset:    offset,
+		ExpiresAt: u.UpdatedAt.Add(chunkUploadExpire()).In(config.TimeLoc()).Format("2006-01-02 15:04:05"),
+	}
+}
+
+func saveChunkUpload(u *chunkUpload) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(chunkUploadFile(u.ID, ".json"), data, 0600)
+}
+
+// loadChunkUpload load the chunked upload of the user, it returns the upload and the size of received data
+// uploads of other users are not found, and so are the expired ones.
+func loadChunkUpload(userID uint64, id string) (*chunkUpload, int64, error) {
+	if !isChunkUploadID(id) {
+		return nil, 0, errno.ErrUploadNotFound
+	}
+
+	// the upload may be removed by the cleaner after it was locked
+	data, err := ioutil.ReadFile(chunkUploadFile(id, ".json"))
+	if os.IsNotExist(err) {
+		chunkUploadLocks.Delete(id)
+		return nil, 0, errno.ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, 0, errno.New(errno.ErrUploadFile, err)
+	}
+
+	u := &chunkUpload{}
+	if err := json.Unmarshal(data, u); err != nil {
+		return nil, 0, errno.New(errno.ErrUploadFile, err)
+	}
+	if u.UserID != userID {
+		return nil, 0, errno.ErrUploadNotFound
+	}
+	if time.Since(u.UpdatedAt) > chunkUploadExpire() {
+		removeChunkUpload(id)
+		return nil, 0, errno.ErrUploadNotFound
+	}
+
+	info, err := os.Stat(chunkUploadFile(id, ".part"))
+	if os.IsNotExist(err) {
+		removeChunkUpload(id)
+		return nil, 0, errno.ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, 0, errno.New(errno.ErrUploadFile, err)
+	}
+
+	return u, info.Size(), nil
+}
+
+// removeChunkUpload remove the data and state of the chunked upload
+func removeChunkUpload(id string) {
+	for _, ext := range []string{".part", ".json"} {
+		if err := os.Remove(chunkUploadFile(id, ext)); err != nil && !os.IsNotExist(err) {
+			logger.Errorf("remove chunked upload %s failed. %s", id, err)
+		}
+	}
+	chunkUploadLocks.Delete(id)
+}
+
+// cleanExpiredChunkUploads remove the abandoned chunked uploads by the modification time of their files
+func cleanExpiredChunkUploads() {
+	files, err := ioutil.ReadDir(chunkUploadPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Errorf("read chunked uploads failed. %s", err)
+		}
+		return
+	}
+
+	expire := chunkUploadExpire()
+	for _, f := range files {
+		id := strings.TrimSuffix(f.Name(), filepath.Ext(f.Name()))
+		if !isChunkUploadID(id) || time.Since(f.ModTime()) <= expire {
+			continue
+		}
+		if err := os.Remove(filepath.Join(chunkUploadPath(), f.Name())); err != nil && !os.IsNotExist(err) {
+			logger.Errorf("remove expired chunked upload %s failed. %s", f.Name(), err)
+		}
+		chunkUploadLocks.Delete(id)
+	}
+}
+
+// InitChunkUploadCleanTicker init the ticker which removes the abandoned chunked uploads
+func InitChunkUploadCleanTicker() {
+	cleanTicker := time.NewTicker(ChunkUploadCleanTickerRepeatTime)
+
+	go func() {
+		cleanExpiredChunkUploads()
+		for {
+			select {
+			case <-cleanTicker.C:
+				cleanExpiredChunkUploads()
+			case <-ChunkUploadCleanTickerStopChan:
+				logger.Info("chunked upload clean ticker will be stop")
+				cleanTicker.Stop()
+				logger.Info("chunked upload clean ticker stopped")
+				return
+			}
+		}
+	}()
+
+	logger.Info("start to running the chunked upload clean ticker")
+}
+
+// StopChunkUploadCleanTicker stop the chunked upload clean ticker
+func StopChunkUploadCleanTicker() {
+	ChunkUploadCleanTickerStopChan <- true
+}
@@ -40,6 +40,7 @@ var OptionSettingTypeMap = []string{
 	"media",
 	"reading",
 	"writing",
+	"markdown",
 }
 
 // OptionNamesMap option keys for those setting type
@@ -50,6 +51,7 @@ var OptionNamesMap = map[string][]string{
 		"site_url",
 		"admin_email",
 		"users_can_register",
+		"default_role",
 		"timezone_string",
 		"site_language",
 	},
@@ -87,13 +89,26 @@ var OptionNamesMap = map[string][]string{
 		"image_medium_height",
 		"image_large_width",
 		"image_large_height",
+		"image_strip_location",
 	},
 	"reading": {
 		"open_XML",
+		"robots_txt",
 	},
 	"writing": {
 		"default_category",
 		"default_link_category",
+		"revisions_to_keep",
+	},
+	"markdown": {
+		"markdown_gfm_table",
+		"markdown_footnotes",
+		"markdown_toc",
+		"markdown_emoji",
+		"markdown_math",
+		"markdown_mermaid",
+		"markdown_heading_anchor",
+		"markdown_code_style",
 	},
 }
 
@@ -122,6 +137,9 @@ func (svc Service) ListOption(settingType string) (*OptionListResponse, error) {
 	if "theme" == settingType {
 		rsp.ExtraData = generalThemeExtra()
 	}
+	if "markdown" == settingType {
+		rsp.ExtraData = ListCodeStyles()
+	}
 
 	return rsp, nil
 }
@@ -165,15 +183,34 @@ func (svc Service) GetOptionsByType(settingType string) (map[string]string, erro
 
 // UpdateOptions update options
 func (svc Service) UpdateOptions(options map[string]interface{}) error {
+	if style, ok := options[codeStyleOption]; ok {
+		if err := checkCodeStyle(fmt.Sprintf("%v", style)); err != nil {
+			return err
+		}
+	}
+
 	// update options
 	if err := svc.dao.UpdateOptions(options); err != nil {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
 	// update options cache
+	markdownChanged := false
 	for optionName, optionValue := range options {
 		cache.Options.Put(optionName, fmt.Sprintf("%v", optionValue))
+		if isMarkdownOption(optionName) && optionName != codeStyleOption {
+			markdownChanged = true
+		}
+	}
+
+	// content rendered by the old markdown options is rendered again
+	if markdownChanged {
+		svc.updateMarkdownOptions()
 	}
 
+	// site url, blog name and posts per page are used in feed and sitemap
+	svc.CleanCacheFeed()
+	svc.CleanCacheSitemap()
+
 	return nil
 }
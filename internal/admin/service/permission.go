@@ -0,0 +1,51 @@
+package service
+
+import (
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
+)
+
+// CheckPostPermission check if the user can edit or delete the post
+// users can only handle their own posts unless the role can edit posts of others;
+// a published post can not be changed by the role which can not publish posts
+func (svc Service) CheckPostPermission(postID, userID uint64, role string) error {
+	post, err := svc.dao.GetPostByID(postID)
+	if err != nil {
+		return errno.New(errno.ErrArticleNotFount, err)
+	}
+
+	if post.UserID != userID && !rbac.Can(role, rbac.CapEditOthersPosts) {
+		return errno.ErrPermissionDenied
+	}
+
+	if (post.Status == model.PostStatusPublish || post.Status == model.PostStatusScheduled) && !rbac.Can(role, rbac.CapPublishPosts) {
+		return errno.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// CheckPublishPermission check if the user can save the post as the status
+func (svc Service) CheckPublishPermission(status, role string) error {
+	if (status == model.PostStatusPublish || status == model.PostStatusScheduled) && !rbac.Can(role, rbac.CapPublishPosts) {
+		return errno.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// CheckMediaPermission check if the user can edit or delete the media
+// users can only handle media uploaded by themselves unless the role can edit media of others
+func (svc Service) CheckMediaPermission(mediaID, userID uint64, role string) error {
+	media, err := svc.dao.GetMediaByID(mediaID)
+	if err != nil {
+		return errno.New(errno.ErrMediaNotFound, err)
+	}
+
+	if media.UserID != userID && !rbac.Can(role, rbac.CapEditOthersMedia) {
+		return errno.ErrPermissionDenied
+	}
+
+	return nil
+}
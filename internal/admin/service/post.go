@@ -10,6 +10,7 @@ import (
 
 	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/markdown"
 	"github.com/puti-projects/puti/internal/utils"
 )
 
@@ -18,7 +19,6 @@ type ArticleCreateRequest struct {
 	Status        string   `json:"status"`
 	Title         string   `json:"title"`
 	Content       string   `json:"content"`
-	ContentHTML   string   `json:"content_html"`
 	Description   string   `json:"description"`
 	CommentStatus uint64   `json:"comment_status"`
 	CoverPicture  string   `json:"cover_picture"`
@@ -40,7 +40,6 @@ type PageCreateRequest struct {
 	Status        string `json:"status"`
 	Title         string `json:"title"`
 	Content       string `json:"content"`
-	ContentHTML   string `json:"content_html"`
 	Description   string `json:"description"`
 	CommentStatus uint64 `json:"comment_status"`
 	CoverPicture  string `json:"cover_picture"`
@@ -62,7 +61,6 @@ type ArticleUpdateRequest struct {
 	Status        string   `json:"status"`
 	Title         string   `json:"title"`
 	Content       string   `json:"content"`
-	ContentHTML   string   `json:"content_html"`
 	Description   string   `json:"description"`
 	CommentStatus uint64   `json:"comment_status"`
 	CoverPicture  string   `json:"cover_picture"`
@@ -79,7 +77,6 @@ type PageUpdateRequest struct {
 	Status        string `json:"status"`
 	Title         string `json:"title"`
 	Content       string `json:"content"`
-	ContentHTML   string `json:"content_html"`
 	Description   string `json:"description"`
 	CommentStatus uint64 `json:"comment_status"`
 	CoverPicture  string `json:"cover_picture"`
@@ -178,7 +175,7 @@ func (svc Service) CreateArticle(r *ArticleCreateRequest, userID uint64) (*Artic
 		PostType:        model.PostTypeArticle,
 		Title:           r.Title,
 		ContentMarkdown: r.Content,
-		ContentHTML:     r.ContentHTML,
+		ContentHTML:     markdown.Markdown2HTML(r.Title, r.Content),
 		ParentID:        0,
 		Status:          r.Status,
 		CommentStatus:   r.CommentStatus,
@@ -187,10 +184,10 @@ func (svc Service) CreateArticle(r *ArticleCreateRequest, userID uint64) (*Artic
 		CommentCount:    0,
 		ViewCount:       0,
 	}
-	if r.PostedTime == "" && r.Status == model.PostStatusPublish {
+	article.PostDate = utils.StringToNullTime("2006-01-02 15:04:05", r.PostedTime)
+	article.Status = postStatus(r.Status, article.PostDate)
+	if article.PostDate.Valid == false && article.Status == model.PostStatusPublish {
 		article.PostDate = sql.NullTime{Time: time.Now(), Valid: true}
-	} else {
-		article.PostDate = utils.StringToNullTime("2006-01-02 15:04:05", r.PostedTime)
 	}
 
 	// post meta data
@@ -206,6 +203,11 @@ func (svc Service) CreateArticle(r *ArticleCreateRequest, userID uint64) (*Artic
 		return nil, errno.New(errno.ErrDatabase, err)
 	}
 
+	if article.Status == model.PostStatusPublish {
+		svc.CleanCacheFeed()
+		svc.CleanCacheSitemap()
+	}
+
 	rsp := &ArticleCreateResponse{
 		ID:   article.ID,
 		GUID: article.GUID,
@@ -221,7 +223,7 @@ func (svc Service) CreatePage(r *PageCreateRequest, userID uint64) (*PageCreateR
 		PostType:        model.PostTypePage,
 		Title:           r.Title,
 		ContentMarkdown: r.Content,
-		ContentHTML:     r.ContentHTML,
+		ContentHTML:     markdown.Markdown2HTML(r.Title, r.Content),
 		Slug:            r.Slug,
 		ParentID:        r.ParentID,
 		Status:          r.Status,
@@ -232,10 +234,10 @@ func (svc Service) CreatePage(r *PageCreateRequest, userID uint64) (*PageCreateR
 		CommentCount:    0,
 		ViewCount:       0,
 	}
-	if r.PostedTime == "" && r.Status == model.PostStatusPublish {
+	page.PostDate = utils.StringToNullTime("2006-01-02 15:04:05", r.PostedTime)
+	page.Status = postStatus(r.Status, page.PostDate)
+	if page.PostDate.Valid == false && page.Status == model.PostStatusPublish {
 		page.PostDate = sql.NullTime{Time: time.Now(), Valid: true}
-	} else {
-		page.PostDate = utils.StringToNullTime("2006-01-02 15:04:05", r.PostedTime)
 	}
 
 	// set metadata description
@@ -257,6 +259,10 @@ func (svc Service) CreatePage(r *PageCreateRequest, userID uint64) (*PageCreateR
 		return nil, errno.New(errno.ErrDatabase, err)
 	}
 
+	if page.Status == model.PostStatusPublish {
+		svc.CleanCacheSitemap()
+	}
+
 	rsp := &PageCreateResponse{
 		ID:   page.ID,
 		GUID: page.GUID,
@@ -428,7 +434,7 @@ func (svc Service) GetPageDetail(pageID uint64) (*PageDetail, error) {
 
 // UpdateArticle update article
 // TODO: In this version, article meta data just update description, it should be more than one choise.
-func (svc *Service) UpdateArticle(a *ArticleUpdateRequest) error {
+func (svc *Service) UpdateArticle(a *ArticleUpdateRequest, userID uint64) error {
 	// get old article data
 	article, err := svc.dao.GetPostByID(a.ID)
 	if err != nil {
@@ -438,29 +444,32 @@ func (svc *Service) UpdateArticle(a *ArticleUpdateRequest) error {
 	// reset article data
 	article.Title = a.Title
 	article.ContentMarkdown = a.Content
-	article.ContentHTML = a.ContentHTML
+	article.ContentHTML = markdown.Markdown2HTML(a.Title, a.Content)
 	article.Status = a.Status
 	article.CommentStatus = a.CommentStatus
 	article.IfTop = a.IfTop
 	article.CoverPicture = a.CoverPicture
 	article.PostDate = utils.StringToNullTime("2006-01-02 15:04:05", a.PostedTime)
+	article.Status = postStatus(a.Status, article.PostDate)
 	if article.PostDate.Valid == false && article.Status == model.PostStatusPublish {
 		// first publish; save as draft and  publish now in this situation
 		article.PostDate = sql.NullTime{Time: time.Now(), Valid: true}
 	}
 
-	err = svc.dao.UpdateArticle(article, a.Description, a.Category, a.Tag, a.Subject)
+	err = svc.dao.UpdateArticle(article, userID, revisionsToKeep(), a.Description, a.Category, a.Tag, a.Subject)
 	if err != nil {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
 	// update finished. clean cache.a
 	svc.CleanCacheAfterEditArticle(a.ID)
+	svc.CleanCacheFeed()
+	svc.CleanCacheSitemap()
 	return nil
 }
 
 // UpdatePage update page
-func (svc *Service) UpdatePage(p *PageUpdateRequest) (err error) {
+func (svc *Service) UpdatePage(p *PageUpdateRequest, userID uint64) (err error) {
 	// get old page data
 	page, err := svc.dao.GetPostByID(p.ID)
 	if err != nil {
@@ -469,24 +478,26 @@ func (svc *Service) UpdatePage(p *PageUpdateRequest) (err error) {
 
 	page.Title = p.Title
 	page.ContentMarkdown = p.Content
-	page.ContentHTML = p.ContentHTML
+	page.ContentHTML = markdown.Markdown2HTML(p.Title, p.Content)
 	page.Status = p.Status
 	page.CommentStatus = p.CommentStatus
 	page.CoverPicture = p.CoverPicture
 	page.PostDate = utils.StringToNullTime("2006-01-02 15:04:05", p.PostedTime)
+	page.Status = postStatus(p.Status, page.PostDate)
 	if page.Slug != p.Slug {
 		page.Slug = p.Slug
 		page.GUID = fmt.Sprintf("/%s", p.Slug)
 	}
 	page.ParentID = p.ParentID
 
-	err = svc.dao.UpdatePage(page, p.Description, p.PageTemplate)
+	err = svc.dao.UpdatePage(page, userID, revisionsToKeep(), p.Description, p.PageTemplate)
 	if err != nil {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
 	// update finished. clean cache.
 	svc.CleanCacheAfterEditPage(p.ID)
+	svc.CleanCacheSitemap()
 	return nil
 }
 
@@ -497,6 +508,9 @@ func (svc Service) DeletePost(postType string, articleID uint64) error {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	svc.CleanCacheFeed()
+	svc.CleanCacheSitemap()
+
 	return nil
 }
 
@@ -507,6 +521,9 @@ func (svc Service) TrashPost(postID uint64) error {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	svc.CleanCacheFeed()
+	svc.CleanCacheSitemap()
+
 	return nil
 }
 
@@ -517,5 +534,8 @@ func (svc Service) RestorePost(postID uint64) error {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	svc.CleanCacheFeed()
+	svc.CleanCacheSitemap()
+
 	return nil
 }
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"strconv"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/diff"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/markdown"
+)
+
+// defaultRevisionsToKeep the number of revisions kept for each post if the option revisions_to_keep is not set
+const defaultRevisionsToKeep = 50
+
+// PostRevisionInfo revision info for revision list
+type PostRevisionInfo struct {
+	ID          uint64 `json:"id"`
+	UserID      uint64 `json:"userId"`
+	Author      string `json:"author"`
+	Title       string `json:"title"`
+	CreatedTime string `json:"created_time"`
+}
+
+// PostRevisionDiffResponse line diff of markdown content between two revisions
+type PostRevisionDiffResponse struct {
+	From  *PostRevisionInfo `json:"from"`
+	To    *PostRevisionInfo `json:"to"`
+	Lines []*diff.Line      `json:"lines"`
+}
+
+// revisionsToKeep get the number of revisions kept for each post; 0 means all revisions are kept
+func revisionsToKeep() int {
+	keep, err := strconv.Atoi(cache.Options.Get("revisions_to_keep"))
+	if err != nil || keep < 0 {
+		return defaultRevisionsToKeep
+	}
+	return keep
+}
+
+// getPostOfType get the post and check if it is the type
+func (svc Service) getPostOfType(postType string, postID uint64) (*model.Post, error) {
+	notFound := errno.ErrArticleNotFount
+	if postType == model.PostTypePage {
+		notFound = errno.ErrPageNotFount
+	}
+
+	post, err := svc.dao.GetPostByID(postID)
+	if err != nil {
+		return nil, errno.New(notFound, err)
+	}
+	if post.PostType != postType {
+		return nil, notFound
+	}
+	return post, nil
+}
+
+// ListPostRevisions get revisions of the article or page from the newest
+func (svc Service) ListPostRevisions(postType string, postID uint64) ([]*PostRevisionInfo, error) {
+	if _, err := svc.getPostOfType(postType, postID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := svc.dao.ListPostRevision(postID)
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+
+	authors := make(map[uint64]string)
+	result := make([]*PostRevisionInfo, 0, len(revisions))
+	for _, revision := range revisions {
+		result = append(result, svc.newPostRevisionInfo(revision, authors))
+	}
+	return result, nil
+}
+
+// DiffPostRevisions compare the markdown content of two revisions of the article or page line by line
+func (svc Service) DiffPostRevisions(postType string, postID, fromID, toID uint64) (*PostRevisionDiffResponse, error) {
+	if _, err := svc.getPostOfType(postType, postID); err != nil {
+		return nil, err
+	}
+
+	from, err := svc.dao.GetPostRevision(postID, fromID)
+	if err != nil {
+		return nil, errno.New(errno.ErrRevisionNotFound, err)
+	}
+	to, err := svc.dao.GetPostRevision(postID, toID)
+	if err != nil {
+		return nil, errno.New(errno.ErrRevisionNotFound, err)
+	}
+
+	authors := make(map[uint64]string)
+	return &PostRevisionDiffResponse{
+		From:  svc.newPostRevisionInfo(from, authors),
+		To:    svc.newPostRevisionInfo(to, authors),
+		Lines: diff.Lines(from.ContentMarkdown, to.ContentMarkdown),
+	}, nil
+}
+
+// RestorePostRevision restore title and content of the article or page to the revision
+func (svc *Service) RestorePostRevision(postType string, postID, revisionID, userID uint64) error {
+	post, err := svc.getPostOfType(postType, postID)
+	if err != nil {
+		return err
+	}
+
+	revision, err := svc.dao.GetPostRevision(postID, revisionID)
+	if err != nil {
+		return errno.New(errno.ErrRevisionNotFound, err)
+	}
+
+	// the stored HTML may be rendered by old options, so it is rendered again
+	revision.ContentHTML = markdown.Markdown2HTML(revision.Title, revision.ContentMarkdown)
+	if err := svc.dao.RestorePostRevision(post, revision, userID, revisionsToKeep()); err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+
+	if postType == model.PostTypeArticle {
+		svc.CleanCacheAfterEditArticle(postID)
+		svc.CleanCacheFeed()
+	} else {
+		svc.CleanCacheAfterEditPage(postID)
+	}
+	svc.CleanCacheSitemap()
+	return nil
+}
+
+// newPostRevisionInfo build the revision info; nicknames of authors are cached in the map
+func (svc Service) newPostRevisionInfo(revision *model.PostRevision, authors map[uint64]string) *PostRevisionInfo {
+	author, ok := authors[revision.UserID]
+	if !ok {
+		if user, err := svc.dao.GetUserByID(revision.UserID); err == nil {
+			author = user.Nickname
+		}
+		authors[revision.UserID] = author
+	}
+
+	return &PostRevisionInfo{
+		ID:          revision.ID,
+		UserID:      revision.UserID,
+		Author:      author,
+		Title:       revision.Title,
+		CreatedTime: revision.CreatedAt.In(config.TimeLoc()).Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/markdown"
+)
+
+// renderBatchSize number of posts loaded at a time when rendering all posts
+const renderBatchSize = 100
+
+// PostRenderResponse the number of rendered posts and posts whose HTML changed
+type PostRenderResponse struct {
+	Total   int `json:"total"`
+	Changed int `json:"changed"`
+}
+
+// RenderPosts render the markdown of all articles and pages to HTML again and clean the cache of rendered content
+// It should be run after the markdown options or the sanitize allowlist are changed.
+func (svc *Service) RenderPosts() (*PostRenderResponse, error) {
+	result := &PostRenderResponse{}
+	changedArticle := false
+
+	var lastID uint64
+	for {
+		posts, err := svc.dao.ListPostContent(lastID, renderBatchSize)
+		if err != nil {
+			return result, errno.New(errno.ErrDatabase, err)
+		}
+		if len(posts) == 0 {
+			break
+		}
+
+		for _, post := range posts {
+			lastID = post.ID
+			result.Total++
+
+			contentHTML := markdown.Markdown2HTML(post.Title, post.ContentMarkdown)
+			if contentHTML == post.ContentHTML {
+				continue
+			}
+			if err := svc.dao.UpdatePostContentHTML(post.ID, contentHTML); err != nil {
+				return result, errno.New(errno.ErrDatabase, err)
+			}
+			result.Changed++
+
+			if post.PostType == model.PostTypeArticle {
+				svc.CleanCacheAfterEditArticle(post.ID)
+				changedArticle = true
+			} else {
+				svc.CleanCacheAfterEditPage(post.ID)
+			}
+		}
+	}
+	if changedArticle {
+		svc.CleanCacheFeed()
+	}
+
+	// knowledge item content is rendered when it is shown, only the cache is cleaned
+	symbols, err := svc.dao.ListKnowledgeItemSymbol()
+	if err != nil {
+		logger.Errorf("list knowledge item symbols failed. %s", err)
+	}
+	for _, symbol := range symbols {
+		svc.CleanCacheAfterUpdateKnowledgeItemContent(symbol)
+	}
+
+	return result, nil
+}
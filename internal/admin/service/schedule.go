@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+)
+
+// PublishTickerRepeatTime interval of checking the scheduled posts
+const PublishTickerRepeatTime = time.Minute
+
+// PublishTickerStopChan chan for stop the publish ticker
+var PublishTickerStopChan = make(chan bool)
+
+// postStatus get the status to save for the post
+// Published post with a future posted time is scheduled, and scheduled post whose posted time has come is published.
+func postStatus(status string, postDate sql.NullTime) string {
+	if status != model.PostStatusPublish && status != model.PostStatusScheduled {
+		return status
+	}
+	if postDate.Valid && postDate.Time.After(time.Now()) {
+		return model.PostStatusScheduled
+	}
+	return model.PostStatusPublish
+}
+
+// PublishScheduledPosts publish the scheduled posts whose posted time has come
+func (svc *Service) PublishScheduledPosts() (int, error) {
+	posts, err := svc.dao.ListScheduledPost(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, post := range posts {
+		ok, err := svc.dao.PublishScheduledPost(post)
+		if err != nil {
+			logger.Errorf("publish scheduled post %d failed. %s", post.ID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		published++
+		if post.PostType == model.PostTypeArticle {
+			svc.CleanCacheAfterEditArticle(post.ID)
+		} else {
+			svc.CleanCacheAfterEditPage(post.ID)
+		}
+	}
+
+	if published != 0 {
+		svc.CleanCacheFeed()
+		svc.CleanCacheSitemap()
+	}
+	return published, nil
+}
+
+// InitPublishTicker init the ticker which publishes the scheduled posts
+// The scheduled posts are stored in database, so posts whose posted time passed during the downtime are published at once.
+func InitPublishTicker() {
+	publishTicker := time.NewTicker(PublishTickerRepeatTime)
+	svc := New(context.Background())
+
+	publish := func() {
+		if n, err := svc.PublishScheduledPosts(); err != nil {
+			logger.Errorf("ticker: publish scheduled posts failed. %s", err)
+		} else if n != 0 {
+			logger.Infof("ticker: %d scheduled posts were published", n)
+		}
+	}
+
+	go func() {
+		publish()
+		for {
+			select {
+			case <-publishTicker.C:
+				publish()
+			case <-PublishTickerStopChan:
+				logger.Info("publish ticker will be stop")
+				publishTicker.Stop()
+				logger.Info("publish ticker stopped")
+				return
+			}
+		}
+	}()
+
+	logger.Info("start to running the publish ticker")
+}
+
+// StopPublishTicker stop the publish ticker
+func StopPublishTicker() {
+	PublishTickerStopChan <- true
+}
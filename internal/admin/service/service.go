@@ -3,8 +3,10 @@ package service
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/puti-projects/puti/internal/admin/dao"
+	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/cache"
 	"github.com/puti-projects/puti/internal/pkg/config"
 	"github.com/puti-projects/puti/internal/pkg/db"
@@ -46,6 +48,25 @@ func (svc *Service) CleanCacheAfterEditPage(pageID uint64) {
 	svc.DeleteCache(config.CachePageDetailPrefix + strconv.Itoa(int(pageID)))
 }
 
+// CleanCacheFeed expire all feed cache by changing the feed cache version
+// feed is built by article list of site, category, tag and subject, so they are cleaned together
+func (svc *Service) CleanCacheFeed() {
+	svc.changeCacheVersion(config.CacheFeedVersionKey)
+}
+
+// CleanCacheSitemap expire the sitemap and all its parts by changing the sitemap cache version
+func (svc *Service) CleanCacheSitemap() {
+	svc.changeCacheVersion(config.CacheSitemapVersionKey)
+}
+
+// changeCacheVersion set a new version for the cache whose key contains the version
+func (svc *Service) changeCacheVersion(versionKey string) {
+	version := strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := svc.cache.SetCache(versionKey, version); err != nil {
+		logger.Errorf("error setting cache version. %s", err)
+	}
+}
+
 // CleanCacheAfterUpdateKnowledge clean cache after update knowledge info
 func (svc *Service) CleanCacheAfterUpdateKnowledge(slug string) {
 	// knowledge info cache
@@ -63,3 +84,12 @@ func (svc *Service) CleanCacheAfterUpdateKnowledgeItemContent(kiSymbol uint64) {
 	// knowledge item content cache by item symbol
 	svc.DeleteCache(config.CacheKnowledgeItemContentPrefix + strconv.Itoa(int(kiSymbol)))
 }
+
+// CleanCacheImageSize clean cache of image size urls, dimensions and variants of the media
+func (svc *Service) CleanCacheImageSize(guid string) {
+	for _, size := range model.ImageSizes {
+		svc.DeleteCache(config.CacheImageSizePrefix + size + "_" + guid)
+	}
+	svc.DeleteCache(config.CacheContentImagePrefix + guid)
+	svc.DeleteCache(config.CacheImageVariantPrefix + guid)
+}
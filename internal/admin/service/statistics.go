@@ -13,7 +13,7 @@ type DashboardData struct {
 func (svc Service) GetDashboardStatisticsData() (*DashboardData, error) {
 	dashboardData := &DashboardData{
 		TotalViews:    svc.dao.GetPostTotalView(),
-		TotalComments: 0, // TODO comment Features
+		TotalComments: svc.dao.GetTotalComments(),
 		TotalArticles: svc.dao.GetTotalArticles(),
 		TotalMedia:    svc.dao.GetTotalMedia(),
 	}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/storage"
 	"github.com/puti-projects/puti/internal/utils"
 
 	"gorm.io/gorm"
@@ -67,6 +68,8 @@ func (svc Service) CreateSubject(r *SubjectCreateRequest) error {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	svc.CleanCacheSitemap()
+
 	return nil
 }
 
@@ -129,7 +132,7 @@ func (svc Service) GetSubjectInfo(subjectID uint64) (*SubjectDetail, error) {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			coverImageStatus = "关联封面图不存在，可能已被删除。"
 		}
-		coverImageURL = m.GUID
+		coverImageURL = storage.URL(m.GUID)
 	}
 
 	subjectInfo := &SubjectDetail{
@@ -162,6 +165,9 @@ func (svc Service) UpdateSubject(r *SubjectUpdateRequest) error {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	svc.CleanCacheFeed()
+	svc.CleanCacheSitemap()
+
 	return nil
 }
 
@@ -200,5 +206,8 @@ func (svc Service) DeleteSubject(subjectID uint64) error {
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	svc.CleanCacheFeed()
+	svc.CleanCacheSitemap()
+
 	return nil
 }
@@ -153,6 +153,9 @@ func (svc Service) UpdateTaxonomy(r *TaxonomyUpdateRequest, termID uint64) error
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	svc.CleanCacheFeed()
+	svc.CleanCacheSitemap()
+
 	return nil
 }
 
@@ -177,5 +180,11 @@ func (svc Service) CheckTaxonomyNameExist(name, taxonomy string) bool {
 
 // DeleteTaxonomy delete term directly
 func (svc Service) DeleteTaxonomy(termID uint64, taxonomyType string) error {
-	return svc.dao.DeleteTaxonomy(termID, taxonomyType)
+	if err := svc.dao.DeleteTaxonomy(termID, taxonomyType); err != nil {
+		return err
+	}
+
+	svc.CleanCacheFeed()
+	svc.CleanCacheSitemap()
+	return nil
 }
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/token"
+	"github.com/puti-projects/puti/internal/pkg/totp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorStatusResponse two-factor authentication status of user
+type TwoFactorStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollResponse the new TOTP secret and its otpauth URI for authenticator apps
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorCodeRequest request params with TOTP code or recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorRecoveryCodesResponse recovery codes, they are only shown once
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorLoginRequest the second login step request params
+type TwoFactorLoginRequest struct {
+	TwoFactorToken string `json:"two_factor_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+const recoveryCodeNumber = 10
+
+// maxTwoFactorLoginAttempts codes can be tried with one first login step, then the user must login again
+const maxTwoFactorLoginAttempts = 5
+
+// GetTwoFactorStatus get two-factor authentication status of user
+func (svc Service) GetTwoFactorStatus(userID uint64) (*TwoFactorStatusResponse, error) {
+	secret, err := svc.dao.GetUserMeta(userID, model.UserMetaTOTPSecret)
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	if secret == "" {
+		return &TwoFactorStatusResponse{}, nil
+	}
+
+	hashes, err := svc.getRecoveryCodeHashes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorStatusResponse{Enabled: true, RecoveryCodesLeft: len(hashes)}, nil
+}
+
+// EnrollTwoFactor generate a new TOTP secret for user
+// the secret is pending until it is activated by a code from the authenticator
+func (svc Service) EnrollTwoFactor(userID uint64, username string) (*TwoFactorEnrollResponse, error) {
+	status, err := svc.GetTwoFactorStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+	if status.Enabled {
+		return nil, errno.ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errno.New(errno.InternalServerError, err)
+	}
+	if err := svc.dao.SetUserMeta(userID, model.UserMetaTOTPPendingSecret, secret); err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+
+	issuer := cache.Options.Get("blog_name")
+	if issuer == "" {
+		issuer = "Puti"
+	}
+	return &TwoFactorEnrollResponse{Secret: secret, URI: totp.URI(issuer, username, secret)}, nil
+}
+
+// ActivateTwoFactor enable two-factor authentication after the code of pending secret verified
+// it returns the recovery codes
+func (svc Service) ActivateTwoFactor(userID uint64, code string) (*TwoFactorRecoveryCodesResponse, error) {
+	secret, err := svc.dao.GetUserMeta(userID, model.UserMetaTOTPPendingSecret)
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	if secret == "" {
+		return nil, errno.ErrTwoFactorNotEnabled
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), 0)
+	if !ok {
+		return nil, errno.ErrTwoFactorCode
+	}
+
+	if err := svc.dao.SetUserMeta(userID, model.UserMetaTOTPSecret, secret); err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	if err := svc.dao.SetUserMeta(userID, model.UserMetaTOTPLastStep, strconv.FormatInt(step, 10)); err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	if err := svc.dao.DeleteUserMeta(userID, model.UserMetaTOTPPendingSecret); err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+
+	return svc.resetRecoveryCodes(userID)
+}
+
+// DisableTwoFactor disable two-factor authentication after the code verified
+func (svc Service) DisableTwoFactor(userID uint64, code string) error {
+	if err := svc.verifyTwoFactorCode(userID, code); err != nil {
+		return err
+	}
+
+	return svc.ResetTwoFactor(userID)
+}
+
+// ResetTwoFactor remove two-factor authentication of user without code, use for the user who lost the authenticator
+func (svc Service) ResetTwoFactor(userID uint64) error {
+	err := svc.dao.DeleteUserMeta(userID,
+		model.UserMetaTOTPSecret,
+		model.UserMetaTOTPPendingSecret,
+		model.UserMetaTOTPLastStep,
+		model.UserMetaTOTPRecoveryCodes,
+		model.UserMetaTOTPLoginAttempts,
+	)
+	if err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes generate new recovery codes after the code verified, the old codes are invalid
+func (svc Service) RegenerateRecoveryCodes(userID uint64, code string) (*TwoFactorRecoveryCodesResponse, error) {
+	if err := svc.verifyTwoFactorCode(userID, code); err != nil {
+		return nil, err
+	}
+
+	return svc.resetRecoveryCodes(userID)
+}
+
+// LoginTwoFactor the second login step, it issues the token pair after the code verified
+func (svc Service) LoginTwoFactor(c *gin.Context, r *TwoFactorLoginRequest) (*Token, error) {
+	ctx, err := token.ParseTwoFactorToken(r.TwoFactorToken)
+	if err != nil {
+		return nil, errno.New(errno.ErrTokenInvalid, err)
+	}
+
+	u, err := svc.GetEnabledUserByID(ctx.ID)
+	if err != nil {
+		return nil, errno.New(errno.ErrTokenInvalid, err)
+	}
+
+	// attempts are counted before the code is verified, so concurrent requests can not exceed the limit
+	ok, err := svc.dao.IncrUserMetaBelow(u.ID, model.UserMetaTOTPLoginAttempts, maxTwoFactorLoginAttempts)
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	if !ok {
+		token.Revoke(ctx)
+		return nil, errno.ErrTwoFactorAttempts
+	}
+
+	if err := svc.verifyTwoFactorCode(u.ID, r.Code); err != nil {
+		return nil, err
+	}
+
+	// the first step token can only be used once
+	token.Revoke(ctx)
+	if err := svc.dao.DeleteUserMeta(u.ID, model.UserMetaTOTPLoginAttempts); err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+	return signTokenPair(c, u)
+}
+
+// verifyTwoFactorCode verify the TOTP code or one of the recovery codes
+// the used code can not be used again
+func (svc Service) verifyTwoFactorCode(userID uint64, code string) error {
+	secret, err := svc.dao.GetUserMeta(userID, model.UserMetaTOTPSecret)
+	if err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+	if secret == "" {
+		return errno.ErrTwoFactorNotEnabled
+	}
+
+	lastStepValue, err := svc.dao.GetUserMeta(userID, model.UserMetaTOTPLastStep)
+	if err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+	lastStep, _ := strconv.ParseInt(lastStepValue, 10, 64)
+
+	if step, ok := totp.Validate(secret, code, time.Now(), lastStep); ok {
+		if err := svc.dao.SetUserMeta(userID, model.UserMetaTOTPLastStep, strconv.FormatInt(step, 10)); err != nil {
+			return errno.New(errno.ErrDatabase, err)
+		}
+		return nil
+	}
+
+	// try recovery codes
+	hashes, err := svc.getRecoveryCodeHashes(userID)
+	if err != nil {
+		return err
+	}
+	hash := totp.HashRecoveryCode(code)
+	for i, h := range hashes {
+		if h == hash {
+			return svc.setRecoveryCodeHashes(userID, append(hashes[:i], hashes[i+1:]...))
+		}
+	}
+
+	return errno.ErrTwoFactorCode
+}
+
+// resetRecoveryCodes generate and save new recovery codes
+func (svc Service) resetRecoveryCodes(userID uint64) (*TwoFactorRecoveryCodesResponse, error) {
+	codes, err := totp.GenerateRecoveryCodes(recoveryCodeNumber)
+	if err != nil {
+		return nil, errno.New(errno.InternalServerError, err)
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, totp.HashRecoveryCode(code))
+	}
+	if err := svc.setRecoveryCodeHashes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (svc Service) getRecoveryCodeHashes(userID uint64) ([]string, error) {
+	value, err := svc.dao.GetUserMeta(userID, model.UserMetaTOTPRecoveryCodes)
+	if err != nil {
+		return nil, errno.New(errno.ErrDatabase, err)
+	}
+
+	hashes := []string{}
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &hashes); err != nil {
+			return nil, errno.New(errno.InternalServerError, err)
+		}
+	}
+	return hashes, nil
+}
+
+func (svc Service) setRecoveryCodeHashes(userID uint64, hashes []string) error {
+	value, err := json.Marshal(hashes)
+	if err != nil {
+		return errno.New(errno.InternalServerError, err)
+	}
+
+	if err := svc.dao.SetUserMeta(userID, model.UserMetaTOTPRecoveryCodes, string(value)); err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+	return nil
+}
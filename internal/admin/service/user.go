@@ -8,7 +8,6 @@ import (
 	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/config"
 	"github.com/puti-projects/puti/internal/pkg/errno"
-	"github.com/puti-projects/puti/internal/pkg/token"
 	"github.com/puti-projects/puti/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -82,19 +81,18 @@ func (svc Service) GetUser(username string) (*UserInfo, error) {
 	return userInfo, nil
 }
 
-// GetUserByToken get userInfo by token(JWT)
-func (svc Service) GetUserByToken(t string) (*UserInfo, error) {
-	userContext, err := token.ParseToken(t)
+// GetEnabledUserByID get the user by ID, the user must be enabled
+func (svc Service) GetEnabledUserByID(userID uint64) (*model.User, error) {
+	u, err := svc.dao.GetUserByID(userID)
 	if err != nil {
-		return nil, err
+		return nil, errno.New(errno.ErrUserNotFound, err)
 	}
 
-	userInfo, err := svc.GetUser(userContext.Username)
-	if err != nil {
-		return nil, err
+	if u.Status != 1 {
+		return nil, errno.ErrUserNotFound
 	}
 
-	return userInfo, nil
+	return u, nil
 }
 
 // UserList user list handle struct
@@ -223,6 +221,11 @@ func (svc Service) UpdateUserStatus(u *UserUpdateStatusRequest, userID int) erro
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	// sessions of the user are killed after the status changed
+	if err := svc.RevokeUserTokens(uint64(userID)); err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+
 	return nil
 }
 
@@ -233,6 +236,11 @@ func (svc Service) UpdateUserPassword(u *UserUpdatePasswordRequest, userID int)
 		return errno.New(errno.ErrDatabase, err)
 	}
 
+	// sessions of the user are killed after the password changed
+	if err := svc.RevokeUserTokens(uint64(userID)); err != nil {
+		return errno.New(errno.ErrDatabase, err)
+	}
+
 	return nil
 }
 
@@ -241,10 +249,9 @@ func (svc Service) UpdateUserAvatar(c *gin.Context, userID string, file *multipa
 	fileExt := utils.GetFileExt(file)
 	newFileName := "user_" + userID + fileExt
 
-	// Upload the file to specific dst.
+	// Upload the file to the storage.
 	pathName := config.UploadUserAvatarPath + newFileName
-	dst := "." + pathName
-	if err := c.SaveUploadedFile(file, dst); err != nil {
+	if err := saveUploadedFile(newUploadFile(file), pathName, utils.GetFileMimeTypeByExt(fileExt)); err != nil {
 		return errno.New(errno.ErrSaveAvatar, err)
 	}
 
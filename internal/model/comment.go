@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment comment model of article and page
+type Comment struct {
+	Model
+
+	ParentID        uint64    `gorm:"column:parent_id;not null"`
+	PostID          uint64    `gorm:"column:post_id;not null"`
+	Content         string    `gorm:"column:content;not null"`
+	IfVisitor       uint64    `gorm:"column:if_visitor;not null;default:1"`
+	CommenterUserID uint64    `gorm:"column:commenter_user_id;not null"`
+	CommenterName   string    `gorm:"column:commenter_name;not null"`
+	CommenterEmail  string    `gorm:"column:commenter_email;not null"`
+	CommenterURL    string    `gorm:"column:commenter_url;not null"`
+	CommenterIP     string    `gorm:"column:commenter_ip;not null"`
+	CommentDate     time.Time `gorm:"column:comment_date;not null"`
+	Approved        string    `gorm:"column:approved;not null;default:1"`
+	Agent           string    `gorm:"column:agent;not null"`
+}
+
+const (
+	// CommentStatusApproved comment is approved and shown
+	CommentStatusApproved = "1"
+	// CommentStatusPending comment is waiting for moderation
+	CommentStatusPending = "0"
+	// CommentStatusSpam comment was marked as spam
+	CommentStatusSpam = "spam"
+	// CommentStatusTrash comment was put into the trash
+	CommentStatusTrash = "trash"
+)
+
+// TableName is the comment table name in db
+func (c *Comment) TableName() string {
+	return "pt_comment"
+}
+
+// Create creates a comment
+func (c *Comment) Create(db *gorm.DB) error {
+	return db.Create(c).Error
+}
+
+// Save update a comment
+func (c *Comment) Save(db *gorm.DB) error {
+	return db.Save(c).Error
+}
+
+// Delete delete a comment by ID
+func (c *Comment) Delete(db *gorm.DB) error {
+	return db.Delete(c).Error
+}
+
+// HardDelete delete a comment by ID without soft delete
+func (c *Comment) HardDelete(db *gorm.DB) error {
+	return db.Unscoped().Delete(c).Error
+}
+
+// GetByID get comment by ID
+func (c *Comment) GetByID(db *gorm.DB) error {
+	return db.Where("`deleted_time` is null").First(c, c.ID).Error
+}
+
+// Count count comment
+func (c *Comment) Count(db *gorm.DB, where string, whereArgs []interface{}) (int64, error) {
+	var count int64
+	err := db.Model(c).Where(where, whereArgs...).Count(&count).Error
+	return count, err
+}
+
+// List get comment list
+func (c *Comment) List(db *gorm.DB, where string, whereArgs []interface{}, offset, number int, order string) ([]*Comment, error) {
+	comments := make([]*Comment, 0)
+	err := db.Where(where, whereArgs...).Offset(offset).Limit(number).Order(order).Find(&comments).Error
+	return comments, err
+}
+
+// TotalNumber count total number of approved comments
+func (c *Comment) TotalNumber(db *gorm.DB) (totalComment int64, err error) {
+	err = db.Model(c).
+		Where("`approved` = ? AND `deleted_time` is null", CommentStatusApproved).
+		Count(&totalComment).Error
+	return
+}
+
+// SyncPostCommentCount recount approved comments of the post and save it into pt_post.comment_count
+func (c *Comment) SyncPostCommentCount(db *gorm.DB, postID uint64) error {
+	count := db.Model(&Comment{}).Select("count(*)").
+		Where("`post_id` = ? AND `approved` = ? AND `deleted_time` is null", postID, CommentStatusApproved)
+	return db.Model(&Post{}).Where("`id` = ?", postID).UpdateColumn("comment_count", count).Error
+}
@@ -21,8 +21,21 @@ type Media struct {
 	Status      uint64 `gorm:"column:status;not null;default:1"`
 }
 
-// ResourceTypePicture resource type of picture
-const ResourceTypePicture = "picture"
+// resource types by mime-type, see utils.GetFileType
+const (
+	// ResourceTypePicture resource type of picture
+	ResourceTypePicture = "picture"
+	// ResourceTypeAudio resource type of audio, such as mp3 and ogg
+	ResourceTypeAudio = "audio"
+	// ResourceTypeVideo resource type of video, such as mp4 and webm
+	ResourceTypeVideo = "video"
+	// ResourceTypeDocument resource type of document, such as pdf
+	ResourceTypeDocument = "document"
+	// ResourceTypeText resource type of plain text
+	ResourceTypeText = "text"
+	// ResourceTypeOthers resource type of files which are not in the types above
+	ResourceTypeOthers = "others"
+)
 
 // StatusNormal normal status value
 const StatusNormal = 1
@@ -60,7 +73,7 @@ func (m *Media) GetByID(db *gorm.DB) error {
 	return db.Where("`status` = 1 AND `deleted_time` is null AND `id` = ?", m.ID).First(m).Error
 }
 
-// Delete delete the media info by id (not file right now)
+// Delete delete the media info by id, the file is removed by service
 func (m *Media) Delete(db *gorm.DB) error {
 	return db.Delete(m).Error
 }
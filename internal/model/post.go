@@ -17,7 +17,7 @@ type Post struct {
 	ContentMarkdown string       `gorm:"column:content_markdown;not null"`
 	ContentHTML     string       `gorm:"column:content_html;not null"`
 	Slug            string       `gorm:"column:slug;not null"`
-	ParentID        uint64       `gorm:"column:parent_id;not null"` // set to 0 now, use for draft history feature in the future
+	ParentID        uint64       `gorm:"column:parent_id;not null"` // parent page, set to 0 for article; history of content is kept in PostRevision
 	Status          string       `gorm:"column:status;not null;default:publish"`
 	CommentStatus   uint64       `gorm:"column:comment_status;not null;default:1"`
 	IfTop           uint64       `gorm:"column:if_top;not null"`
@@ -43,6 +43,8 @@ const (
 	PostTypePage = "page"
 	// PostStatusPublish post status of published post
 	PostStatusPublish = "publish"
+	// PostStatusScheduled post status of post which will be published at the posted time
+	PostStatusScheduled = "scheduled"
 	// PostStatusDraft post status of draft post
 	PostStatusDraft = "draft"
 	// PostStatusDeleted post status of deleted post
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostRevision a saved version of post content
+type PostRevision struct {
+	ID              uint64    `gorm:"primaryKey;autoIncrement;column:id"`
+	PostID          uint64    `gorm:"column:post_id;not null"`
+	UserID          uint64    `gorm:"column:user_id;not null"`
+	Title           string    `gorm:"column:title;not null"`
+	ContentMarkdown string    `gorm:"column:content_markdown;not null"`
+	ContentHTML     string    `gorm:"column:content_html;not null"`
+	CreatedAt       time.Time `gorm:"column:created_time;not null"`
+}
+
+// TableName is the post revision table name in db
+func (pr *PostRevision) TableName() string {
+	return "pt_post_revision"
+}
+
+// Create save a new revision
+func (pr *PostRevision) Create(db *gorm.DB) error {
+	return db.Create(pr).Error
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// ResourceMeta meta data for resource
+type ResourceMeta struct {
+	ID         uint64 `gorm:"primaryKey;autoIncrement;column:meta_id"`
+	ResourceID uint64 `gorm:"column:resource_id;not null"`
+	MetaKey    string `gorm:"column:meta_key;not null"`
+	MetaValue  string `gorm:"column:meta_value;not null"`
+}
+
+// sizes of image derivatives, their max width and height are set by options image_{size}_width and image_{size}_height
+const (
+	// ImageSizeThumbnail thumbnail which is cropped to the exact size
+	ImageSizeThumbnail = "thumbnail"
+	// ImageSizeMedium medium size which is scaled to fit in the size
+	ImageSizeMedium = "medium"
+	// ImageSizeLarge large size which is scaled to fit in the size
+	ImageSizeLarge = "large"
+	// ImageSizeFull the original image
+	ImageSizeFull = "full"
+	// ImageSizePoster poster frame of video or preview of the first page of document, which is generated by an external command
+	ImageSizePoster = "poster"
+)
+
+// ImageSizes sizes of image derivatives from the smallest to the largest
+var ImageSizes = []string{ImageSizeThumbnail, ImageSizeMedium, ImageSizeLarge}
+
+// ResourceMetaImageSizePrefix meta key prefix of image size, the key is followed by the size name
+// and the value is ImageSizeMeta in JSON
+const ResourceMetaImageSizePrefix = "image_size_"
+
+// ResourceMetaContentHashPrefix meta key prefix of content hash, the key is followed by the sha256 of the file content
+// so that media can be found by the hash with the index of meta key
+const ResourceMetaContentHashPrefix = "content_hash:"
+
+// ResourceMetaFolder meta key of the virtual folder of media, the value is the folder name
+// nested folders are separated by "/", such as "blog/2020"
+const ResourceMetaFolder = "folder"
+
+// ResourceMetaExif meta key of the EXIF of image, the value is ExifMeta in JSON
+const ResourceMetaExif = "exif"
+
+// ExifMeta EXIF info of image which is read on upload
+type ExifMeta struct {
+	Camera string `json:"camera,omitempty"`
+	// TakenAt the time the photo was taken in the camera's local time, formatted as 2006-01-02 15:04:05
+	TakenAt string `json:"taken_at,omitempty"`
+	// Width and Height dimensions of the saved image, after it is rotated by the orientation
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Orientation the orientation tag of the uploaded image, the saved image is always upright
+	Orientation int `json:"orientation"`
+	// LocationStripped whether the GPS info was removed from the saved image
+	LocationStripped bool `json:"location_stripped"`
+}
+
+// ResourceMetaMediaInfo meta key of the info of audio, video and document, the value is MediaInfoMeta in JSON
+const ResourceMetaMediaInfo = "media_info"
+
+// MediaInfoMeta info of audio, video and document which is read on upload; fields which can not be read are omitted
+type MediaInfoMeta struct {
+	// Duration in seconds of audio and video
+	Duration float64 `json:"duration,omitempty"`
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	// Pages page count of document
+	Pages int `json:"pages,omitempty"`
+}
+
+// ImageSizeMeta path and dimensions of an image size
+type ImageSizeMeta struct {
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// TableName is the resource meta table name in db
+func (rm *ResourceMeta) TableName() string {
+	return "pt_resource_meta"
+}
+
+// Create creates a resource meta
+func (rm *ResourceMeta) Create(db *gorm.DB) error {
+	return db.Create(rm).Error
+}
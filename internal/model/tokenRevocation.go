@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// TokenRevocation a token which was revoked before it expired
+type TokenRevocation struct {
+	TokenID   string    `gorm:"primaryKey;column:token_id"`
+	ExpiresAt time.Time `gorm:"column:expires_time;not null"`
+}
+
+// TableName is the token revocation table name in db
+func (tr *TokenRevocation) TableName() string {
+	return "pt_token_revocation"
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// UserMeta meta data for user
+type UserMeta struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID    uint64 `gorm:"column:user_id;not null"`
+	MetaKey   string `gorm:"column:meta_key;not null"`
+	MetaValue string `gorm:"column:meta_value;not null"`
+}
+
+const (
+	// UserMetaTokenValidAfter meta key of the unix time in milliseconds, tokens issued before or at it are invalid
+	UserMetaTokenValidAfter = "token_valid_after"
+	// UserMetaAPITokenPrefix meta key prefix of personal api token, the key is followed by the token hash
+	UserMetaAPITokenPrefix = "api_token:"
+	// UserMetaTOTPSecret meta key of the TOTP secret, two-factor authentication is enabled if it exists
+	UserMetaTOTPSecret = "totp_secret"
+	// UserMetaTOTPPendingSecret meta key of the TOTP secret which is enrolled but not activated
+	UserMetaTOTPPendingSecret = "totp_pending_secret"
+	// UserMetaTOTPLastStep meta key of the last used TOTP time step, codes can not be used twice
+	UserMetaTOTPLastStep = "totp_last_step"
+	// UserMetaTOTPRecoveryCodes meta key of the hashes of unused recovery codes in JSON
+	UserMetaTOTPRecoveryCodes = "totp_recovery_codes"
+	// UserMetaTOTPLoginAttempts meta key of the number of codes tried since the last first login step
+	UserMetaTOTPLoginAttempts = "totp_login_attempts"
+)
+
+// TableName is the user meta table name in db
+func (um *UserMeta) TableName() string {
+	return "pt_user_meta"
+}
+
+// Create creates a user meta
+func (um *UserMeta) Create(db *gorm.DB) error {
+	return db.Create(um).Error
+}
@@ -21,4 +21,21 @@ const (
 	CacheKnowledgeItemListPrefix = "PUTI_KITEM_"
 	// CacheKnowledgeItemContentPrefix key prefix for knowledge item content
 	CacheKnowledgeItemContentPrefix = "PUTI_KITEM_CONTENT_"
+
+	// CacheFeedPrefix key prefix for RSS and Atom feeds
+	CacheFeedPrefix = "PUTI_FEED_"
+	// CacheFeedVersionKey key of feed cache version; feeds cached in old version are expired
+	CacheFeedVersionKey = "PUTI_FEED_VERSION"
+
+	// CacheSitemapPrefix key prefix for sitemap and its parts
+	CacheSitemapPrefix = "PUTI_SITEMAP_"
+	// CacheSitemapVersionKey key of sitemap cache version; sitemaps cached in old version are expired
+	CacheSitemapVersionKey = "PUTI_SITEMAP_VERSION"
+
+	// CacheImageSizePrefix key prefix for the url of image size, followed by the size name and the original url
+	CacheImageSizePrefix = "PUTI_IMAGE_SIZE_"
+	// CacheContentImagePrefix key prefix for the dimensions and srcset of image in content, followed by the image path
+	CacheContentImagePrefix = "PUTI_CONTENT_IMAGE_"
+	// CacheImageVariantPrefix key prefix for the variant formats of image, followed by the image path
+	CacheImageVariantPrefix = "PUTI_IMAGE_VARIANT_"
 )
@@ -19,6 +19,12 @@ var (
 	Safety *SafetyConfig
 	Log    *LogConfig
 	Db     *DbConfig
+	// Storage is nil if the storage section is not configured
+	Storage *StorageConfig
+	// Upload is nil if the upload section is not configured
+	Upload *UploadConfig
+	// Markdown is nil if the markdown section is not configured
+	Markdown *MarkdownConfig
 )
 
 // NewConfig set up viper config and return a Config struct instance
@@ -83,6 +89,21 @@ func (c *Config) readConfig() error {
 		return err
 	}
 
+	err = c.readConfigSections("storage", &Storage)
+	if err != nil {
+		return err
+	}
+
+	err = c.readConfigSections("upload", &Upload)
+	if err != nil {
+		return err
+	}
+
+	err = c.readConfigSections("markdown", &Markdown)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
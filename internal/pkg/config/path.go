@@ -14,9 +14,13 @@ const (
 	PathArchives = "/archives"
 
 	PathRSS     = "/rss"
+	PathAtom    = "/atom.xml"
 	PathSiteMap = "/sitemap.xml"
 	PathRobots  = "/robots.txt"
 	PathFavicon = "/favicon.ico"
+
+	// PathSiteMapPart parts of the sitemap when it is split by sitemap index, such as /sitemap/1.xml
+	PathSiteMapPart = "/sitemap"
 )
 
 // static path config
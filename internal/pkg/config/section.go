@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type ServerConfig struct {
 	Runmode    string   `mapstructure:"runmode"`
 	Name       string   `mapstructure:"name"`
@@ -13,7 +15,9 @@ type ServerConfig struct {
 }
 
 type SafetyConfig struct {
-	JwtSecret string `mapstructure:"jwt_secret"`
+	JwtSecret          string        `mapstructure:"jwt_secret"`
+	AccessTokenExpire  time.Duration `mapstructure:"access_token_expire"`
+	RefreshTokenExpire time.Duration `mapstructure:"refresh_token_expire"`
 }
 
 type LogConfig struct {
@@ -33,3 +37,58 @@ type DbConfig struct {
 	MaxIdleConns int    `mapstructure:"max_idle_conns"`
 	MaxOpenConns int    `mapstructure:"max_open_conns"`
 }
+
+type StorageConfig struct {
+	Type      string       `mapstructure:"type"`
+	PublicURL string       `mapstructure:"public_url"`
+	S3        S3Config     `mapstructure:"s3"`
+	WebDAV    WebDAVConfig `mapstructure:"webdav"`
+}
+
+type S3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	PathStyle bool   `mapstructure:"path_style"`
+	Prefix    string `mapstructure:"prefix"`
+}
+
+type WebDAVConfig struct {
+	URL      string `mapstructure:"url"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+type UploadConfig struct {
+	// MaxSize max file size in megabytes by file type, such as picture, video and text
+	MaxSize map[string]int64 `mapstructure:"max_size"`
+	// ImageVariants encoder commands by variant format, such as webp and avif; the format is not generated without command
+	// {input} and {output} in the command are replaced by the file paths
+	ImageVariants map[string]string `mapstructure:"image_variants"`
+	// Posters commands to generate the poster image by media type, such as the first frame of video and the first page of document
+	// {input} and {output} in the command are replaced by the file paths, the output is a JPEG image
+	Posters map[string]string `mapstructure:"posters"`
+	// TempPath local directory of chunked uploads in progress, it is the system temp directory by default
+	// It must be shared by all instances, or requests of a chunked upload must be routed to the same instance.
+	TempPath string `mapstructure:"temp_path"`
+	// TempExpire hours to keep the abandoned chunked uploads, 24 by default
+	TempExpire int `mapstructure:"temp_expire"`
+	// MaxPixels max width × height of images to decode, larger images are saved without derivatives; 50 million by default
+	MaxPixels int64 `mapstructure:"max_pixels"`
+}
+
+type MarkdownConfig struct {
+	// Sanitize allowlist of HTML rendered from markdown
+	Sanitize SanitizeConfig `mapstructure:"sanitize"`
+}
+
+type SanitizeConfig struct {
+	// Elements allowed attributes by element name; the default allowlist is used if it is empty
+	Elements map[string][]string `mapstructure:"elements"`
+	// Attributes attributes allowed on all elements, such as id and class
+	Attributes []string `mapstructure:"attributes"`
+	// URLSchemes schemes allowed in href, src and so on; relative urls are always allowed
+	URLSchemes []string `mapstructure:"url_schemes"`
+}
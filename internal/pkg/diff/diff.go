@@ -0,0 +1,147 @@
+package diff
+
+import (
+	"strings"
+)
+
+// Op operation of a diff line
+type Op string
+
+const (
+	// OpEqual the line is in both texts
+	OpEqual Op = "equal"
+	// OpInsert the line is only in the new text
+	OpInsert Op = "insert"
+	// OpDelete the line is only in the old text
+	OpDelete Op = "delete"
+)
+
+// Line a line of the diff
+// OldLine and NewLine are 1-based line numbers, and 0 if the line does not exist in that text
+type Line struct {
+	Op      Op     `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line"`
+	NewLine int    `json:"new_line"`
+}
+
+// Lines compare the old and new text line by line
+// The common prefix and suffix are skipped before the longest common subsequence is computed.
+// The memory used is linear in the number of lines, so long texts can be compared.
+func Lines(old, new string) []*Line {
+	a, b := splitLines(old), splitLines(new)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]*Line, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, &Line{Op: OpEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	matches := make([][2]int, 0)
+	lcs(ma, mb, 0, 0, &matches)
+	// sentinel to output the lines after the last match
+	matches = append(matches, [2]int{len(ma), len(mb)})
+
+	i, j := 0, 0
+	for _, m := range matches {
+		for ; i < m[0]; i++ {
+			lines = append(lines, &Line{Op: OpDelete, Text: ma[i], OldLine: prefix + i + 1})
+		}
+		for ; j < m[1]; j++ {
+			lines = append(lines, &Line{Op: OpInsert, Text: mb[j], NewLine: prefix + j + 1})
+		}
+		if i < len(ma) && j < len(mb) {
+			lines = append(lines, &Line{Op: OpEqual, Text: ma[i], OldLine: prefix + i + 1, NewLine: prefix + j + 1})
+			i++
+			j++
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		lines = append(lines, &Line{
+			Op:      OpEqual,
+			Text:    a[len(a)-suffix+k],
+			OldLine: len(a) - suffix + k + 1,
+			NewLine: len(b) - suffix + k + 1,
+		})
+	}
+
+	return lines
+}
+
+// lcs find the longest common subsequence of a and b, and append the indexes of matched lines to matches
+// It is the Hirschberg's algorithm, which needs linear space instead of a len(a)×len(b) matrix.
+// aOff and bOff are the offsets of a and b in the whole texts.
+func lcs(a, b []string, aOff, bOff int, matches *[][2]int) {
+	if len(a) == 0 || len(b) == 0 {
+		return
+	}
+	if len(a) == 1 {
+		for j := range b {
+			if a[0] == b[j] {
+				*matches = append(*matches, [2]int{aOff, bOff + j})
+				return
+			}
+		}
+		return
+	}
+
+	// split b where the lengths of the two halves sum to the max
+	mid := len(a) / 2
+	forward := lcsLengths(a[:mid], b)
+	backward := lcsLengths(reverse(a[mid:]), reverse(b))
+	k, max := 0, -1
+	for j := 0; j <= len(b); j++ {
+		if l := forward[j] + backward[len(b)-j]; l > max {
+			k, max = j, l
+		}
+	}
+
+	lcs(a[:mid], b[:k], aOff, bOff, matches)
+	lcs(a[mid:], b[k:], aOff+mid, bOff+k, matches)
+}
+
+// lcsLengths get the lengths of the longest common subsequence of a and every prefix of b
+// The j-th length is for b[:j].
+func lcsLengths(a, b []string) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else if prev[j+1] >= cur[j] {
+				cur[j+1] = prev[j+1]
+			} else {
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func reverse(s []string) []string {
+	r := make([]string, len(s))
+	for i, v := range s {
+		r[len(s)-1-i] = v
+	}
+	return r
+}
+
+// splitLines split the text into lines; line endings are removed and an empty text has no line
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
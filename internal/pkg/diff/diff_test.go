@@ -0,0 +1,100 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func format(lines []*Line) string {
+	var b strings.Builder
+	for _, l := range lines {
+		switch l.Op {
+		case OpEqual:
+			fmt.Fprintf(&b, " %d,%d %s\n", l.OldLine, l.NewLine, l.Text)
+		case OpDelete:
+			fmt.Fprintf(&b, "-%d %s\n", l.OldLine, l.Text)
+		case OpInsert:
+			fmt.Fprintf(&b, "+%d %s\n", l.NewLine, l.Text)
+		}
+	}
+	return b.String()
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     string
+	}{
+		{"", "", ""},
+		{"a\nb\n", "a\nb", " 1,1 a\n 2,2 b\n"},
+		{"", "a\nb", "+1 a\n+2 b\n"},
+		{"a\nb", "", "-1 a\n-2 b\n"},
+		{"a\nb\nc", "a\nx\nc", " 1,1 a\n-2 b\n+2 x\n 3,3 c\n"},
+		{"a\nb\nc\nd", "a\nc\nd\ne", " 1,1 a\n-2 b\n 3,2 c\n 4,3 d\n+4 e\n"},
+		{"x\na\nb\ny", "y\na\nb\nx", "-1 x\n+1 y\n 2,2 a\n 3,3 b\n-4 y\n+4 x\n"},
+		{"a\r\nb\r\n", "a\nb\n", " 1,1 a\n 2,2 b\n"},
+	}
+
+	for _, tt := range tests {
+		if got := format(Lines(tt.old, tt.new)); got != tt.want {
+			t.Errorf("Lines(%q, %q) =\n%s\nwant\n%s", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+// lcsLength length of the longest common subsequence by the full matrix
+func lcsLength(a, b []string) int {
+	l := make([][]int, len(a)+1)
+	for i := range l {
+		l[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				l[i][j] = l[i+1][j+1] + 1
+			} else if l[i+1][j] > l[i][j+1] {
+				l[i][j] = l[i+1][j]
+			} else {
+				l[i][j] = l[i][j+1]
+			}
+		}
+	}
+	return l[0][0]
+}
+
+func TestLinesRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	text := func() string {
+		lines := make([]string, r.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + r.Intn(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for n := 0; n < 500; n++ {
+		old, new := text(), text()
+		var gotOld, gotNew []string
+		equal := 0
+		for _, l := range Lines(old, new) {
+			if l.Op != OpInsert {
+				gotOld = append(gotOld, l.Text)
+			}
+			if l.Op != OpDelete {
+				gotNew = append(gotNew, l.Text)
+			}
+			if l.Op == OpEqual {
+				equal++
+			}
+		}
+
+		if strings.Join(gotOld, "\n") != old || strings.Join(gotNew, "\n") != new {
+			t.Fatalf("Lines(%q, %q) does not restore the texts", old, new)
+		}
+		if want := lcsLength(splitLines(old), splitLines(new)); equal != want {
+			t.Fatalf("Lines(%q, %q) has %d equal lines, want %d", old, new, equal, want)
+		}
+	}
+}
@@ -8,6 +8,8 @@ var (
 	InternalServerError = &Errno{Code: 10001, Message: "Internal server error"}
 	// ErrBind binding request struct error
 	ErrBind = &Errno{Code: 10002, Message: "Error occurred while binding the request body to the struct"}
+	// ErrTooManyRequests request rate limit exceeded error
+	ErrTooManyRequests = &Errno{Code: 10003, Message: "Too many requests, please try again later."}
 
 	// ErrValidation Validation failed error
 	ErrValidation = &Errno{Code: 20001, Message: "Validation failed."}
@@ -29,6 +31,24 @@ var (
 	ErrPasswordIncorrect = &Errno{Code: 20104, Message: "The password was incorrect."}
 	// ErrSaveAvatar Save avatar failed error
 	ErrSaveAvatar = &Errno{Code: 20105, Message: "Save file failed"}
+	// ErrPermissionDenied the role of login user has no permission to do the action
+	ErrPermissionDenied = &Errno{Code: 20106, Message: "Permission denied."}
+	// ErrRegisterClosed public registration is not allowed error
+	ErrRegisterClosed = &Errno{Code: 20107, Message: "Registration is closed."}
+	// ErrAccountExist account was already registered error
+	ErrAccountExist = &Errno{Code: 20108, Message: "The account was already exist."}
+	// ErrEmailExist email was already registered error
+	ErrEmailExist = &Errno{Code: 20109, Message: "The email was already exist."}
+	// ErrAPITokenNotFound personal api token not found error
+	ErrAPITokenNotFound = &Errno{Code: 20110, Message: "The api token was not found."}
+	// ErrTwoFactorCode two-factor authentication code or recovery code incorrect error
+	ErrTwoFactorCode = &Errno{Code: 20111, Message: "The two-factor authentication code was incorrect."}
+	// ErrTwoFactorNotEnabled two-factor authentication is not enabled or enrolled error
+	ErrTwoFactorNotEnabled = &Errno{Code: 20112, Message: "Two-factor authentication is not enabled."}
+	// ErrTwoFactorEnabled two-factor authentication is already enabled error
+	ErrTwoFactorEnabled = &Errno{Code: 20113, Message: "Two-factor authentication is already enabled."}
+	// ErrTwoFactorAttempts too many incorrect codes were tried with the first login step token
+	ErrTwoFactorAttempts = &Errno{Code: 20114, Message: "Too many incorrect codes, please login again."}
 )
 
 // media errors
@@ -39,6 +59,20 @@ var (
 	ErrMediaNotFound = &Errno{Code: 20202, Message: "The media was not found."}
 	// ErrTitleEmpty Title is empty error
 	ErrTitleEmpty = &Errno{Code: 20203, Message: "Title can not be empty."}
+	// ErrFileTooLarge the file is larger than the max size of its type
+	ErrFileTooLarge = &Errno{Code: 20204, Message: "The file is too large."}
+	// ErrFileTypeNotAllowed the file type is not allowed to upload
+	ErrFileTypeNotAllowed = &Errno{Code: 20205, Message: "The file type is not allowed."}
+	// ErrFileTypeMismatch the file extension does not match the file content
+	ErrFileTypeMismatch = &Errno{Code: 20206, Message: "The file extension does not match the file content."}
+	// ErrUploadNotFound the chunked upload does not exist or has expired
+	ErrUploadNotFound = &Errno{Code: 20207, Message: "The upload was not found or has expired."}
+	// ErrUploadOffset the chunk offset is not the current offset of the upload
+	ErrUploadOffset = &Errno{Code: 20208, Message: "The upload offset does not match."}
+	// ErrChecksumMismatch the checksum of the uploaded data does not match
+	ErrChecksumMismatch = &Errno{Code: 20209, Message: "The checksum does not match."}
+	// ErrUploadIncomplete the chunked upload is completed before all data is received
+	ErrUploadIncomplete = &Errno{Code: 20210, Message: "The upload is incomplete."}
 )
 
 // taxonomy errors
@@ -61,6 +95,8 @@ var (
 	ErrArticleNotFount = &Errno{Code: 20401, Message: "The article was not found."}
 	// ErrArticleCreateFailed create article failed error
 	ErrArticleCreateFailed = &Errno{Code: 20402, Message: "Create article failed."}
+	// ErrRevisionNotFound revision of article or page not found error
+	ErrRevisionNotFound = &Errno{Code: 20403, Message: "The revision was not found."}
 )
 
 // Page errors
@@ -106,3 +142,19 @@ var (
 	// ErrKnowledgeItemCanNotBeDeleted delete knowledge item error
 	ErrKnowledgeItemCanNotBeDeleted = &Errno{Code: 20905, Message: "Knowledge item can not be deleted."}
 )
+
+// Comment errors
+var (
+	// ErrCommentClosed comment is closed for the post
+	ErrCommentClosed = &Errno{Code: 21001, Message: "Comment is closed."}
+	// ErrCommentNeedLogin comment need user login
+	ErrCommentNeedLogin = &Errno{Code: 21002, Message: "You must log in to post a comment."}
+	// ErrCommentParentID error parent comment id
+	ErrCommentParentID = &Errno{Code: 21003, Message: "The comment being replied to was not found."}
+	// ErrCommentCreateFailed create comment failed
+	ErrCommentCreateFailed = &Errno{Code: 21004, Message: "Create comment failed."}
+	// ErrCommentNotFound comment not found error
+	ErrCommentNotFound = &Errno{Code: 21005, Message: "The comment was not found."}
+	// ErrCommentStatus illegal comment status
+	ErrCommentStatus = &Errno{Code: 21006, Message: "Illegal comment status."}
+)
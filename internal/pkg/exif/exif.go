@@ -0,0 +1,367 @@
+// Package exif read and edit the EXIF of JPEG images
+// Only the tags used by media are read: camera, taken time, orientation, dimensions and GPS.
+package exif
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound the image does not have EXIF
+	ErrNotFound = errors.New("exif: not found")
+	// ErrInvalid the segments of the JPEG image or its EXIF can not be read
+	ErrInvalid = errors.New("exif: invalid JPEG or EXIF")
+)
+
+// xmpHeaders headers of the APP1 segments of XMP and extended XMP
+var xmpHeaders = []string{
+	"http://ns.adobe.com/xap/1.0/\x00",
+	"http://ns.adobe.com/xmp/extension/\x00",
+}
+
+// tags
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagPixelXDimension  = 0xA002
+	tagPixelYDimension  = 0xA003
+)
+
+// value types and their sizes
+var typeSizes = map[uint16]int{
+	1:  1, // BYTE
+	2:  1, // ASCII
+	3:  2, // SHORT
+	4:  4, // LONG
+	5:  8, // RATIONAL
+	7:  1, // UNDEFINED
+	9:  4, // SLONG
+	10: 8, // SRATIONAL
+}
+
+// Exif info of image
+type Exif struct {
+	Make    string
+	Model   string
+	TakenAt time.Time // DateTimeOriginal or DateTime, in unknown timezone and parsed as UTC
+	// Orientation 1 to 8, 1 is normal
+	Orientation int
+	Width       int
+	Height      int
+	HasGPS      bool
+}
+
+// Camera make and model of the camera
+func (e *Exif) Camera() string {
+	if e.Model == "" || strings.HasPrefix(e.Model, e.Make) {
+		return e.Model
+	}
+	if e.Make == "" {
+		return e.Model
+	}
+	return e.Make + " " + e.Model
+}
+
+// tiff the TIFF structure in the APP1 segment
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+type entry struct {
+	// offset of the entry in data
+	offset int
+	tag    uint16
+	typ    uint16
+	count  uint32
+}
+
+// segment marker segment of JPEG, data[start:end] is the whole segment with its marker
+type segment struct {
+	marker byte
+	start  int
+	end    int
+}
+
+// payload the data of the segment after its marker and length
+func (sg segment) payload(data []byte) []byte {
+	return data[sg.start+4 : sg.end]
+}
+
+// segments read the marker segments of JPEG before the start of scan
+func segments(data []byte) ([]segment, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrNotFound
+	}
+
+	var result []segment
+	for i := 2; ; {
+		// only the segments, such as the Exif segment to copy
+		if i == len(data) {
+			return result, nil
+		}
+		if i+2 > len(data) || data[i] != 0xFF {
+			return nil, ErrInvalid
+		}
+		marker := data[i+1]
+		// fill byte before the marker
+		if marker == 0xFF {
+			i++
+			continue
+		}
+		// start of scan or end of image
+		if marker == 0xDA || marker == 0xD9 {
+			return result, nil
+		}
+		if i+4 > len(data) {
+			return nil, ErrInvalid
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, ErrInvalid
+		}
+
+		result = append(result, segment{marker: marker, start: i, end: end})
+		i = end
+	}
+}
+
+// locate find the TIFF data in the Exif APP1 segment of JPEG
+// it returns the start of the APP1 segment and the TIFF data
+func locate(data []byte) (int, *tiff, error) {
+	list, err := segments(data)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for _, sg := range list {
+		payload := sg.payload(data)
+		if sg.marker != 0xE1 || !strings.HasPrefix(string(payload), "Exif\x00\x00") {
+			continue
+		}
+		if len(payload) < 14 {
+			return 0, nil, ErrInvalid
+		}
+
+		t := &tiff{data: payload[6:]}
+		switch string(t.data[:2]) {
+		case "II":
+			t.order = binary.LittleEndian
+		case "MM":
+			t.order = binary.BigEndian
+		default:
+			return 0, nil, ErrInvalid
+		}
+		return sg.start, t, nil
+	}
+
+	return 0, nil, ErrNotFound
+}
+
+// ifd read entries of the IFD at offset
+func (t *tiff) ifd(offset uint32) []*entry {
+	if int64(offset)+2 > int64(len(t.data)) {
+		return nil
+	}
+	count := int(t.order.Uint16(t.data[offset:]))
+
+	entries := make([]*entry, 0, count)
+	for i := 0; i < count; i++ {
+		o := int(offset) + 2 + i*12
+		if o+12 > len(t.data) {
+			break
+		}
+		entries = append(entries, &entry{
+			offset: o,
+			tag:    t.order.Uint16(t.data[o:]),
+			typ:    t.order.Uint16(t.data[o+2:]),
+			count:  t.order.Uint32(t.data[o+4:]),
+		})
+	}
+	return entries
+}
+
+// value the value bytes of the entry, which is in the entry or at the offset
+func (t *tiff) value(e *entry) []byte {
+	size := int64(typeSizes[e.typ]) * int64(e.count)
+	if size <= 4 {
+		return t.data[e.offset+8 : e.offset+8+int(size)]
+	}
+
+	offset := int64(t.order.Uint32(t.data[e.offset+8:]))
+	if offset+size > int64(len(t.data)) {
+		return nil
+	}
+	return t.data[offset : offset+size]
+}
+
+func (t *tiff) uint(e *entry) int {
+	v := t.value(e)
+	switch {
+	case e.typ == 3 && len(v) >= 2:
+		return int(t.order.Uint16(v))
+	case e.typ == 4 && len(v) >= 4:
+		return int(t.order.Uint32(v))
+	}
+	return 0
+}
+
+func (t *tiff) string(e *entry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(t.value(e)), "\x00"))
+}
+
+// Parse read the EXIF of the JPEG image
+// It returns ErrNotFound if the image does not have EXIF, and ErrInvalid if the image or its EXIF is broken.
+func Parse(data []byte) (*Exif, error) {
+	_, t, err := locate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exif{Orientation: 1}
+	var dateTime string
+	for _, en := range t.ifd(t.order.Uint32(t.data[4:])) {
+		switch en.tag {
+		case tagMake:
+			e.Make = t.string(en)
+		case tagModel:
+			e.Model = t.string(en)
+		case tagOrientation:
+			if o := t.uint(en); o >= 1 && o <= 8 {
+				e.Orientation = o
+			}
+		case tagDateTime:
+			dateTime = t.string(en)
+		case tagGPSIFD:
+			e.HasGPS = len(t.ifd(uint32(t.uint(en)))) > 0
+		case tagExifIFD:
+			for _, sub := range t.ifd(uint32(t.uint(en))) {
+				switch sub.tag {
+				case tagDateTimeOriginal:
+					dateTime = t.string(sub)
+				case tagPixelXDimension:
+					e.Width = t.uint(sub)
+				case tagPixelYDimension:
+					e.Height = t.uint(sub)
+				}
+			}
+		}
+	}
+
+	if takenAt, err := time.Parse("2006:01:02 15:04:05", dateTime); err == nil {
+		e.TakenAt = takenAt
+	}
+	return e, nil
+}
+
+// StripGPS remove the GPS info of the JPEG image in place
+// Entries and values of the GPS IFD are zeroed and the IFD is left empty, so the size of image is not changed.
+// It returns false if the image does not have GPS info.
+func StripGPS(data []byte) bool {
+	_, t, err := locate(data)
+	if err != nil {
+		return false
+	}
+
+	for _, en := range t.ifd(t.order.Uint32(t.data[4:])) {
+		if en.tag != tagGPSIFD {
+			continue
+		}
+
+		offset := uint32(t.uint(en))
+		entries := t.ifd(offset)
+		if len(entries) == 0 {
+			return false
+		}
+		for _, gps := range entries {
+			zero(t.value(gps))
+			zero(t.data[gps.offset : gps.offset+12])
+		}
+		t.order.PutUint16(t.data[offset:], 0)
+		return true
+	}
+
+	return false
+}
+
+// StripXMP remove the XMP APP1 segments of the JPEG image, which may also contain the location
+// It returns the image without XMP and whether XMP was removed.
+func StripXMP(data []byte) ([]byte, bool, error) {
+	list, err := segments(data)
+	if err != nil {
+		return data, false, err
+	}
+
+	result := make([]byte, 0, len(data))
+	last := 0
+	for _, sg := range list {
+		if sg.marker != 0xE1 || !isXMP(sg.payload(data)) {
+			continue
+		}
+		result = append(result, data[last:sg.start]...)
+		last = sg.end
+	}
+	if last == 0 {
+		return data, false, nil
+	}
+	return append(result, data[last:]...), true, nil
+}
+
+// isXMP check if the APP1 payload is XMP
+func isXMP(payload []byte) bool {
+	for _, header := range xmpHeaders {
+		if strings.HasPrefix(string(payload), header) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetOrientation set the orientation tag of the JPEG image in place if it exists
+func SetOrientation(data []byte, orientation int) {
+	_, t, err := locate(data)
+	if err != nil {
+		return
+	}
+
+	for _, en := range t.ifd(t.order.Uint32(t.data[4:])) {
+		if en.tag == tagOrientation && en.typ == 3 && en.count == 1 {
+			t.order.PutUint16(t.data[en.offset+8:], uint16(orientation))
+		}
+	}
+}
+
+// Copy copy the Exif APP1 segment from src into the JPEG image dst, after the start of image
+// It returns dst if src does not have EXIF or dst is not a JPEG image.
+func Copy(dst, src []byte) []byte {
+	start, _, err := locate(src)
+	if err != nil || len(dst) < 2 || dst[0] != 0xFF || dst[1] != 0xD8 {
+		return dst
+	}
+
+	length := int(binary.BigEndian.Uint16(src[start+2:]))
+	segment := src[start : start+2+length]
+
+	result := make([]byte, 0, len(dst)+len(segment))
+	result = append(result, dst[:2]...)
+	result = append(result, segment...)
+	return append(result, dst[2:]...)
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
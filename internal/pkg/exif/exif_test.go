@@ -0,0 +1,179 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+	"time"
+)
+
+type testEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// buildIFD write the IFD at offset of buf, values larger than 4 bytes are appended after the IFD
+func buildIFD(buf []byte, offset int, entries []testEntry) []byte {
+	le := binary.LittleEndian
+	dataOffset := offset + 2 + len(entries)*12 + 4
+	ifd := make([]byte, dataOffset-offset)
+	le.PutUint16(ifd, uint16(len(entries)))
+
+	var data []byte
+	for i, e := range entries {
+		o := 2 + i*12
+		le.PutUint16(ifd[o:], e.tag)
+		le.PutUint16(ifd[o+2:], e.typ)
+		le.PutUint32(ifd[o+4:], e.count)
+		if len(e.value) <= 4 {
+			copy(ifd[o+8:], e.value)
+		} else {
+			le.PutUint32(ifd[o+8:], uint32(dataOffset+len(data)))
+			data = append(data, e.value...)
+		}
+	}
+
+	buf = append(buf[:offset], ifd...)
+	return append(buf, data...)
+}
+
+func ascii(s string) []byte { return append([]byte(s), 0) }
+
+func short(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return b
+}
+
+func long(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+// testJPEG a 4x2 JPEG image with the EXIF tags set
+func testJPEG(t *testing.T) []byte {
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+
+	// the IFD0 has 5 entries with the Make and Model out of line; sub IFDs are placed after it
+	const ifd0Size = 2 + 5*12 + 4 + 6 + 8
+	exifOffset := uint32(8 + ifd0Size)
+	const exifSize = 2 + 3*12 + 4 + 20
+	gpsOffset := exifOffset + exifSize
+
+	tiff = buildIFD(tiff, 8, []testEntry{
+		{tagMake, 2, 6, ascii("Canon")},
+		{tagModel, 2, 8, ascii("EOS 5D ")},
+		{tagOrientation, 3, 1, short(6)},
+		{tagExifIFD, 4, 1, long(exifOffset)},
+		{tagGPSIFD, 4, 1, long(gpsOffset)},
+	})
+	tiff = buildIFD(tiff, int(exifOffset), []testEntry{
+		{tagDateTimeOriginal, 2, 20, ascii("2020:05:01 12:30:45")},
+		{tagPixelXDimension, 4, 1, long(4)},
+		{tagPixelYDimension, 3, 1, short(2)},
+	})
+	tiff = buildIFD(tiff, int(gpsOffset), []testEntry{
+		{1, 2, 2, ascii("N")},
+		{2, 5, 3, []byte{30, 0, 0, 0, 1, 0, 0, 0, 15, 0, 0, 0, 1, 0, 0, 0, 9, 0, 0, 0, 1, 0, 0, 0}},
+	})
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+6+len(tiff)))
+	segment = append(segment, "Exif\x00\x00"...)
+	segment = append(segment, tiff...)
+	src := append([]byte{0xFF, 0xD8}, segment...)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 2)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return Copy(buf.Bytes(), src)
+}
+
+func TestParse(t *testing.T) {
+	data := testJPEG(t)
+
+	e, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if e.Camera() != "Canon EOS 5D" {
+		t.Errorf("Camera() = %q, want %q", e.Camera(), "Canon EOS 5D")
+	}
+	if e.Orientation != 6 || e.Width != 4 || e.Height != 2 || !e.HasGPS {
+		t.Errorf("Parse() = %+v, want orientation 6, 4x2 with GPS", e)
+	}
+	if want := time.Date(2020, 5, 1, 12, 30, 45, 0, time.UTC); !e.TakenAt.Equal(want) {
+		t.Errorf("TakenAt = %v, want %v", e.TakenAt, want)
+	}
+
+	if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("decode JPEG with EXIF error = %v", err)
+	}
+
+	if _, err := Parse([]byte("not a jpeg")); err != ErrNotFound {
+		t.Errorf("Parse() invalid error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStripGPSAndSetOrientation(t *testing.T) {
+	data := testJPEG(t)
+	size := len(data)
+
+	if !StripGPS(data) {
+		t.Fatal("StripGPS() = false, want true")
+	}
+	if StripGPS(data) {
+		t.Error("StripGPS() again = true, want false")
+	}
+	if len(data) != size {
+		t.Errorf("StripGPS() changed the size %d to %d", size, len(data))
+	}
+	if bytes.Contains(data, []byte{30, 0, 0, 0, 1, 0, 0, 0, 15, 0, 0, 0, 1}) {
+		t.Error("StripGPS() left the latitude value")
+	}
+
+	SetOrientation(data, 1)
+
+	e, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if e.HasGPS || e.Orientation != 1 || e.Camera() != "Canon EOS 5D" {
+		t.Errorf("Parse() after strip = %+v", e)
+	}
+}
+
+func TestStripXMP(t *testing.T) {
+	data := testJPEG(t)
+	xmp := []byte{0xFF, 0xE1, 0, 0}
+	payload := "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta><exif:GPSLatitude>30,15N</exif:GPSLatitude></x:xmpmeta>"
+	binary.BigEndian.PutUint16(xmp[2:], uint16(2+len(payload)))
+	xmp = append(xmp, payload...)
+	withXMP := append(append(append([]byte{}, data[:2]...), xmp...), data[2:]...)
+
+	stripped, ok, err := StripXMP(withXMP)
+	if err != nil || !ok {
+		t.Fatalf("StripXMP() = %v, %v, want true, nil", ok, err)
+	}
+	if !bytes.Equal(stripped, data) {
+		t.Error("StripXMP() did not remove only the XMP segment")
+	}
+	if _, ok, _ := StripXMP(data); ok {
+		t.Error("StripXMP() without XMP = true, want false")
+	}
+
+	// the length of the segment is out of the image
+	broken := append([]byte{}, withXMP[:8]...)
+	if _, _, err := StripXMP(broken); err != ErrInvalid {
+		t.Errorf("StripXMP() broken error = %v, want ErrInvalid", err)
+	}
+	if _, err := Parse(broken); err != ErrInvalid {
+		t.Errorf("Parse() broken error = %v, want ErrInvalid", err)
+	}
+}
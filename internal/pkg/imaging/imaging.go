@@ -0,0 +1,316 @@
+// Package imaging decode, resize and encode JPEG, PNG and GIF images with the standard library
+// It is used to generate image derivatives of the uploaded media.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+)
+
+// supported image formats, the same as the format name registered in image package
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+)
+
+// jpegQuality quality of the encoded JPEG derivatives
+const jpegQuality = 85
+
+// defaultMaxPixels max pixels of images to decode if it is not set in config
+const defaultMaxPixels = 50000000
+
+var (
+	// ErrFormat means the image format is not supported
+	ErrFormat = errors.New("imaging: unsupported image format")
+	// ErrTooLarge means the image has more pixels than the limit, decoding it may take too much memory
+	ErrTooLarge = errors.New("imaging: image is too large")
+)
+
+// IsSupported check if the format can be decoded and encoded
+func IsSupported(format string) bool {
+	return format == FormatJPEG || format == FormatPNG || format == FormatGIF
+}
+
+// maxPixels get the max pixels of images to decode
+func maxPixels() int64 {
+	if config.Upload != nil && config.Upload.MaxPixels > 0 {
+		return config.Upload.MaxPixels
+	}
+	return defaultMaxPixels
+}
+
+// Decode decode the image and return its format
+// The dimensions declared in the header are checked first, images larger than the limit are not decoded.
+func Decode(r io.Reader) (image.Image, string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err == image.ErrFormat {
+		return nil, "", ErrFormat
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels() {
+		return nil, "", ErrTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err == image.ErrFormat {
+		return nil, "", ErrFormat
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if !IsSupported(format) {
+		return nil, "", ErrFormat
+	}
+
+	return img, format, nil
+}
+
+// Encode encode the image in the format
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case FormatPNG:
+		return png.Encode(w, img)
+	case FormatGIF:
+		return gif.Encode(w, img, nil)
+	}
+
+	return ErrFormat
+}
+
+// Fit scale down the image to fit in the box of maxWidth and maxHeight, keeping the aspect ratio
+// zero width or height means no limit in that dimension.
+// It returns false if the image is already small enough, images are never scaled up.
+func Fit(img image.Image, maxWidth, maxHeight int) (image.Image, bool) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 || (maxWidth <= 0 && maxHeight <= 0) {
+		return nil, false
+	}
+
+	ratio := math.Inf(1)
+	if maxWidth > 0 {
+		ratio = float64(maxWidth) / float64(w)
+	}
+	if maxHeight > 0 {
+		ratio = math.Min(ratio, float64(maxHeight)/float64(h))
+	}
+	if ratio >= 1 {
+		return nil, false
+	}
+
+	dstW := int(math.Max(1, math.Round(float64(w)*ratio)))
+	dstH := int(math.Max(1, math.Round(float64(h)*ratio)))
+	return Resize(img, dstW, dstH), true
+}
+
+// Fill scale and crop the image from center to fill exactly width x height
+// It returns false if the image is smaller than the box in both dimensions, images are never scaled up.
+func Fill(img image.Image, width, height int) (image.Image, bool) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 || width <= 0 || height <= 0 || (w <= width && h <= height) {
+		return nil, false
+	}
+
+	// the box is larger than image in one dimension; crop to the box without scaling
+	if w < width || h < height {
+		width, height = int(math.Min(float64(w), float64(width))), int(math.Min(float64(h), float64(height)))
+	}
+
+	// crop the largest area in the aspect ratio of the box from center
+	cropW, cropH := w, int(math.Round(float64(w)*float64(height)/float64(width)))
+	if cropH > h {
+		cropW, cropH = int(math.Round(float64(h)*float64(width)/float64(height))), h
+	}
+	x0 := b.Min.X + (w-cropW)/2
+	y0 := b.Min.Y + (h-cropH)/2
+	cropped := toNRGBA(img).SubImage(image.Rect(x0, y0, x0+cropW, y0+cropH))
+
+	return Resize(cropped, width, height), true
+}
+
+// Resize resize the image to width x height with a linear filter, which is scaled when downsampling
+func Resize(img image.Image, width, height int) *image.NRGBA {
+	src := toNRGBA(img)
+	b := src.Bounds()
+	if b.Dx() == width && b.Dy() == height {
+		return src
+	}
+
+	// resize horizontally then vertically
+	tmp := image.NewNRGBA(image.Rect(0, 0, width, b.Dy()))
+	xWeights := resampleWeights(width, b.Dx())
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < width; x++ {
+			setPixel(tmp, x, y, xWeights[x], func(i int) []uint8 {
+				off := src.PixOffset(b.Min.X+i, b.Min.Y+y)
+				return src.Pix[off : off+4]
+			})
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	yWeights := resampleWeights(height, b.Dy())
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			setPixel(dst, x, y, yWeights[y], func(i int) []uint8 {
+				off := tmp.PixOffset(x, i)
+				return tmp.Pix[off : off+4]
+			})
+		}
+	}
+
+	return dst
+}
+
+type weight struct {
+	index int
+	value float64
+}
+
+// resampleWeights the source pixels and their weights of each destination pixel
+func resampleWeights(dstSize, srcSize int) [][]weight {
+	scale := float64(srcSize) / float64(dstSize)
+	support := math.Max(1, scale)
+
+	weights := make([][]weight, dstSize)
+	for i := 0; i < dstSize; i++ {
+		center := (float64(i)+0.5)*scale - 0.5
+		start := int(math.Floor(center - support))
+		end := int(math.Ceil(center + support))
+
+		var sum float64
+		for j := start; j <= end; j++ {
+			if j < 0 || j >= srcSize {
+				continue
+			}
+			v := 1 - math.Abs(float64(j)-center)/support
+			if v <= 0 {
+				continue
+			}
+			weights[i] = append(weights[i], weight{index: j, value: v})
+			sum += v
+		}
+
+		if sum == 0 {
+			// never be here, just in case of rounding
+			j := int(math.Min(math.Max(math.Round(center), 0), float64(srcSize-1)))
+			weights[i] = []weight{{index: j, value: 1}}
+			continue
+		}
+		for k := range weights[i] {
+			weights[i][k].value /= sum
+		}
+	}
+
+	return weights
+}
+
+// setPixel set the destination pixel by the weighted source pixels; colors are weighted by alpha
+func setPixel(dst *image.NRGBA, x, y int, weights []weight, pixel func(i int) []uint8) {
+	var r, g, b, a float64
+	for _, w := range weights {
+		p := pixel(w.index)
+		pa := float64(p[3]) * w.value
+		r += float64(p[0]) * pa
+		g += float64(p[1]) * pa
+		b += float64(p[2]) * pa
+		a += pa
+	}
+
+	off := dst.PixOffset(x, y)
+	if a > 0 {
+		dst.Pix[off] = clamp(r / a)
+		dst.Pix[off+1] = clamp(g / a)
+		dst.Pix[off+2] = clamp(b / a)
+	}
+	dst.Pix[off+3] = clamp(a)
+}
+
+func clamp(v float64) uint8 {
+	v = math.Round(v)
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+// toNRGBA convert the image to NRGBA for resampling
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		return nrgba
+	}
+
+	b := img.Bounds()
+	dst := image.NewNRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+	return dst
+}
+
+// Orient transform the image by the EXIF orientation, so that it is displayed upright without the orientation tag
+// Orientation 1 or unknown values return the image itself.
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	src := toNRGBA(img)
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for dy := 0; dy < dstH; dy++ {
+		for dx := 0; dx < dstW; dx++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontal
+				sx, sy = w-1-dx, dy
+			case 3: // rotate 180
+				sx, sy = w-1-dx, h-1-dy
+			case 4: // flip vertical
+				sx, sy = dx, h-1-dy
+			case 5: // transpose
+				sx, sy = dy, dx
+			case 6: // rotate 90 clockwise
+				sx, sy = dy, h-1-dx
+			case 7: // transverse
+				sx, sy = w-1-dy, h-1-dx
+			case 8: // rotate 90 counterclockwise
+				sx, sy = w-1-dy, dx
+			}
+			si := src.PixOffset(b.Min.X+sx, b.Min.Y+sy)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,160 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"os/exec"
+	"testing"
+)
+
+func newImage(w, h int, c color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestFit(t *testing.T) {
+	img := newImage(400, 200, color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+
+	dst, ok := Fit(img, 100, 100)
+	if !ok {
+		t.Fatal("Fit() = false, want true")
+	}
+	if b := dst.Bounds(); b.Dx() != 100 || b.Dy() != 50 {
+		t.Errorf("Fit() size = %dx%d, want 100x50", b.Dx(), b.Dy())
+	}
+	if c := color.NRGBAModel.Convert(dst.At(50, 25)).(color.NRGBA); c.R != 200 || c.G != 100 || c.B != 50 || c.A != 255 {
+		t.Errorf("Fit() color = %v, want the same color", c)
+	}
+
+	if _, ok := Fit(img, 1024, 1024); ok {
+		t.Error("Fit() larger box = true, want false")
+	}
+	if dst, ok := Fit(img, 200, 0); !ok || dst.Bounds().Dy() != 100 {
+		t.Errorf("Fit() width only = %v, %v, want height 100", dst, ok)
+	}
+}
+
+func TestFill(t *testing.T) {
+	img := newImage(400, 200, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+
+	dst, ok := Fill(img, 150, 150)
+	if !ok {
+		t.Fatal("Fill() = false, want true")
+	}
+	if b := dst.Bounds(); b.Dx() != 150 || b.Dy() != 150 {
+		t.Errorf("Fill() size = %dx%d, want 150x150", b.Dx(), b.Dy())
+	}
+
+	if _, ok := Fill(newImage(100, 100, color.Black), 150, 150); ok {
+		t.Error("Fill() smaller image = true, want false")
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	img := newImage(20, 10, color.NRGBA{R: 255, A: 255})
+	for _, format := range []string{FormatJPEG, FormatPNG, FormatGIF} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, img, format); err != nil {
+			t.Fatalf("Encode(%s) error = %v", format, err)
+		}
+
+		decoded, got, err := Decode(&buf)
+		if err != nil {
+			t.Fatalf("Decode(%s) error = %v", format, err)
+		}
+		if got != format || decoded.Bounds().Dx() != 20 {
+			t.Errorf("Decode(%s) = %s %v", format, got, decoded.Bounds())
+		}
+	}
+}
+
+func TestDecodeTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, newImage(1, 1, color.NRGBA{A: 255}), FormatPNG); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	// declare 60000x60000 pixels in the IHDR chunk and fix its checksum
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:20], 60000)
+	binary.BigEndian.PutUint32(data[20:24], 60000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	if _, _, err := Decode(bytes.NewReader(data)); err != ErrTooLarge {
+		t.Errorf("Decode(60000x60000) error = %v, want %v", err, ErrTooLarge)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 3x2 image with a red pixel at top-left
+	img := newImage(3, 2, color.NRGBA{A: 255})
+	img.(*image.NRGBA).Set(0, 0, color.NRGBA{R: 255, A: 255})
+
+	tests := []struct {
+		orientation int
+		w, h        int
+		x, y        int
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+	}
+	for _, tt := range tests {
+		dst := Orient(img, tt.orientation)
+		if b := dst.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("Orient(%d) size = %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+			continue
+		}
+		if c := color.NRGBAModel.Convert(dst.At(tt.x, tt.y)).(color.NRGBA); c.R != 255 {
+			t.Errorf("Orient(%d) red pixel is not at (%d, %d)", tt.orientation, tt.x, tt.y)
+		}
+	}
+}
+
+func TestVariantPath(t *testing.T) {
+	path := VariantPath("/uploads/2020/01/abc.jpg", FormatWebP)
+	if path != "/uploads/2020/01/abc.jpg.webp" {
+		t.Errorf("VariantPath() = %s", path)
+	}
+	if src := VariantSourcePath(path); src != "/uploads/2020/01/abc.jpg" {
+		t.Errorf("VariantSourcePath() = %s", src)
+	}
+	if src := VariantSourcePath("/uploads/2020/01/abc.jpg"); src != "" {
+		t.Errorf("VariantSourcePath() not variant = %s", src)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	if _, err := exec.LookPath("cp"); err != nil {
+		t.Skip("cp is not found")
+	}
+
+	data := []byte("image data")
+	result, err := Convert("cp {input} {output}", bytes.NewReader(data), ".jpg", FormatWebP)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if !bytes.Equal(result, data) {
+		t.Errorf("Convert() = %q, want %q", result, data)
+	}
+
+	if _, err := Convert("true {input}", bytes.NewReader(data), ".jpg", FormatWebP); err != ErrNoOutput {
+		t.Errorf("Convert() without output error = %v, want ErrNoOutput", err)
+	}
+	if _, err := Convert("false", bytes.NewReader(data), ".jpg", FormatWebP); err == nil {
+		t.Error("Convert() failed command error = nil")
+	}
+}
@@ -0,0 +1,112 @@
+package imaging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// variant formats which are converted from JPEG and PNG by external encoders
+const (
+	FormatWebP = "webp"
+	FormatAVIF = "avif"
+)
+
+// VariantFormats variant formats from the most preferred, since AVIF is usually smaller than WebP
+var VariantFormats = []string{FormatAVIF, FormatWebP}
+
+// convertTimeout max time of an encoder command
+const convertTimeout = time.Minute
+
+// ErrNoOutput the encoder command does not write the output file
+var ErrNoOutput = errors.New("imaging: encoder has no output")
+
+// IsVariantSource check if variants can be converted from the format
+// GIF is skipped since the encoders only convert the first frame of animation.
+func IsVariantSource(format string) bool {
+	return format == FormatJPEG || format == FormatPNG
+}
+
+// FormatOf the format of the image by the extension of its path, it returns an empty string if it is not supported
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		return FormatJPEG
+	case ".png":
+		return FormatPNG
+	case ".gif":
+		return FormatGIF
+	}
+	return ""
+}
+
+// VariantPath the path of variant, which is the source path with the format as an extra extension
+// such as /uploads/2020/01/abc.jpg.webp
+func VariantPath(path, format string) string {
+	return path + "." + format
+}
+
+// VariantSourcePath the source path of the variant path, it returns an empty string if the path is not a variant
+func VariantSourcePath(path string) string {
+	for _, format := range VariantFormats {
+		if strings.HasSuffix(path, "."+format) {
+			return strings.TrimSuffix(path, "."+format)
+		}
+	}
+	return ""
+}
+
+// Convert convert the file by the encoder command, such as "cwebp -quiet -q 80 {input} -o {output}"
+// {input} and {output} in the command are replaced by temporary files, and the output file is returned.
+func Convert(command string, r io.Reader, inputExt, format string) ([]byte, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("imaging: empty encoder command")
+	}
+
+	dir, err := ioutil.TempDir("", "puti-imaging")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input"+inputExt)
+	output := filepath.Join(dir, "output."+format)
+	if err := writeFile(input, r); err != nil {
+		return nil, err
+	}
+	for i, arg := range args {
+		arg = strings.Replace(arg, "{input}", input, -1)
+		args[i] = strings.Replace(arg, "{output}", output, -1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), convertTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput(); err != nil {
+		return nil, errors.New("imaging: " + err.Error() + ": " + strings.TrimSpace(string(out)))
+	}
+
+	result, err := ioutil.ReadFile(output)
+	if os.IsNotExist(err) || (err == nil && len(result) == 0) {
+		return nil, ErrNoOutput
+	}
+	return result, err
+}
+
+func writeFile(name string, r io.Reader) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
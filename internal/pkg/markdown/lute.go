@@ -1,26 +1,135 @@
 package markdown
 
 import (
+	"html"
+	"strings"
 	"sync"
 
 	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
 )
 
-var once sync.Once
+// Options features of the markdown engine which can be switched by site options
+type Options struct {
+	GFMTable      bool
+	Footnotes     bool
+	ToC           bool
+	Emoji         bool
+	Math          bool
+	Mermaid       bool
+	HeadingAnchor bool
+}
+
+// DefaultOptions the options used before the site options are applied
+func DefaultOptions() Options {
+	return Options{
+		GFMTable:  true,
+		Footnotes: true,
+		Emoji:     true,
+		Math:      true,
+		Mermaid:   true,
+	}
+}
+
+// mermaidLanguages languages of code blocks which are rendered as diagrams
+var mermaidLanguages = map[string]bool{
+	"mermaid":   true,
+	"flowchart": true,
+}
 
-var luteEngine *lute.Lute
+var (
+	engineLock    sync.RWMutex
+	engineOptions = DefaultOptions()
+	luteEngine    = newLuteEngine(engineOptions)
+)
+
+// newLuteEngine create the lute engine with the options
+func newLuteEngine(o Options) *lute.Lute {
+	engine := lute.New()
+	engine.SetGFMTable(o.GFMTable)
+	engine.SetFootnotes(o.Footnotes)
+	engine.SetToC(o.ToC)
+	engine.SetEmoji(o.Emoji)
+	engine.SetHeadingAnchor(o.HeadingAnchor)
+	return engine
+}
 
-func initLuteEngine() {
-	if luteEngine == nil {
-		once.Do(func() {
-			luteEngine = lute.New()
-		})
+// SetOptions rebuild the markdown engine with the options
+// It returns false if the options are not changed.
+func SetOptions(o Options) bool {
+	engineLock.Lock()
+	defer engineLock.Unlock()
+
+	if o == engineOptions {
+		return false
 	}
+	engineOptions = o
+	luteEngine = newLuteEngine(o)
+	return true
+}
+
+// GetOptions get the options of the markdown engine
+func GetOptions() Options {
+	engineLock.RLock()
+	defer engineLock.RUnlock()
+	return engineOptions
 }
 
-//Markdown2HTML convert markdown to HTML
+// Markdown2HTML convert markdown to HTML
+// the HTML is sanitized by the allowlist, so raw HTML in markdown can not inject scripts
 func Markdown2HTML(name, markdown string) string {
-	initLuteEngine()
-	html := luteEngine.MarkdownStr(name, markdown)
-	return html
+	engineLock.RLock()
+	engine, o := luteEngine, engineOptions
+	engineLock.RUnlock()
+
+	tree := parse.Parse(name, []byte(markdown), engine.Options)
+	renderer := render.NewHtmlRenderer(tree)
+	if !o.Math {
+		renderer.ExtRendererFuncs[ast.NodeInlineMath] = renderMathText("$", "$", ast.NodeInlineMathContent)
+		renderer.ExtRendererFuncs[ast.NodeMathBlock] = renderMathText("<p>$$\n", "\n$$</p>\n", ast.NodeMathBlockContent)
+	}
+	if o.Mermaid {
+		renderer.ExtRendererFuncs[ast.NodeCodeBlock] = renderMermaid(renderer.RendererFuncs[ast.NodeCodeBlock])
+	}
+
+	return Sanitize(string(renderer.Render()))
+}
+
+// renderMathText render the math as plain text with its delimiters, so it is not typeset by the theme
+func renderMathText(open, close string, contentType ast.NodeType) render.ExtRendererFunc {
+	return func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		if !entering {
+			return "", ast.WalkContinue
+		}
+
+		var text string
+		if content := n.ChildByType(contentType); content != nil {
+			text = html.EscapeString(string(content.Tokens))
+		}
+		return open + text + close, ast.WalkSkipChildren
+	}
+}
+
+// renderMermaid render mermaid and flowchart code blocks as diagram containers, and other code blocks by the default renderer
+func renderMermaid(renderCodeBlock render.RendererFunc) render.ExtRendererFunc {
+	return func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		language := ""
+		if info := strings.Fields(string(n.CodeBlockInfo)); len(info) > 0 {
+			language = strings.ToLower(info[0])
+		}
+		if !n.IsFencedCodeBlock || !mermaidLanguages[language] {
+			return "", renderCodeBlock(n, entering)
+		}
+		if !entering {
+			return "", ast.WalkContinue
+		}
+
+		var code string
+		if content := n.ChildByType(ast.NodeCodeBlockCode); content != nil {
+			code = html.EscapeString(string(content.Tokens))
+		}
+		return "<div class=\"language-" + language + "\">" + code + "</div>\n", ast.WalkSkipChildren
+	}
 }
@@ -0,0 +1,65 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdown2HTMLOptions(t *testing.T) {
+	defer SetOptions(DefaultOptions())
+
+	md := "$a<b$\n\n```mermaid\nA-->B\n```\n\n| a |\n|---|\n| 1 |\n"
+	tests := []struct {
+		options  Options
+		contains []string
+	}{
+		{
+			DefaultOptions(),
+			[]string{`<span class="vditor-math">a&lt;b</span>`, `<div class="language-mermaid">A--&gt;B`, `<table>`},
+		},
+		{
+			Options{},
+			[]string{`<p>$a&lt;b$</p>`, `<pre><code class="language-mermaid">A--&gt;B`, `<p>| a |`},
+		},
+	}
+
+	for _, tt := range tests {
+		SetOptions(tt.options)
+		got := Markdown2HTML("test", md)
+		for _, want := range tt.contains {
+			if !strings.Contains(got, want) {
+				t.Errorf("Markdown2HTML with %+v = %q, want containing %q", tt.options, got, want)
+			}
+		}
+	}
+}
+
+func TestSetOptions(t *testing.T) {
+	defer SetOptions(DefaultOptions())
+
+	o := DefaultOptions()
+	o.ToC = true
+	if !SetOptions(o) {
+		t.Errorf("SetOptions(%+v) = false, want true", o)
+	}
+	if SetOptions(o) {
+		t.Errorf("SetOptions(%+v) again = true, want false", o)
+	}
+	if got := GetOptions(); got != o {
+		t.Errorf("GetOptions() = %+v, want %+v", got, o)
+	}
+}
+
+func TestMarkdown2HTMLHeadingAnchor(t *testing.T) {
+	defer SetOptions(DefaultOptions())
+
+	o := DefaultOptions()
+	o.HeadingAnchor = true
+	SetOptions(o)
+	got := Markdown2HTML("test", "## Title\n")
+	for _, want := range []string{`<a id="vditorAnchor-Title" class="vditor-anchor" href="#Title"><svg viewbox="0 0 16 16"`, `<path fill-rule="evenodd" d="`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown2HTML with heading anchor = %q, want containing %q", got, want)
+		}
+	}
+}
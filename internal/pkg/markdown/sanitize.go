@@ -0,0 +1,277 @@
+package markdown
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+
+	"golang.org/x/net/html"
+)
+
+// defaultElements allowed elements and their attributes besides the global attributes
+// svg and path are for the icons of heading anchors.
+var defaultElements = map[string][]string{
+	"a":          {"href", "name", "target", "rel"},
+	"abbr":       {},
+	"audio":      {"src", "controls", "preload", "loop", "muted"},
+	"b":          {},
+	"blockquote": {"cite"},
+	"br":         {},
+	"code":       {},
+	"dd":         {},
+	"del":        {"cite", "datetime"},
+	"details":    {"open"},
+	"div":        {"align"},
+	"dl":         {},
+	"dt":         {},
+	"em":         {},
+	"figcaption": {},
+	"figure":     {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src", "alt", "width", "height", "loading"},
+	"input":      {"type", "checked", "disabled"},
+	"ins":        {"cite", "datetime"},
+	"kbd":        {},
+	"li":         {"value"},
+	"mark":       {},
+	"ol":         {"start", "type", "reversed"},
+	"p":          {"align"},
+	"path":       {"d", "fill-rule"},
+	"pre":        {},
+	"q":          {"cite"},
+	"s":          {},
+	"samp":       {},
+	"small":      {},
+	"source":     {"src", "type"},
+	"span":       {},
+	"strike":     {},
+	"strong":     {},
+	"sub":        {},
+	"summary":    {},
+	"sup":        {},
+	"svg":        {"viewbox", "version", "width", "height"},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"align", "colspan", "rowspan"},
+	"tfoot":      {},
+	"th":         {"align", "colspan", "rowspan", "scope"},
+	"thead":      {},
+	"tr":         {},
+	"u":          {},
+	"ul":         {},
+	"var":        {},
+	"video":      {"src", "poster", "controls", "width", "height", "preload", "loop", "muted", "playsinline"},
+}
+
+// defaultAttributes attributes allowed on all elements
+var defaultAttributes = []string{"id", "class", "title", "lang", "dir"}
+
+// defaultURLSchemes schemes allowed in url attributes
+var defaultURLSchemes = []string{"http", "https", "mailto", "tel"}
+
+// urlAttributes attributes whose value is an url
+var urlAttributes = map[string]bool{
+	"href":   true,
+	"src":    true,
+	"cite":   true,
+	"poster": true,
+}
+
+// skipContentElements elements which are removed together with their content if they are not allowed
+var skipContentElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"noscript": true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+	"select":   true,
+}
+
+// voidElements elements which have no end tag
+var voidElements = map[string]bool{
+	"area":   true,
+	"br":     true,
+	"col":    true,
+	"embed":  true,
+	"hr":     true,
+	"img":    true,
+	"input":  true,
+	"source": true,
+	"track":  true,
+	"wbr":    true,
+}
+
+// Policy allowlist of HTML sanitizing
+type Policy struct {
+	elements   map[string]map[string]bool
+	attributes map[string]bool
+	schemes    map[string]bool
+}
+
+// NewPolicy build the policy from the config; the default allowlist is used for the items which are not configured
+func NewPolicy(cfg *config.SanitizeConfig) *Policy {
+	elements, attributes, schemes := defaultElements, defaultAttributes, defaultURLSchemes
+	if cfg != nil {
+		if len(cfg.Elements) != 0 {
+			elements = cfg.Elements
+		}
+		if cfg.Attributes != nil {
+			attributes = cfg.Attributes
+		}
+		if len(cfg.URLSchemes) != 0 {
+			schemes = cfg.URLSchemes
+		}
+	}
+
+	p := &Policy{
+		elements:   make(map[string]map[string]bool, len(elements)),
+		attributes: make(map[string]bool, len(attributes)),
+		schemes:    make(map[string]bool, len(schemes)),
+	}
+	for name, attrs := range elements {
+		allowed := make(map[string]bool, len(attrs))
+		for _, attr := range attrs {
+			allowed[strings.ToLower(attr)] = true
+		}
+		p.elements[strings.ToLower(name)] = allowed
+	}
+	for _, attr := range attributes {
+		p.attributes[strings.ToLower(attr)] = true
+	}
+	for _, scheme := range schemes {
+		p.schemes[strings.ToLower(scheme)] = true
+	}
+	return p
+}
+
+// Sanitize remove the elements, attributes and urls which are not allowed from the HTML
+// Text of removed elements is kept, except the elements such as script and style.
+// End tags without a matching start tag are removed, and the elements which are still open at the end are closed,
+// so the result can not break the page it is put in.
+func (p *Policy) Sanitize(s string) string {
+	var buf bytes.Buffer
+	z := html.NewTokenizer(strings.NewReader(s))
+	skip := ""
+	depth := 0
+	// open allowed elements, from the outermost
+	var open []string
+	closeTag := func(name string) {
+		buf.WriteString("</")
+		buf.WriteString(name)
+		buf.WriteByte('>')
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// io.EOF or the content is too long to tokenize
+			for i := len(open) - 1; i >= 0; i-- {
+				closeTag(open[i])
+			}
+			return buf.String()
+		}
+
+		token := z.Token()
+		name := token.Data
+
+		// drop everything inside the removed script, style and so on
+		if skip != "" {
+			switch {
+			case tt == html.StartTagToken && name == skip:
+				depth++
+			case tt == html.EndTagToken && name == skip:
+				depth--
+				if depth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			buf.WriteString(html.EscapeString(token.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			allowed, ok := p.elements[name]
+			if !ok {
+				if tt == html.StartTagToken && skipContentElements[name] {
+					skip, depth = name, 1
+				}
+				continue
+			}
+			buf.WriteByte('<')
+			buf.WriteString(name)
+			for _, attr := range token.Attr {
+				key := strings.ToLower(attr.Key)
+				if attr.Namespace != "" || (!allowed[key] && !p.attributes[key]) {
+					continue
+				}
+				if urlAttributes[key] && !p.allowURL(attr.Val) {
+					continue
+				}
+				buf.WriteByte(' ')
+				buf.WriteString(key)
+				buf.WriteString(`="`)
+				buf.WriteString(html.EscapeString(attr.Val))
+				buf.WriteByte('"')
+			}
+			switch {
+			case voidElements[name]:
+				if tt == html.SelfClosingTagToken {
+					buf.WriteString(" /")
+				}
+				buf.WriteByte('>')
+			case tt == html.SelfClosingTagToken:
+				buf.WriteByte('>')
+				closeTag(name)
+			default:
+				buf.WriteByte('>')
+				open = append(open, name)
+			}
+		case html.EndTagToken:
+			// close the elements opened inside the matching one as well
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != name {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					closeTag(open[j])
+				}
+				open = open[:i]
+				break
+			}
+		}
+		// comments and doctypes are removed
+	}
+}
+
+// allowURL check if the url is relative or its scheme is allowed
+func (p *Policy) allowURL(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || p.schemes[strings.ToLower(u.Scheme)]
+}
+
+// Sanitize remove the elements, attributes and urls which are not allowed by the configured allowlist from the HTML
+func Sanitize(s string) string {
+	var cfg *config.SanitizeConfig
+	if config.Markdown != nil {
+		cfg = &config.Markdown.Sanitize
+	}
+	return NewPolicy(cfg).Sanitize(s)
+}
@@ -0,0 +1,51 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+)
+
+func TestSanitize(t *testing.T) {
+	p := NewPolicy(nil)
+	tests := []struct {
+		in, want string
+	}{
+		{`<p class="a" style="color:red" onclick="x()">text</p>`, `<p class="a">text</p>`},
+		{`<p>a<script>alert("<p>")</script>b</p>`, `<p>ab</p>`},
+		{`<object data="/a.swf"><object></object>x</object>y`, `y`},
+		{`<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="&#106;avascript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="java&#9;script:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="/about" target="_blank">x</a>`, `<a href="/about" target="_blank">x</a>`},
+		{`<a href="mailto:a@b.c">x</a>`, `<a href="mailto:a@b.c">x</a>`},
+		{`<img src="/a.jpg" alt="&quot;a&quot;" onerror="x()" />`, `<img src="/a.jpg" alt="&#34;a&#34;" />`},
+		{`<font color="red">1 &lt; 2</font><!-- c -->`, `1 &lt; 2`},
+		{`<svg><script>x</script></svg>t`, `<svg></svg>t`},
+		{`<svg viewBox="0 0 16 16" onload="x()"><path fill-rule="evenodd" d="M4 9h1"></path></svg>`, `<svg viewbox="0 0 16 16"><path fill-rule="evenodd" d="M4 9h1"></path></svg>`},
+		{`<div>unclosed`, `<div>unclosed</div>`},
+		{`</div></p>text</em>`, `text`},
+		{`<p><em>a</p>b</em>`, `<p><em>a</em></p>b`},
+		{`<p>a<br>b<hr/><div/>c`, `<p>a<br>b<hr /><div></div>c</p>`},
+	}
+
+	for _, tt := range tests {
+		if got := p.Sanitize(tt.in); got != tt.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	p := NewPolicy(&config.SanitizeConfig{
+		Elements:   map[string][]string{"iframe": {"src"}, "p": {}},
+		Attributes: []string{},
+		URLSchemes: []string{"https"},
+	})
+
+	in := `<p id="a"><iframe src="https://example.com/v"></iframe><iframe src="http://example.com/v"></iframe><em>x</em></p>`
+	want := `<p><iframe src="https://example.com/v"></iframe><iframe></iframe>x</p>`
+	if got := p.Sanitize(in); got != want {
+		t.Errorf("Sanitize() = %q, want %q", got, want)
+	}
+}
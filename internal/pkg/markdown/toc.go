@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Heading node of the table of contents, headings of lower levels are in children
+type Heading struct {
+	Level    int
+	Text     string
+	ID       string
+	Children []*Heading
+}
+
+// headingLevels levels of the heading elements
+var headingLevels = map[string]int{
+	"h1": 1,
+	"h2": 2,
+	"h3": 3,
+	"h4": 4,
+	"h5": 5,
+	"h6": 6,
+}
+
+// ToC extract the heading tree from the rendered HTML
+// Headings without an id can not be linked to, so they are not included.
+func ToC(s string) []*Heading {
+	toc := make([]*Heading, 0)
+	// parents of the next heading, from the top level
+	var parents []*Heading
+	var current *Heading
+	var text strings.Builder
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return toc
+		}
+
+		token := z.Token()
+		switch tt {
+		case html.StartTagToken:
+			level, ok := headingLevels[token.Data]
+			if !ok || current != nil {
+				continue
+			}
+			for _, attr := range token.Attr {
+				if attr.Key == "id" && attr.Val != "" {
+					current = &Heading{Level: level, ID: attr.Val, Children: make([]*Heading, 0)}
+					text.Reset()
+					break
+				}
+			}
+		case html.TextToken:
+			if current != nil {
+				text.WriteString(token.Data)
+			}
+		case html.EndTagToken:
+			if current == nil || headingLevels[token.Data] != current.Level {
+				continue
+			}
+			current.Text = strings.Join(strings.Fields(text.String()), " ")
+
+			for len(parents) > 0 && parents[len(parents)-1].Level >= current.Level {
+				parents = parents[:len(parents)-1]
+			}
+			if len(parents) == 0 {
+				toc = append(toc, current)
+			} else {
+				parent := parents[len(parents)-1]
+				parent.Children = append(parent.Children, current)
+			}
+			parents = append(parents, current)
+			current = nil
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func formatToC(toc []*Heading, depth int, b *strings.Builder) {
+	for _, h := range toc {
+		fmt.Fprintf(b, "%s%d %s #%s\n", strings.Repeat(" ", depth), h.Level, h.Text, h.ID)
+		formatToC(h.Children, depth+1, b)
+	}
+}
+
+func TestToC(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{``, ``},
+		{`<p>text</p><h2>no id</h2>`, ``},
+		{
+			`<h1 id="a">A</h1><h2 id="b">B <code>x</code></h2><h3 id="c">C &amp; D</h3><h2 id="e">E</h2><h1 id="f">F</h1>`,
+			"1 A #a\n 2 B x #b\n  3 C & D #c\n 2 E #e\n1 F #f\n",
+		},
+		{
+			`<h3 id="a">A</h3><h2 id="b">B</h2><h4 id="c">C<a class="vditor-anchor" href="#c"></a></h4>`,
+			"3 A #a\n2 B #b\n 4 C #c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var b strings.Builder
+		formatToC(ToC(tt.in), 0, &b)
+		if got := b.String(); got != tt.want {
+			t.Errorf("ToC(%q) =\n%s\nwant\n%s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// mp3 tables of MPEG audio layer III, indexed by the version: MPEG1, MPEG2 and MPEG2.5
+var (
+	mp3Bitrates = [2][15]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	mp3SampleRates = [3][3]int{
+		{44100, 48000, 32000},
+		{22050, 24000, 16000},
+		{11025, 12000, 8000},
+	}
+)
+
+// probeMP3 read the duration by the frame count in Xing or VBRI header, or by the bitrate of CBR file
+func probeMP3(r io.ReaderAt, size int64) (*Info, error) {
+	// skip ID3v2 tag
+	var start int64
+	head, err := readAt(r, 0, 10)
+	if err != nil {
+		return nil, err
+	}
+	if string(head[:3]) == "ID3" {
+		start = 10 + int64(head[6]&0x7F)<<21 | int64(head[7]&0x7F)<<14 | int64(head[8]&0x7F)<<7 | int64(head[9]&0x7F)
+		if head[5]&0x10 != 0 {
+			start += 10
+		}
+	}
+	if start >= size {
+		return nil, ErrFormat
+	}
+
+	buf, err := readAt(r, start, int(min64(size-start, 8192)))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xFF || buf[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+
+		h := binary.BigEndian.Uint32(buf[i:])
+		version := (h >> 19) & 3 // 0: MPEG2.5, 2: MPEG2, 3: MPEG1
+		layer := (h >> 17) & 3   // 1: layer III
+		bitrateIndex := (h >> 12) & 0xF
+		sampleRateIndex := (h >> 10) & 3
+		if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+			continue
+		}
+
+		mpeg1 := version == 3
+		mono := (h>>6)&3 == 3
+		var bitrate, sampleRate, samplesPerFrame, sideInfo int
+		if mpeg1 {
+			bitrate = mp3Bitrates[0][bitrateIndex] * 1000
+			sampleRate = mp3SampleRates[0][sampleRateIndex]
+			samplesPerFrame, sideInfo = 1152, 32
+			if mono {
+				sideInfo = 17
+			}
+		} else {
+			bitrate = mp3Bitrates[1][bitrateIndex] * 1000
+			sampleRate = mp3SampleRates[2-version/2][sampleRateIndex]
+			samplesPerFrame, sideInfo = 576, 17
+			if mono {
+				sideInfo = 9
+			}
+		}
+
+		// frame count of VBR file
+		frame := buf[i:]
+		if o := 4 + sideInfo; len(frame) >= o+12 && (string(frame[o:o+4]) == "Xing" || string(frame[o:o+4]) == "Info") {
+			if binary.BigEndian.Uint32(frame[o+4:])&1 != 0 {
+				frames := binary.BigEndian.Uint32(frame[o+8:])
+				return &Info{Duration: float64(frames) * float64(samplesPerFrame) / float64(sampleRate)}, nil
+			}
+		}
+		if o := 4 + 32; len(frame) >= o+18 && string(frame[o:o+4]) == "VBRI" {
+			frames := binary.BigEndian.Uint32(frame[o+14:])
+			return &Info{Duration: float64(frames) * float64(samplesPerFrame) / float64(sampleRate)}, nil
+		}
+
+		// constant bitrate
+		audioSize := size - start - int64(i)
+		if tail, err := readTail(r, size, 128); err == nil && string(tail[:3]) == "TAG" {
+			audioSize -= 128
+		}
+		return &Info{Duration: float64(audioSize) * 8 / float64(bitrate)}, nil
+	}
+
+	return nil, ErrFormat
+}
+
+// probeWAV read the duration by the byte rate and the size of data chunk
+func probeWAV(r io.ReaderAt, size int64) (*Info, error) {
+	head, err := readAt(r, 0, 12)
+	if err != nil {
+		return nil, err
+	}
+	if string(head[:4]) != "RIFF" || string(head[8:12]) != "WAVE" {
+		return nil, ErrFormat
+	}
+
+	var byteRate uint32
+	for offset := int64(12); offset+8 <= size; {
+		chunk, err := readAt(r, offset, 8)
+		if err != nil {
+			return nil, err
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(chunk[4:]))
+
+		switch string(chunk[:4]) {
+		case "fmt ":
+			fmtChunk, err := readAt(r, offset+8, 12)
+			if err != nil {
+				return nil, err
+			}
+			byteRate = binary.LittleEndian.Uint32(fmtChunk[8:])
+		case "data":
+			if byteRate == 0 {
+				return nil, ErrFormat
+			}
+			// the size may be wrong in streamed file
+			chunkSize = min64(chunkSize, size-offset-8)
+			return &Info{Duration: float64(chunkSize) / float64(byteRate)}, nil
+		}
+		offset += 8 + chunkSize + chunkSize&1
+	}
+
+	return nil, ErrFormat
+}
+
+// probeOgg read the duration by the granule position of the last page and the sample rate of Vorbis or Opus
+func probeOgg(r io.ReaderAt, size int64) (*Info, error) {
+	head, err := readAt(r, 0, int(min64(size, 512)))
+	if err != nil {
+		return nil, err
+	}
+	if len(head) < 28 || string(head[:4]) != "OggS" || 27+int(head[26]) > len(head) {
+		return nil, ErrFormat
+	}
+	packet := head[27+int(head[26]):]
+
+	var sampleRate, preSkip uint64
+	switch {
+	case len(packet) >= 16 && string(packet[:7]) == "\x01vorbis":
+		sampleRate = uint64(binary.LittleEndian.Uint32(packet[12:]))
+	case len(packet) >= 12 && string(packet[:8]) == "OpusHead":
+		// the granule position of opus is always in 48kHz
+		sampleRate = 48000
+		preSkip = uint64(binary.LittleEndian.Uint16(packet[10:]))
+	default:
+		return nil, ErrFormat
+	}
+	if sampleRate == 0 {
+		return nil, ErrFormat
+	}
+
+	tail, err := readTail(r, size, 65536)
+	if err != nil {
+		return nil, err
+	}
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		if i+14 > len(tail) {
+			continue
+		}
+		granule := binary.LittleEndian.Uint64(tail[i+6:])
+		// -1 means no packet ends in the page
+		if granule == ^uint64(0) {
+			continue
+		}
+		if granule < preSkip {
+			granule = preSkip
+		}
+		return &Info{Duration: float64(granule-preSkip) / float64(sampleRate)}, nil
+	}
+
+	return nil, ErrFormat
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,50 @@
+package mediainfo
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// maxPDFSize max size of PDF which is read for the page count
+const maxPDFSize = 64 << 20
+
+var (
+	pdfPagesRegexp = regexp.MustCompile(`/Type\s*/Pages\b`)
+	pdfCountRegexp = regexp.MustCompile(`/Count\s+(\d+)`)
+	pdfPageRegexp  = regexp.MustCompile(`/Type\s*/Page\b`)
+)
+
+// probePDF read the page count by the /Count of the page tree root, which is the largest count of the page tree nodes
+// If the page tree is in compressed object streams, the count is zero.
+func probePDF(r io.ReaderAt, size int64) (*Info, error) {
+	if size > maxPDFSize {
+		return &Info{}, nil
+	}
+	data, err := readAt(r, 0, int(size))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, ErrFormat
+	}
+
+	info := &Info{}
+	for _, obj := range bytes.Split(data, []byte("endobj")) {
+		if !pdfPagesRegexp.Match(obj) {
+			continue
+		}
+		for _, m := range pdfCountRegexp.FindAllSubmatch(obj, -1) {
+			if count, err := strconv.Atoi(string(m[1])); err == nil && count > info.Pages {
+				info.Pages = count
+			}
+		}
+	}
+
+	// count the page objects if the page tree is broken
+	if info.Pages == 0 {
+		info.Pages = len(pdfPageRegexp.FindAll(data, -1))
+	}
+	return info, nil
+}
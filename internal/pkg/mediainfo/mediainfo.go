@@ -0,0 +1,76 @@
+// Package mediainfo read the duration, dimensions and page count of audio, video and document files
+// The container headers are parsed without decoding, so it is fast and does not need external tools.
+package mediainfo
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrFormat the format is not supported or the file is broken
+var ErrFormat = errors.New("mediainfo: unsupported format")
+
+// Info media info, fields which can not be read are zero
+type Info struct {
+	// Duration in seconds of audio and video
+	Duration float64
+	// Width and Height of video
+	Width  int
+	Height int
+	// Pages page count of document
+	Pages int
+}
+
+// probers by mime-type
+var probers = map[string]func(r io.ReaderAt, size int64) (*Info, error){
+	"audio/mpeg":      probeMP3,
+	"audio/wav":       probeWAV,
+	"audio/ogg":       probeOgg,
+	"video/mp4":       probeMP4,
+	"video/3gpp":      probeMP4,
+	"video/webm":      probeWebM,
+	"video/x-msvideo": probeAVI,
+	"application/pdf": probePDF,
+}
+
+// IsSupported check if the info of the mime-type can be read
+func IsSupported(mimeType string) bool {
+	_, ok := probers[mimeType]
+	return ok
+}
+
+// Probe read the info of the file by its mime-type
+// The probers check the bounds of the headers; a broken file which is missed by them is reported as ErrFormat instead of panic.
+func Probe(r io.ReaderAt, size int64, mimeType string) (info *Info, err error) {
+	probe, ok := probers[mimeType]
+	if !ok {
+		return nil, ErrFormat
+	}
+
+	defer func() {
+		if recover() != nil {
+			info, err = nil, ErrFormat
+		}
+	}()
+	return probe(r, size)
+}
+
+// readAt read n bytes at offset, it returns ErrFormat if the file is too short
+func readAt(r io.ReaderAt, offset int64, n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := r.ReadAt(b, offset); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrFormat
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// readTail read at most n bytes at the end of file
+func readTail(r io.ReaderAt, size int64, n int) ([]byte, error) {
+	if int64(n) > size {
+		n = int(size)
+	}
+	return readAt(r, size-int64(n), n)
+}
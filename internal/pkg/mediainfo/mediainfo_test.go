@@ -0,0 +1,218 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func probe(t *testing.T, data []byte, mimeType string) *Info {
+	t.Helper()
+	info, err := Probe(bytes.NewReader(data), int64(len(data)), mimeType)
+	if err != nil {
+		t.Fatalf("Probe(%s) error = %v", mimeType, err)
+	}
+	return info
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func le32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func be32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+func TestProbeWAV(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	buf.Write(le32(36 + 16000))
+	buf.WriteString("WAVEfmt ")
+	buf.Write(le32(16))
+	buf.Write([]byte{1, 0, 1, 0})
+	buf.Write(le32(8000)) // sample rate
+	buf.Write(le32(8000)) // byte rate
+	buf.Write([]byte{1, 0, 8, 0})
+	buf.WriteString("data")
+	buf.Write(le32(16000))
+	buf.Write(make([]byte, 16000))
+
+	if info := probe(t, buf.Bytes(), "audio/wav"); !almostEqual(info.Duration, 2) {
+		t.Errorf("Duration = %v, want 2", info.Duration)
+	}
+}
+
+func TestProbeMP3(t *testing.T) {
+	// MPEG1 layer III, 128kbps, 44.1kHz, stereo; 417 bytes per frame
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+
+	var buf bytes.Buffer
+	// ID3v2 tag of 10 bytes data
+	buf.Write([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 10})
+	buf.Write(make([]byte, 10))
+	for i := 0; i < 100; i++ {
+		buf.Write(frame)
+	}
+
+	info := probe(t, buf.Bytes(), "audio/mpeg")
+	if want := float64(417*100*8) / 128000; !almostEqual(info.Duration, want) {
+		t.Errorf("CBR Duration = %v, want %v", info.Duration, want)
+	}
+
+	// Xing header with 1000 frames
+	xing := make([]byte, 417)
+	copy(xing, []byte{0xFF, 0xFB, 0x90, 0x00})
+	copy(xing[36:], "Xing")
+	copy(xing[40:], be32(1))
+	copy(xing[44:], be32(1000))
+	if info := probe(t, xing, "audio/mpeg"); !almostEqual(info.Duration, 1000*1152/44100.0) {
+		t.Errorf("VBR Duration = %v, want %v", info.Duration, 1000*1152/44100.0)
+	}
+}
+
+func box(typ string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	return append(append(be32(uint32(8+len(data))), typ...), data...)
+}
+
+func TestProbeMP4(t *testing.T) {
+	mvhd := make([]byte, 100)
+	copy(mvhd[12:], be32(1000))  // timescale
+	copy(mvhd[16:], be32(12500)) // duration
+
+	audio := make([]byte, 84)
+	video := make([]byte, 84)
+	copy(video[76:], be32(1280<<16))
+	copy(video[80:], be32(720<<16))
+
+	data := bytes.Join([][]byte{
+		box("ftyp", []byte("isom")),
+		box("moov",
+			box("mvhd", mvhd),
+			box("trak", box("tkhd", audio)),
+			box("trak", box("tkhd", video)),
+		),
+		box("mdat", make([]byte, 64)),
+	}, nil)
+
+	info := probe(t, data, "video/mp4")
+	if !almostEqual(info.Duration, 12.5) || info.Width != 1280 || info.Height != 720 {
+		t.Errorf("Probe() = %+v, want 12.5s 1280x720", info)
+	}
+}
+
+func ebml(id []byte, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	// 8 bytes size
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(data)))
+	size[0] = 0x01
+	return append(append(append([]byte{}, id...), size...), data...)
+}
+
+func TestProbeWebM(t *testing.T) {
+	duration := make([]byte, 8)
+	binary.BigEndian.PutUint64(duration, math.Float64bits(3500))
+
+	data := bytes.Join([][]byte{
+		ebml([]byte{0x1A, 0x45, 0xDF, 0xA3}, ebml([]byte{0x42, 0x82}, []byte("webm"))),
+		ebml([]byte{0x18, 0x53, 0x80, 0x67},
+			ebml([]byte{0x15, 0x49, 0xA9, 0x66},
+				ebml([]byte{0x2A, 0xD7, 0xB1}, []byte{0x0F, 0x42, 0x40}),
+				ebml([]byte{0x44, 0x89}, duration),
+			),
+			ebml([]byte{0x16, 0x54, 0xAE, 0x6B},
+				ebml([]byte{0xAE}, ebml([]byte{0xE1}, ebml([]byte{0x9F}, []byte{2}))),
+				ebml([]byte{0xAE}, ebml([]byte{0xE0},
+					ebml([]byte{0xB0}, []byte{0x02, 0x80}),
+					ebml([]byte{0xBA}, []byte{0x01, 0xE0}),
+				)),
+			),
+			ebml([]byte{0x1F, 0x43, 0xB6, 0x75}, make([]byte, 16)),
+		),
+	}, nil)
+
+	info := probe(t, data, "video/webm")
+	if !almostEqual(info.Duration, 3.5) || info.Width != 640 || info.Height != 480 {
+		t.Errorf("Probe() = %+v, want 3.5s 640x480", info)
+	}
+}
+
+func TestProbePDF(t *testing.T) {
+	pdf := "%PDF-1.4\n" +
+		"1 0 obj << /Type /Catalog /Pages 2 0 R /Outlines 5 0 R >> endobj\n" +
+		"2 0 obj << /Kids [3 0 R 4 0 R] /Type /Pages /Count 12 >> endobj\n" +
+		"3 0 obj << /Type /Pages /Parent 2 0 R /Count 5 >> endobj\n" +
+		"5 0 obj << /Type /Outlines /Count 30 >> endobj\n"
+	if info := probe(t, []byte(pdf), "application/pdf"); info.Pages != 12 {
+		t.Errorf("Pages = %d, want 12", info.Pages)
+	}
+
+	if _, err := Probe(bytes.NewReader([]byte("not pdf")), 7, "application/pdf"); err != ErrFormat {
+		t.Errorf("Probe() invalid error = %v, want ErrFormat", err)
+	}
+}
+
+// probeDirect call the prober without the recover of Probe, so a panic fails the test
+func probeDirect(data []byte, mimeType string) (*Info, error) {
+	return probers[mimeType](bytes.NewReader(data), int64(len(data)))
+}
+
+func TestProbeBroken(t *testing.T) {
+	ogg := append([]byte("OggS"), make([]byte, 24)...)
+	ogg[26] = 200
+
+	tests := []struct {
+		name     string
+		mimeType string
+		data     []byte
+	}{
+		{"empty mvhd", "video/mp4", box("moov", box("mvhd"), box("free"))},
+		{"empty tkhd", "video/mp4", box("moov", box("trak", box("tkhd"), box("free")))},
+		{"huge box", "video/mp4", append(be32(1), append([]byte("moov"), 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)...)},
+		{"ID3 size out of file", "audio/mpeg", []byte{'I', 'D', '3', 3, 0, 0, 0x7F, 0x7F, 0x7F, 0x7F}},
+		{"ogg segments out of page", "audio/ogg", ogg},
+		{"huge webm duration", "video/webm", ebml([]byte{0x18, 0x53, 0x80, 0x67},
+			ebml([]byte{0x15, 0x49, 0xA9, 0x66}, ebml([]byte{0x44, 0x89}, make([]byte, 64))))},
+	}
+
+	for _, tt := range tests {
+		if _, err := probeDirect(tt.data, tt.mimeType); err != nil && err != ErrFormat {
+			t.Errorf("probe %s error = %v, want nil or ErrFormat", tt.name, err)
+		}
+	}
+}
+
+func TestProbeTruncated(t *testing.T) {
+	mvhd := make([]byte, 100)
+	copy(mvhd[12:], be32(1000))
+	mvhd[0] = 1
+	samples := map[string][]byte{
+		"video/mp4": box("moov", box("mvhd", mvhd), box("trak", box("tkhd", make([]byte, 92)))),
+		"video/webm": ebml([]byte{0x18, 0x53, 0x80, 0x67},
+			ebml([]byte{0x16, 0x54, 0xAE, 0x6B}, ebml([]byte{0xAE}, ebml([]byte{0xE0}, ebml([]byte{0xB0}, []byte{0x02, 0x80}))))),
+		"video/x-msvideo": append([]byte("RIFF\x00\x00\x00\x00AVI LIST\x00\x00\x00\x00hdrlavih"), make([]byte, 64)...),
+		"audio/mpeg":      append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 2, 0, 0, 0xFF, 0xFB, 0x90, 0x00}, "\x00\x00\x00\x00Xing"...),
+		"audio/wav":       append([]byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00\x00\x00"), make([]byte, 16)...),
+		"audio/ogg":       append(append([]byte("OggS"), make([]byte, 22)...), 1, 30, 0x01, 'v', 'o', 'r', 'b', 'i', 's'),
+		"application/pdf": []byte("%PDF-1.4\n2 0 obj << /Type /Pages /Count 3 >> endobj\n"),
+	}
+
+	for mimeType, data := range samples {
+		for n := 0; n <= len(data); n++ {
+			if _, err := probeDirect(data[:n], mimeType); err != nil && err != ErrFormat {
+				t.Errorf("probe %s truncated to %d bytes error = %v", mimeType, n, err)
+			}
+		}
+	}
+}
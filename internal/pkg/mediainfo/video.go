@@ -0,0 +1,331 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// mp4Box a box of ISO base media file, the payload is from start to end
+type mp4Box struct {
+	typ   string
+	start int64
+	end   int64
+}
+
+// mp4Boxes read the child boxes in the range
+func mp4Boxes(r io.ReaderAt, start, end int64) ([]*mp4Box, error) {
+	var boxes []*mp4Box
+	for offset := start; offset+8 <= end; {
+		head, err := readAt(r, offset, 8)
+		if err != nil {
+			return nil, err
+		}
+
+		size := int64(binary.BigEndian.Uint32(head))
+		headSize := int64(8)
+		switch size {
+		case 0:
+			// the box extends to the end
+			size = end - offset
+		case 1:
+			large, err := readAt(r, offset+8, 8)
+			if err != nil {
+				return nil, err
+			}
+			size = int64(binary.BigEndian.Uint64(large))
+			headSize = 16
+		}
+		if size < headSize || size > end-offset {
+			return nil, ErrFormat
+		}
+
+		boxes = append(boxes, &mp4Box{typ: string(head[4:8]), start: offset + headSize, end: offset + size})
+		offset += size
+	}
+	return boxes, nil
+}
+
+func findMP4Box(boxes []*mp4Box, typ string) *mp4Box {
+	for _, box := range boxes {
+		if box.typ == typ {
+			return box
+		}
+	}
+	return nil
+}
+
+// probeMP4 read the duration in mvhd and the dimensions in tkhd of the video track
+func probeMP4(r io.ReaderAt, size int64) (*Info, error) {
+	boxes, err := mp4Boxes(r, 0, size)
+	if err != nil {
+		return nil, err
+	}
+	moov := findMP4Box(boxes, "moov")
+	if moov == nil {
+		return nil, ErrFormat
+	}
+	children, err := mp4Boxes(r, moov.start, moov.end)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{}
+	if mvhd := findMP4Box(children, "mvhd"); mvhd != nil {
+		b, err := readAt(r, mvhd.start, int(min64(mvhd.end-mvhd.start, 32)))
+		if err != nil {
+			return nil, err
+		}
+		var timescale, duration uint64
+		switch {
+		case len(b) >= 32 && b[0] == 1:
+			timescale, duration = uint64(binary.BigEndian.Uint32(b[20:])), binary.BigEndian.Uint64(b[24:])
+		case len(b) >= 20 && b[0] == 0:
+			timescale, duration = uint64(binary.BigEndian.Uint32(b[12:])), uint64(binary.BigEndian.Uint32(b[16:]))
+		}
+		if timescale > 0 {
+			info.Duration = float64(duration) / float64(timescale)
+		}
+	}
+
+	for _, trak := range children {
+		if trak.typ != "trak" {
+			continue
+		}
+		trakChildren, err := mp4Boxes(r, trak.start, trak.end)
+		if err != nil {
+			return nil, err
+		}
+		tkhd := findMP4Box(trakChildren, "tkhd")
+		if tkhd == nil {
+			continue
+		}
+
+		b, err := readAt(r, tkhd.start, int(min64(tkhd.end-tkhd.start, 92)))
+		if err != nil {
+			return nil, err
+		}
+		if len(b) == 0 {
+			continue
+		}
+		// width and height are 16.16 fixed-point numbers after the matrix; they are zero in audio tracks
+		offset := 76
+		if b[0] == 1 {
+			offset = 88
+		}
+		if len(b) < offset+8 {
+			continue
+		}
+		width, height := int(binary.BigEndian.Uint32(b[offset:])>>16), int(binary.BigEndian.Uint32(b[offset+4:])>>16)
+		if width > 0 && height > 0 {
+			info.Width, info.Height = width, height
+			break
+		}
+	}
+
+	return info, nil
+}
+
+// EBML element ids of Matroska and WebM
+const (
+	ebmlIDSegment       = 0x18538067
+	ebmlIDInfo          = 0x1549A966
+	ebmlIDTimecodeScale = 0x2AD7B1
+	ebmlIDDuration      = 0x4489
+	ebmlIDTracks        = 0x1654AE6B
+	ebmlIDTrackEntry    = 0xAE
+	ebmlIDVideo         = 0xE0
+	ebmlIDPixelWidth    = 0xB0
+	ebmlIDPixelHeight   = 0xBA
+	ebmlIDCluster       = 0x1F43B675
+)
+
+// ebmlElement an EBML element, the data is from start to end
+type ebmlElement struct {
+	id    uint64
+	start int64
+	end   int64
+}
+
+// readVint read the variable size integer, the length marker is kept in ids and removed in sizes
+func readVint(r io.ReaderAt, offset int64, keepMarker bool) (uint64, int, error) {
+	first, err := readAt(r, offset, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	length := 1
+	for mask := byte(0x80); length <= 8 && first[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 {
+		return 0, 0, ErrFormat
+	}
+
+	b, err := readAt(r, offset, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	value := uint64(b[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> uint(length))
+	}
+	for _, c := range b[1:] {
+		value = value<<8 | uint64(c)
+	}
+	return value, length, nil
+}
+
+// ebmlElements read the child elements in the range, until the element with the stop id
+func ebmlElements(r io.ReaderAt, start, end int64, stop uint64) ([]*ebmlElement, error) {
+	var elements []*ebmlElement
+	for offset := start; offset < end; {
+		id, idLen, err := readVint(r, offset, true)
+		if err != nil {
+			return nil, err
+		}
+		if id == stop {
+			break
+		}
+		size, sizeLen, err := readVint(r, offset+int64(idLen), false)
+		if err != nil {
+			return nil, err
+		}
+
+		dataStart := offset + int64(idLen+sizeLen)
+		dataEnd := end
+		// all ones means the size is unknown, the element extends to the end of its parent
+		if size != 1<<(7*uint(sizeLen))-1 {
+			dataEnd = dataStart + int64(size)
+		}
+		if dataEnd > end || dataEnd < dataStart {
+			return nil, ErrFormat
+		}
+
+		elements = append(elements, &ebmlElement{id: id, start: dataStart, end: dataEnd})
+		offset = dataEnd
+	}
+	return elements, nil
+}
+
+func findEBMLElement(elements []*ebmlElement, id uint64) *ebmlElement {
+	for _, e := range elements {
+		if e.id == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// ebmlUint read the unsigned integer element
+func ebmlUint(r io.ReaderAt, e *ebmlElement) uint64 {
+	if e == nil || e.end-e.start > 8 {
+		return 0
+	}
+	b, err := readAt(r, e.start, int(e.end-e.start))
+	if err != nil {
+		return 0
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// ebmlFloat read the float element
+func ebmlFloat(r io.ReaderAt, e *ebmlElement) float64 {
+	if e == nil || (e.end-e.start != 4 && e.end-e.start != 8) {
+		return 0
+	}
+	b, err := readAt(r, e.start, int(e.end-e.start))
+	if err != nil {
+		return 0
+	}
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// probeWebM read the duration in segment info and the dimensions of the video track
+func probeWebM(r io.ReaderAt, size int64) (*Info, error) {
+	top, err := ebmlElements(r, 0, size, ebmlIDCluster)
+	if err != nil {
+		return nil, err
+	}
+	segment := findEBMLElement(top, ebmlIDSegment)
+	if segment == nil {
+		return nil, ErrFormat
+	}
+	children, err := ebmlElements(r, segment.start, segment.end, ebmlIDCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{}
+	if segmentInfo := findEBMLElement(children, ebmlIDInfo); segmentInfo != nil {
+		elements, err := ebmlElements(r, segmentInfo.start, segmentInfo.end, 0)
+		if err != nil {
+			return nil, err
+		}
+		// duration is in the unit of timecode scale, which is 1ms by default
+		scale := float64(1000000)
+		if s := ebmlUint(r, findEBMLElement(elements, ebmlIDTimecodeScale)); s > 0 {
+			scale = float64(s)
+		}
+		info.Duration = ebmlFloat(r, findEBMLElement(elements, ebmlIDDuration)) * scale / 1e9
+	}
+
+	if tracks := findEBMLElement(children, ebmlIDTracks); tracks != nil {
+		entries, err := ebmlElements(r, tracks.start, tracks.end, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.id != ebmlIDTrackEntry {
+				continue
+			}
+			elements, err := ebmlElements(r, entry.start, entry.end, 0)
+			if err != nil {
+				return nil, err
+			}
+			video := findEBMLElement(elements, ebmlIDVideo)
+			if video == nil {
+				continue
+			}
+			videoElements, err := ebmlElements(r, video.start, video.end, 0)
+			if err != nil {
+				return nil, err
+			}
+			info.Width = int(ebmlUint(r, findEBMLElement(videoElements, ebmlIDPixelWidth)))
+			info.Height = int(ebmlUint(r, findEBMLElement(videoElements, ebmlIDPixelHeight)))
+			break
+		}
+	}
+
+	return info, nil
+}
+
+// probeAVI read the duration and dimensions in the main AVI header
+func probeAVI(r io.ReaderAt, size int64) (*Info, error) {
+	head, err := readAt(r, 0, 72)
+	if err != nil {
+		return nil, err
+	}
+	if string(head[:4]) != "RIFF" || string(head[8:12]) != "AVI " ||
+		string(head[12:16]) != "LIST" || string(head[20:24]) != "hdrl" || string(head[24:28]) != "avih" {
+		return nil, ErrFormat
+	}
+
+	avih := head[32:]
+	microSecPerFrame := binary.LittleEndian.Uint32(avih)
+	totalFrames := binary.LittleEndian.Uint32(avih[16:])
+	return &Info{
+		Duration: float64(totalFrames) * float64(microSecPerFrame) / 1e6,
+		Width:    int(binary.LittleEndian.Uint32(avih[32:])),
+		Height:   int(binary.LittleEndian.Uint32(avih[36:])),
+	}, nil
+}
@@ -0,0 +1,182 @@
+// Package rbac role based access control of the console
+// Each role owns a set of capabilities and api routes are protected by capability.
+package rbac
+
+// roles of user
+const (
+	// RoleAdministrator can do everything, include managing users and settings
+	RoleAdministrator = "administrator"
+	// RoleEditor can manage all contents, include posts of others, pages, taxonomy, media and comments
+	RoleEditor = "editor"
+	// RoleAuthor can publish and manage own articles and upload media
+	RoleAuthor = "author"
+	// RoleContributor can write and manage own articles but can not publish them
+	RoleContributor = "contributor"
+	// RoleSubscriber can only manage own profile
+	RoleSubscriber = "subscriber"
+)
+
+// Capability the action which can be done in console
+type Capability string
+
+// capabilities
+const (
+	// CapRead read dashboard and manage own profile
+	CapRead Capability = "read"
+	// CapEditPosts create and edit own articles
+	CapEditPosts Capability = "edit_posts"
+	// CapPublishPosts publish own articles
+	CapPublishPosts Capability = "publish_posts"
+	// CapEditOthersPosts edit and delete articles of others
+	CapEditOthersPosts Capability = "edit_others_posts"
+	// CapEditPages create, edit and delete pages
+	CapEditPages Capability = "edit_pages"
+	// CapManageCategories manage taxonomy and subjects
+	CapManageCategories Capability = "manage_categories"
+	// CapUploadFiles upload media and manage own media
+	CapUploadFiles Capability = "upload_files"
+	// CapEditOthersMedia edit and delete media of others
+	CapEditOthersMedia Capability = "edit_others_media"
+	// CapModerateComments moderate comments
+	CapModerateComments Capability = "moderate_comments"
+	// CapManageKnowledge manage knowledge bases and their items
+	CapManageKnowledge Capability = "manage_knowledge"
+	// CapEditUsers create, edit and delete users
+	CapEditUsers Capability = "edit_users"
+	// CapManageOptions change settings and read system information
+	CapManageOptions Capability = "manage_options"
+)
+
+// Roles all roles from the highest to the lowest
+var Roles = []string{
+	RoleAdministrator,
+	RoleEditor,
+	RoleAuthor,
+	RoleContributor,
+	RoleSubscriber,
+}
+
+// roleCapabilities capabilities of each role; a role owns all capabilities of the lower roles
+var roleCapabilities = map[string][]Capability{
+	RoleSubscriber: {
+		CapRead,
+	},
+	RoleContributor: {
+		CapEditPosts,
+	},
+	RoleAuthor: {
+		CapPublishPosts,
+		CapUploadFiles,
+	},
+	RoleEditor: {
+		CapEditOthersPosts,
+		CapEditPages,
+		CapManageCategories,
+		CapEditOthersMedia,
+		CapModerateComments,
+		CapManageKnowledge,
+	},
+	RoleAdministrator: {
+		CapEditUsers,
+		CapManageOptions,
+	},
+}
+
+// legacyRoles roles of the old version and the role they are treated as now
+var legacyRoles = map[string]string{
+	"writer": RoleAuthor,
+}
+
+// IsRole check if the role is defined
+func IsRole(role string) bool {
+	_, ok := roleCapabilities[role]
+	return ok
+}
+
+// Can check if the role owns the capability
+func Can(role string, capability Capability) bool {
+	if r, ok := legacyRoles[role]; ok {
+		role = r
+	}
+	if !IsRole(role) {
+		return false
+	}
+
+	// check from the lowest role to the role itself
+	for i := len(Roles) - 1; i >= 0; i-- {
+		for _, c := range roleCapabilities[Roles[i]] {
+			if c == capability {
+				return true
+			}
+		}
+
+		if Roles[i] == role {
+			break
+		}
+	}
+
+	return false
+}
+
+// scopes of personal api token; a request with api token is limited by both the role of user and the token scopes
+const (
+	// ScopeRead read only, all write requests are denied
+	ScopeRead = "read"
+	// ScopeWriteArticle write articles, include taxonomy and subjects reading for articles
+	ScopeWriteArticle = "write:article"
+	// ScopeWriteMedia upload and manage media
+	ScopeWriteMedia = "write:media"
+	// ScopeAdmin no limit of scope, the same as login with password
+	ScopeAdmin = "admin"
+)
+
+// Scopes all scopes of personal api token
+var Scopes = []string{
+	ScopeRead,
+	ScopeWriteArticle,
+	ScopeWriteMedia,
+	ScopeAdmin,
+}
+
+// scopeCapabilities capabilities which the write scopes allow to write
+var scopeCapabilities = map[string][]Capability{
+	ScopeWriteArticle: {
+		CapEditPosts,
+		CapPublishPosts,
+		CapEditOthersPosts,
+	},
+	ScopeWriteMedia: {
+		CapUploadFiles,
+		CapEditOthersMedia,
+	},
+}
+
+// IsScope check if the scope is defined
+func IsScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopesAllow check if the scopes allow the capability
+// every scope allows reading; write is only allowed by admin scope or the write scope of the capability
+func ScopesAllow(scopes []string, capability Capability, write bool) bool {
+	for _, scope := range scopes {
+		if scope == ScopeAdmin {
+			return true
+		}
+		if !write && IsScope(scope) {
+			return true
+		}
+		for _, c := range scopeCapabilities[scope] {
+			if c == capability {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package rbac
+
+import (
+	"testing"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		capability Capability
+		want       bool
+	}{
+		{"administrator manage options", RoleAdministrator, CapManageOptions, true},
+		{"administrator read", RoleAdministrator, CapRead, true},
+		{"editor edit others posts", RoleEditor, CapEditOthersPosts, true},
+		{"editor edit users", RoleEditor, CapEditUsers, false},
+		{"author publish posts", RoleAuthor, CapPublishPosts, true},
+		{"author edit pages", RoleAuthor, CapEditPages, false},
+		{"contributor edit posts", RoleContributor, CapEditPosts, true},
+		{"contributor publish posts", RoleContributor, CapPublishPosts, false},
+		{"subscriber read", RoleSubscriber, CapRead, true},
+		{"subscriber edit posts", RoleSubscriber, CapEditPosts, false},
+		{"legacy writer publish posts", "writer", CapPublishPosts, true},
+		{"unknown role", "guest", CapRead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Can(tt.role, tt.capability); got != tt.want {
+				t.Errorf("Can(%s, %s) = %v, want %v", tt.role, tt.capability, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopesAllow(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []string
+		capability Capability
+		write      bool
+		want       bool
+	}{
+		{"read scope read", []string{ScopeRead}, CapEditPosts, false, true},
+		{"read scope write", []string{ScopeRead}, CapEditPosts, true, false},
+		{"article scope write article", []string{ScopeWriteArticle}, CapEditPosts, true, true},
+		{"article scope write media", []string{ScopeWriteArticle}, CapUploadFiles, true, false},
+		{"media scope write media", []string{ScopeWriteMedia}, CapUploadFiles, true, true},
+		{"article and media scope write media", []string{ScopeWriteArticle, ScopeWriteMedia}, CapUploadFiles, true, true},
+		{"admin scope write options", []string{ScopeAdmin}, CapManageOptions, true, true},
+		{"no scope read", nil, CapRead, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScopesAllow(tt.scopes, tt.capability, tt.write); got != tt.want {
+				t.Errorf("ScopesAllow(%v, %s, %v) = %v, want %v", tt.scopes, tt.capability, tt.write, got, tt.want)
+			}
+		})
+	}
+}
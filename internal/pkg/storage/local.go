@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Local storage of local file system
+type Local struct {
+	root string
+}
+
+// NewLocal return a local storage; paths are relative to the root directory
+func NewLocal(root string) *Local {
+	return &Local{root: root}
+}
+
+func (l *Local) localPath(p string) (string, error) {
+	if err := checkPath(p); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(p)), nil
+}
+
+// Put save the file to path
+func (l *Local) Put(p string, r io.Reader, size int64, contentType string) error {
+	dst, err := l.localPath(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(dst)
+		return err
+	}
+	return f.Close()
+}
+
+// Get open the file
+func (l *Local) Get(p string) (io.ReadCloser, *FileInfo, error) {
+	info, err := l.Stat(p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dst, _ := l.localPath(p)
+	f, err := os.Open(dst)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+// Stat get the file info
+func (l *Local) Stat(p string) (*FileInfo, error) {
+	dst, err := l.localPath(p)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(dst)
+	if os.IsNotExist(err) || (err == nil && fi.IsDir()) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l.fileInfo(p, fi), nil
+}
+
+// Delete delete the file
+func (l *Local) Delete(p string) error {
+	dst, err := l.localPath(p)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List list all files under the directory recursively
+func (l *Local) List(dir string) ([]*FileInfo, error) {
+	root, err := l.localPath(path.Clean(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*FileInfo, 0)
+	err = filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, l.fileInfo("/"+filepath.ToSlash(rel), fi))
+		return nil
+	})
+	return files, err
+}
+
+func (l *Local) fileInfo(p string, fi os.FileInfo) *FileInfo {
+	return &FileInfo{
+		Path:        p,
+		Size:        fi.Size(),
+		ModTime:     fi.ModTime(),
+		ContentType: mime.TypeByExtension(path.Ext(p)),
+	}
+}
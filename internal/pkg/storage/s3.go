@@ -0,0 +1,373 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+)
+
+// s3UnsignedPayload payload hash of the request whose body is not signed
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3 storage of S3 compatible object storage, such as AWS S3 and MinIO
+// Requests are signed with AWS signature version 4.
+type S3 struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	prefix    string
+	client    *http.Client
+}
+
+// NewS3 return a S3 storage
+func NewS3(cfg *config.S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("storage: endpoint and bucket of s3 are required")
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		pathStyle: cfg.PathStyle,
+		prefix:    strings.Trim(cfg.Prefix, "/"),
+		client:    &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// key the object key of the path
+func (s *S3) key(p string) string {
+	if s.prefix == "" {
+		return strings.TrimPrefix(p, "/")
+	}
+	return s.prefix + p
+}
+
+// path the path of the object key
+func (s *S3) path(key string) string {
+	if s.prefix == "" {
+		return "/" + key
+	}
+	return strings.TrimPrefix(key, s.prefix)
+}
+
+// objectURL the url of the object, or the bucket if key is empty
+func (s *S3) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	p := "/" + key
+	if s.pathStyle {
+		p = "/" + s.bucket + p
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + p
+	u.RawPath = s3EscapePath(u.Path)
+	u.RawQuery = s3CanonicalQuery(query)
+	return &u
+}
+
+// do send the signed request
+func (s *S3) do(method, key string, query url.Values, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.objectURL(key, query).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	payloadHash := s3EmptyPayloadHash
+	if body != nil {
+		req.ContentLength = size
+		payloadHash = s3UnsignedPayload
+	}
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signV4(req, payloadHash, s.accessKey, s.secretKey, s.region, "s3", time.Now())
+
+	return s.client.Do(req)
+}
+
+// Put save the file to path
+func (s *S3) Put(p string, r io.Reader, size int64, contentType string) error {
+	if err := checkPath(p); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(http.MethodPut, s.key(p), nil, r, size, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return s3ResponseError(resp)
+}
+
+// Get open the file
+func (s *S3) Get(p string) (io.ReadCloser, *FileInfo, error) {
+	if err := checkPath(p); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.do(http.MethodGet, s.key(p), nil, nil, 0, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s3ResponseError(resp); err != nil {
+		resp.Body.Close()
+		return nil, nil, err
+	}
+
+	return resp.Body, httpFileInfo(p, resp), nil
+}
+
+// Stat get the file info
+func (s *S3) Stat(p string) (*FileInfo, error) {
+	if err := checkPath(p); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(http.MethodHead, s.key(p), nil, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := s3ResponseError(resp); err != nil {
+		return nil, err
+	}
+
+	return httpFileInfo(p, resp), nil
+}
+
+// Delete delete the file
+func (s *S3) Delete(p string) error {
+	if err := checkPath(p); err != nil {
+		return err
+	}
+
+	resp, err := s.do(http.MethodDelete, s.key(p), nil, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := s3ResponseError(resp); err != nil && err != ErrNotExist {
+		return err
+	}
+	return nil
+}
+
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// List list all files under the directory recursively
+func (s *S3) List(dir string) ([]*FileInfo, error) {
+	prefix := strings.TrimPrefix(s.key(strings.TrimSuffix(dir, "/")+"/"), "/")
+
+	files := make([]*FileInfo, 0)
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(http.MethodGet, "", query, nil, 0, nil)
+		if err != nil {
+			return nil, err
+		}
+		result := &s3ListResult{}
+		err = s3ResponseError(resp)
+		if err == nil {
+			err = xml.NewDecoder(resp.Body).Decode(result)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range result.Contents {
+			if strings.HasSuffix(c.Key, "/") {
+				continue
+			}
+			files = append(files, &FileInfo{Path: s.path(c.Key), Size: c.Size, ModTime: c.LastModified})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+
+	return files, nil
+}
+
+// s3ResponseError get the error of response; it returns ErrNotExist if the object does not exist
+func s3ResponseError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotExist
+	}
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("storage: %s %s: %s %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, body)
+}
+
+// httpFileInfo get the file info from response headers
+func httpFileInfo(p string, resp *http.Response) *FileInfo {
+	info := &FileInfo{
+		Path:        p,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = size
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return info
+}
+
+// s3EmptyPayloadHash sha256 of empty payload
+var s3EmptyPayloadHash = hex.EncodeToString(sha256Sum(nil))
+
+// signV4 sign the request with AWS signature version 4
+// host, content-type, content-md5 and all x-amz-* headers are signed.
+func signV4(req *http.Request, payloadHash, accessKey, secretKey, region, service string, t time.Time) {
+	amzDate := t.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if k == "content-type" || k == "content-md5" || strings.HasPrefix(k, "x-amz-") {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	uri := req.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uri,
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sha256Sum([]byte(canonicalRequest)))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// s3CanonicalQuery encode the query sorted by key with RFC 3986 encoding
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3EscapePath escape every segment of the path
+func s3EscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = s3Escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3Escape escape all characters except the unreserved characters of RFC 3986
+func s3Escape(s string) string {
+	return strings.NewReplacer("+", "%20", "%7E", "~").Replace(url.QueryEscape(s))
+}
+
+func sha256Sum(data []byte) []byte {
+	h := sha256.Sum256(data)
+	return h[:]
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,112 @@
+// Package storage backends of the uploaded files, such as local file system, S3 compatible object storage and WebDAV
+// Files are addressed by their url path, e.g. /uploads/2020/01/abc.jpg, which is saved as the GUID of media.
+package storage
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+)
+
+// storage types in config
+const (
+	TypeLocal  = "local"
+	TypeS3     = "s3"
+	TypeWebDAV = "webdav"
+)
+
+var (
+	// ErrNotExist the file does not exist
+	ErrNotExist = errors.New("storage: file does not exist")
+	// ErrInvalidPath the path is not a clean absolute path
+	ErrInvalidPath = errors.New("storage: invalid path")
+)
+
+// Storage backend of the uploaded files
+type Storage interface {
+	// Put save the file to path, the parent directories are created if needed
+	Put(path string, r io.Reader, size int64, contentType string) error
+	// Get open the file; it returns ErrNotExist if the file does not exist
+	Get(path string) (io.ReadCloser, *FileInfo, error)
+	// Stat get the file info; it returns ErrNotExist if the file does not exist
+	Stat(path string) (*FileInfo, error)
+	// Delete delete the file; it is not an error if the file does not exist
+	Delete(path string) error
+	// List list all files under the directory recursively
+	List(dir string) ([]*FileInfo, error)
+}
+
+// FileInfo info of a stored file
+type FileInfo struct {
+	Path        string
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+}
+
+// Engine the global storage instance
+var Engine Storage
+
+// publicURL base url of the files if they are served by the storage directly
+var publicURL string
+
+// Init set up the global storage by config; local storage is used if storage is not configured
+func Init() error {
+	s, err := New(config.Storage)
+	if err != nil {
+		return err
+	}
+
+	Engine = s
+	if config.Storage != nil {
+		publicURL = strings.TrimSuffix(config.Storage.PublicURL, "/")
+	}
+	return nil
+}
+
+// New return a storage by config
+func New(cfg *config.StorageConfig) (Storage, error) {
+	if cfg == nil {
+		return NewLocal("."), nil
+	}
+
+	switch cfg.Type {
+	case "", TypeLocal:
+		return NewLocal("."), nil
+	case TypeS3:
+		return NewS3(&cfg.S3)
+	case TypeWebDAV:
+		return NewWebDAV(&cfg.WebDAV)
+	}
+
+	return nil, errors.New("storage: unknown storage type " + cfg.Type)
+}
+
+// URL get the url of the file for output
+// It is the path itself unless the public url of storage is configured, files are served by puti in that case.
+func URL(path string) string {
+	if publicURL == "" || path == "" {
+		return path
+	}
+	return publicURL + path
+}
+
+// PathOf get the path of the file from its url; it is the reverse of URL
+func PathOf(url string) string {
+	if publicURL != "" && strings.HasPrefix(url, publicURL+"/") {
+		return strings.TrimPrefix(url, publicURL)
+	}
+	return url
+}
+
+// checkPath check if the path is a clean absolute path
+func checkPath(p string) error {
+	if p == "" || p[0] != '/' || path.Clean(p) != p {
+		return ErrInvalidPath
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+
+	"golang.org/x/net/webdav"
+)
+
+// testStorage check the behaviour every storage should have
+func testStorage(t *testing.T, s Storage) {
+	files := map[string]string{
+		"/uploads/2020/01/a.jpg":       "image a",
+		"/uploads/2020/01/a-150x1.jpg": "thumbnail a",
+		"/uploads/cover/b c.png":       "image b",
+		"/other/c.txt":                 "other",
+	}
+	for p, content := range files {
+		if err := s.Put(p, strings.NewReader(content), int64(len(content)), "image/jpeg"); err != nil {
+			t.Fatalf("Put(%s) error = %v", p, err)
+		}
+	}
+
+	r, info, err := s.Get("/uploads/2020/01/a.jpg")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := ioutil.ReadAll(r)
+	r.Close()
+	if string(data) != "image a" || info.Size != int64(len("image a")) {
+		t.Errorf("Get() = %q, size %d", data, info.Size)
+	}
+
+	if _, err := s.Stat("/uploads/cover/b c.png"); err != nil {
+		t.Errorf("Stat() error = %v", err)
+	}
+	if _, err := s.Stat("/uploads/none.jpg"); err != ErrNotExist {
+		t.Errorf("Stat() not exist error = %v, want ErrNotExist", err)
+	}
+	if _, _, err := s.Get("/uploads/none.jpg"); err != ErrNotExist {
+		t.Errorf("Get() not exist error = %v, want ErrNotExist", err)
+	}
+
+	list, err := s.List("/uploads/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var paths []string
+	for _, f := range list {
+		paths = append(paths, f.Path)
+	}
+	sort.Strings(paths)
+	want := []string{"/uploads/2020/01/a-150x1.jpg", "/uploads/2020/01/a.jpg", "/uploads/cover/b c.png"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("List() = %v, want %v", paths, want)
+	}
+
+	if err := s.Delete("/uploads/2020/01/a.jpg"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if _, err := s.Stat("/uploads/2020/01/a.jpg"); err != ErrNotExist {
+		t.Errorf("Stat() after delete error = %v, want ErrNotExist", err)
+	}
+	if err := s.Delete("/uploads/2020/01/a.jpg"); err != nil {
+		t.Errorf("Delete() not exist error = %v", err)
+	}
+
+	if err := s.Put("/uploads/../etc/passwd", strings.NewReader(""), 0, ""); err != ErrInvalidPath {
+		t.Errorf("Put() invalid path error = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "puti-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStorage(t, NewLocal(dir))
+}
+
+func TestWebDAV(t *testing.T) {
+	srv := httptest.NewServer(&webdav.Handler{
+		Prefix:     "/dav",
+		FileSystem: webdav.NewMemFS(),
+		LockSystem: webdav.NewMemLS(),
+	})
+	defer srv.Close()
+
+	s, err := NewWebDAV(&config.WebDAVConfig{URL: srv.URL + "/dav"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStorage(t, s)
+}
+
+// fakeS3 in-memory stand-in of S3 compatible storage which only supports path style requests
+type fakeS3 struct {
+	t         *testing.T
+	bucket    string
+	secretKey string
+
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.checkSignature(r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+f.bucket+"/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		var result struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []struct {
+				Key          string
+				LastModified string
+				Size         int
+			}
+		}
+		for k, v := range f.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				result.Contents = append(result.Contents, struct {
+					Key          string
+					LastModified string
+					Size         int
+				}{k, time.Now().UTC().Format(time.RFC3339), len(v)})
+			}
+		}
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		data, _ := ioutil.ReadAll(r.Body)
+		f.objects[key] = data
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// checkSignature sign the received request again and compare the signature
+func (f *fakeS3) checkSignature(r *http.Request) bool {
+	amzDate, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		f.t.Errorf("invalid X-Amz-Date %q", r.Header.Get("X-Amz-Date"))
+		return false
+	}
+
+	req, _ := http.NewRequest(r.Method, "http://"+r.Host+r.RequestURI, nil)
+	for k, v := range r.Header {
+		if k != "Authorization" {
+			req.Header[k] = v
+		}
+	}
+	signV4(req, r.Header.Get("X-Amz-Content-Sha256"), "minio", f.secretKey, "us-east-1", "s3", amzDate)
+
+	if req.Header.Get("Authorization") != r.Header.Get("Authorization") {
+		f.t.Errorf("signature mismatch of %s %s", r.Method, r.RequestURI)
+		return false
+	}
+	return true
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{t: t, bucket: "puti", secretKey: "minio123", objects: make(map[string][]byte)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s, err := NewS3(&config.S3Config{
+		Endpoint:  srv.URL,
+		Bucket:    "puti",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStorage(t, s)
+}
+
+// TestSignV4 the example of AWS signature version 4 documentation
+func TestSignV4(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signV4(req, s3EmptyPayloadHash, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "iam",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("signV4() = %s, want %s", got, want)
+	}
+}
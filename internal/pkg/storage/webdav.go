@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+)
+
+// WebDAV storage of WebDAV server
+type WebDAV struct {
+	base     *url.URL
+	username string
+	password string
+	client   *http.Client
+
+	// dirs collections which are known to exist
+	dirs sync.Map
+}
+
+// NewWebDAV return a WebDAV storage
+func NewWebDAV(cfg *config.WebDAVConfig) (*WebDAV, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("storage: url of webdav is required")
+	}
+	base, err := url.Parse(strings.TrimSuffix(cfg.URL, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebDAV{
+		base:     base,
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+func (w *WebDAV) do(method, p string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	u := *w.base
+	u.Path = w.base.Path + p
+	u.RawPath = ""
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	return w.client.Do(req)
+}
+
+// Put save the file to path
+func (w *WebDAV) Put(p string, r io.Reader, size int64, contentType string) error {
+	if err := checkPath(p); err != nil {
+		return err
+	}
+	if err := w.mkdirAll(path.Dir(p)); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := w.do(http.MethodPut, p, r, size, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return webDAVResponseError(resp)
+}
+
+// mkdirAll create the collection and its parents
+func (w *WebDAV) mkdirAll(dir string) error {
+	if dir == "/" {
+		return nil
+	}
+	if _, ok := w.dirs.Load(dir); ok {
+		return nil
+	}
+	if err := w.mkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+
+	resp, err := w.do("MKCOL", dir+"/", nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 405 means the collection already exists
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		if err := webDAVResponseError(resp); err != nil {
+			return err
+		}
+	}
+	w.dirs.Store(dir, true)
+	return nil
+}
+
+// Get open the file
+func (w *WebDAV) Get(p string) (io.ReadCloser, *FileInfo, error) {
+	if err := checkPath(p); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := w.do(http.MethodGet, p, nil, 0, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := webDAVResponseError(resp); err != nil {
+		resp.Body.Close()
+		return nil, nil, err
+	}
+
+	return resp.Body, httpFileInfo(p, resp), nil
+}
+
+// Stat get the file info
+func (w *WebDAV) Stat(p string) (*FileInfo, error) {
+	if err := checkPath(p); err != nil {
+		return nil, err
+	}
+
+	resp, err := w.do(http.MethodHead, p, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := webDAVResponseError(resp); err != nil {
+		return nil, err
+	}
+
+	return httpFileInfo(p, resp), nil
+}
+
+// Delete delete the file
+func (w *WebDAV) Delete(p string) error {
+	if err := checkPath(p); err != nil {
+		return err
+	}
+
+	resp, err := w.do(http.MethodDelete, p, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := webDAVResponseError(resp); err != nil && err != ErrNotExist {
+		return err
+	}
+	return nil
+}
+
+type webDAVMultiStatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Prop struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+				ContentLength int64  `xml:"DAV: getcontentlength"`
+				LastModified  string `xml:"DAV: getlastmodified"`
+				ContentType   string `xml:"DAV: getcontenttype"`
+			} `xml:"DAV: prop"`
+			Status string `xml:"DAV: status"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// webDAVPropfind request body of PROPFIND
+const webDAVPropfind = `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:"><D:prop><D:resourcetype/><D:getcontentlength/><D:getlastmodified/><D:getcontenttype/></D:prop></D:propfind>`
+
+// List list all files under the directory recursively
+// Collections are listed one by one with depth 1, since depth infinity is disabled by most servers.
+func (w *WebDAV) List(dir string) ([]*FileInfo, error) {
+	dir = strings.TrimSuffix(dir, "/")
+	if err := checkPath(dir); err != nil {
+		return nil, err
+	}
+
+	files := make([]*FileInfo, 0)
+	dirs := []string{dir}
+	for len(dirs) > 0 {
+		current := dirs[0]
+		dirs = dirs[1:]
+
+		resp, err := w.do("PROPFIND", current+"/", strings.NewReader(webDAVPropfind), int64(len(webDAVPropfind)), http.Header{
+			"Depth":        {"1"},
+			"Content-Type": {"application/xml; charset=utf-8"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		result := &webDAVMultiStatus{}
+		err = webDAVResponseError(resp)
+		if err == nil {
+			err = xml.NewDecoder(resp.Body).Decode(result)
+		}
+		resp.Body.Close()
+		if err == ErrNotExist && current == dir {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range result.Responses {
+			p, err := w.hrefPath(r.Href)
+			if err != nil {
+				return nil, err
+			}
+			if p == current || !strings.HasPrefix(p, current+"/") {
+				continue
+			}
+
+			for _, ps := range r.Propstat {
+				if !strings.Contains(ps.Status, " 200 ") {
+					continue
+				}
+				if ps.Prop.ResourceType.Collection != nil {
+					dirs = append(dirs, p)
+					break
+				}
+
+				info := &FileInfo{Path: p, Size: ps.Prop.ContentLength, ContentType: ps.Prop.ContentType}
+				if t, err := http.ParseTime(ps.Prop.LastModified); err == nil {
+					info.ModTime = t
+				}
+				files = append(files, info)
+				break
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// hrefPath get the path from the href of PROPFIND response, which may be a full url or an absolute path
+func (w *WebDAV) hrefPath(href string) (string, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(u.Path, w.base.Path), "/"), nil
+}
+
+// webDAVResponseError get the error of response; it returns ErrNotExist if the file does not exist
+func webDAVResponseError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotExist
+	}
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("storage: %s %s: %s %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, body)
+}
@@ -0,0 +1,35 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// APITokenPrefix prefix of personal api token, use for distinguishing it from JWT
+const APITokenPrefix = "pt_"
+
+// apiTokenBytes random bytes length of personal api token
+const apiTokenBytes = 20
+
+// GenerateAPIToken generate a new random personal api token
+func GenerateAPIToken() (string, error) {
+	b := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return APITokenPrefix + hex.EncodeToString(b), nil
+}
+
+// IsAPIToken check if the token is a personal api token
+func IsAPIToken(t string) bool {
+	return strings.HasPrefix(t, APITokenPrefix)
+}
+
+// HashAPIToken hash the personal api token; only the hash is stored
+func HashAPIToken(t string) string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,80 @@
+package token
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/db"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revocation local list of tokens which were revoked by logout before they expired
+// Revoked tokens are saved in database, so they stay revoked after restart and on other instances;
+// the local list only saves the database query for tokens known to be revoked.
+// the token id is kept until the token expires, then it is removed from the list
+var revocation = struct {
+	sync.RWMutex
+	tokens map[string]time.Time
+}{
+	tokens: make(map[string]time.Time),
+}
+
+// Revoke put the token into the revocation list
+func Revoke(c *Context) {
+	if c == nil || c.TokenID == "" {
+		return
+	}
+
+	now := time.Now()
+	revocation.Lock()
+	// remove the expired tokens, they can not pass the parse anyway
+	for id, expiresAt := range revocation.tokens {
+		if expiresAt.Before(now) {
+			delete(revocation.tokens, id)
+		}
+	}
+	revocation.tokens[c.TokenID] = c.ExpiresAt
+	revocation.Unlock()
+
+	if db.Engine == nil {
+		return
+	}
+	if err := db.Engine.Where("`expires_time` < ?", now.UTC()).Delete(&model.TokenRevocation{}).Error; err != nil {
+		logger.Errorf("delete expired token revocations failed. %s", err)
+	}
+	r := &model.TokenRevocation{TokenID: c.TokenID, ExpiresAt: c.ExpiresAt.UTC()}
+	if err := db.Engine.Clauses(clause.OnConflict{DoNothing: true}).Create(r).Error; err != nil {
+		logger.Errorf("save token revocation failed. token id: %s. %s", c.TokenID, err)
+	}
+}
+
+// IsRevoked check if the token id is in the revocation list
+// The token is treated as revoked if the revocation list can not be read.
+func IsRevoked(tokenID string) bool {
+	revocation.RLock()
+	_, ok := revocation.tokens[tokenID]
+	revocation.RUnlock()
+	if ok || db.Engine == nil {
+		return ok
+	}
+
+	r := &model.TokenRevocation{}
+	err := db.Engine.Where("`token_id` = ?", tokenID).First(r).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
+	if err != nil {
+		logger.Errorf("get token revocation failed. token id: %s. %s", tokenID, err)
+		return true
+	}
+
+	revocation.Lock()
+	revocation.tokens[tokenID] = r.ExpiresAt
+	revocation.Unlock()
+	return true
+}
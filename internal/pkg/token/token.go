@@ -7,6 +7,7 @@ import (
 
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	uuid "github.com/google/uuid"
 	"github.com/puti-projects/puti/internal/pkg/config"
 )
 
@@ -15,12 +16,41 @@ var (
 	ErrMissingHeader = errors.New("The length of the `Authorization` header is zero")
 	// ErrMissingToken means the `token` params was empty.
 	ErrMissingToken = errors.New("Missing token")
+	// ErrTokenType means the token is not the type which is expected, such as using refresh token as access token.
+	ErrTokenType = errors.New("Error token type")
+	// ErrTokenRevoked means the token was revoked by logout.
+	ErrTokenRevoked = errors.New("The token was revoked")
+)
+
+// CookieName is the cookie which the console keeps the login token in
+const CookieName = "puti-login-token"
+
+// token types
+const (
+	// TypeAccess access token which is used to request api
+	TypeAccess = "access"
+	// TypeRefresh refresh token which is only used to get a new access token
+	TypeRefresh = "refresh"
+	// TypeTwoFactor token of the first login step, it is only used to finish the two-factor authentication
+	TypeTwoFactor = "2fa"
+)
+
+// default lifetime of tokens if it is not set in config
+const (
+	defaultAccessTokenExpire  = 2 * time.Hour
+	defaultRefreshTokenExpire = 7 * 24 * time.Hour
+	// two-factor token is not configurable, user has a few minutes to input the code
+	twoFactorTokenExpire = 5 * time.Minute
 )
 
 // Context is the context of the JSON view token.
 type Context struct {
-	ID       uint64
-	Username string
+	ID        uint64
+	Username  string
+	TokenID   string
+	Type      string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
 }
 
 // secretFunc validates the secret format.
@@ -35,9 +65,14 @@ func secretFunc(secret string) jwt.Keyfunc {
 	}
 }
 
-// Parse validates the token with the specified secret,
+// Parse validates the access token with the specified secret,
 // and returns the context if the token was valid.
 func Parse(tokenString string, secret string) (*Context, error) {
+	return parse(tokenString, secret, TypeAccess)
+}
+
+// parse validates the token and its type; the token must have an expiry time and not be revoked
+func parse(tokenString string, secret string, tokenType string) (*Context, error) {
 	ctx := &Context{}
 
 	// Parse the token.
@@ -46,17 +81,44 @@ func Parse(tokenString string, secret string) (*Context, error) {
 	// Parse error.
 	if err != nil {
 		return ctx, err
+	}
 
-		// Read the token if it's valid.
-	} else if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		ctx.ID = uint64(claims["id"].(float64))
-		ctx.Username = claims["username"].(string)
-		return ctx, nil
+	// Read the token if it's valid.
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return ctx, jwt.ErrSignatureInvalid
+	}
 
-		// Other errors.
-	} else {
-		return ctx, err
+	// tokens signed by old version have no expiry time; they are not accepted any more
+	id, idOk := claims["id"].(float64)
+	username, usernameOk := claims["username"].(string)
+	jti, jtiOk := claims["jti"].(string)
+	typ, typOk := claims["typ"].(string)
+	iat, iatOk := claims["iat"].(float64)
+	exp, expOk := claims["exp"].(float64)
+	if !idOk || !usernameOk || !jtiOk || !typOk || !iatOk || !expOk {
+		return ctx, jwt.ErrSignatureInvalid
+	}
+
+	if typ != tokenType {
+		return ctx, ErrTokenType
+	}
+
+	if IsRevoked(jti) {
+		return ctx, ErrTokenRevoked
+	}
+
+	ctx.ID = uint64(id)
+	ctx.Username = username
+	ctx.TokenID = jti
+	ctx.Type = typ
+	ctx.IssuedAt = time.Unix(int64(iat), 0)
+	// iat is in seconds; the issued time in milliseconds tells tokens issued in the same second apart
+	if iatMs, ok := claims["iat_ms"].(float64); ok {
+		ctx.IssuedAt = time.Unix(0, int64(iatMs)*int64(time.Millisecond))
 	}
+	ctx.ExpiresAt = time.Unix(int64(exp), 0)
+	return ctx, nil
 }
 
 // ParseToken directly parse the token
@@ -71,39 +133,105 @@ func ParseToken(t string) (*Context, error) {
 	return Parse(t, secret)
 }
 
-// ParseRequest gets the token from the header and
-// pass it to the Parse function to parses the token.
-func ParseRequest(c *gin.Context) (*Context, error) {
-	header := c.Request.Header.Get("Authorization")
+// ParseRefreshToken parse the refresh token
+func ParseRefreshToken(t string) (*Context, error) {
+	if len(t) == 0 {
+		return &Context{}, ErrMissingToken
+	}
 
-	// Load the jwt secret from config
-	secret := config.Safety.JwtSecret
+	return parse(t, config.Safety.JwtSecret, TypeRefresh)
+}
 
+// GetRequestToken gets the bearer token from the `Authorization` header
+func GetRequestToken(c *gin.Context) (string, error) {
+	header := c.Request.Header.Get("Authorization")
 	if len(header) == 0 {
-		return &Context{}, ErrMissingHeader
+		return "", ErrMissingHeader
 	}
 
 	var t string
 	// Parse the header to get the token part.
 	fmt.Sscanf(header, "Bearer %s", &t)
-	return Parse(t, secret)
+	return t, nil
+}
+
+// ParseTwoFactorToken parse the token of the first login step
+func ParseTwoFactorToken(t string) (*Context, error) {
+	if len(t) == 0 {
+		return &Context{}, ErrMissingToken
+	}
+
+	return parse(t, config.Safety.JwtSecret, TypeTwoFactor)
+}
+
+// ParseRequest gets the token from the header and
+// pass it to the Parse function to parses the token.
+func ParseRequest(c *gin.Context) (*Context, error) {
+	t, err := GetRequestToken(c)
+	if err != nil {
+		return &Context{}, err
+	}
+
+	// Load the jwt secret from config
+	return Parse(t, config.Safety.JwtSecret)
+}
+
+// ParseCookie gets the token from the console login cookie and
+// pass it to the Parse function to parses the token.
+func ParseCookie(c *gin.Context) (*Context, error) {
+	t, err := c.Cookie(CookieName)
+	if err != nil {
+		return &Context{}, ErrMissingToken
+	}
+
+	return ParseToken(t)
 }
 
 // Sign signs the context with the specified secret.
+// the type of token is access token if the context type is empty,
+// and the expiry time is set by the lifetime of the type in config
 func Sign(ctx *gin.Context, c Context, secret string) (tokenString string, err error) {
 	// Load the jwt secret from the Gin config if the secret isn't specified.
 	if secret == "" {
 		secret = config.Safety.JwtSecret
 	}
+	if c.Type == "" {
+		c.Type = TypeAccess
+	}
+
+	now := time.Now()
 	// The token content.
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"id":       c.ID,
 		"username": c.Username,
-		"nbf":      time.Now().Unix(),
-		"iat":      time.Now().Unix(),
+		"jti":      uuid.New().String(),
+		"typ":      c.Type,
+		"nbf":      now.Unix(),
+		"iat":      now.Unix(),
+		"iat_ms":   now.UnixNano() / int64(time.Millisecond),
+		"exp":      now.Add(Lifetime(c.Type)).Unix(),
 	})
 	// Sign the token with the specified secret.
 	tokenString, err = token.SignedString([]byte(secret))
 
 	return
 }
+
+// Lifetime get the lifetime of the token type from config
+func Lifetime(tokenType string) time.Duration {
+	if tokenType == TypeTwoFactor {
+		return twoFactorTokenExpire
+	}
+
+	if tokenType == TypeRefresh {
+		if config.Safety.RefreshTokenExpire > 0 {
+			return config.Safety.RefreshTokenExpire
+		}
+		return defaultRefreshTokenExpire
+	}
+
+	if config.Safety.AccessTokenExpire > 0 {
+		return config.Safety.AccessTokenExpire
+	}
+	return defaultAccessTokenExpire
+}
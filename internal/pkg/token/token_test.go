@@ -0,0 +1,81 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/puti-projects/puti/internal/pkg/config"
+)
+
+func TestSignAndParse(t *testing.T) {
+	config.Safety = &config.SafetyConfig{JwtSecret: "test-secret", AccessTokenExpire: time.Hour}
+
+	access, err := Sign(nil, Context{ID: 1, Username: "admin"}, "")
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	refresh, err := Sign(nil, Context{ID: 1, Username: "admin", Type: TypeRefresh}, "")
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	ctx, err := ParseToken(access)
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+	if ctx.ID != 1 || ctx.Username != "admin" || ctx.Type != TypeAccess {
+		t.Errorf("ParseToken() = %+v, want access token of user 1", ctx)
+	}
+	// the expiry time is in seconds, and the issued time is in milliseconds
+	if d := ctx.ExpiresAt.Sub(ctx.IssuedAt.Truncate(time.Second)); d != time.Hour {
+		t.Errorf("token lifetime = %v, want %v", d, time.Hour)
+	}
+	if d := time.Since(ctx.IssuedAt); d < 0 || d > time.Minute || ctx.IssuedAt.Nanosecond()%int(time.Millisecond) != 0 {
+		t.Errorf("token issued at %v, want now in milliseconds", ctx.IssuedAt)
+	}
+
+	if _, err := ParseToken(refresh); err != ErrTokenType {
+		t.Errorf("ParseToken(refresh) error = %v, want %v", err, ErrTokenType)
+	}
+	if _, err := ParseRefreshToken(access); err != ErrTokenType {
+		t.Errorf("ParseRefreshToken(access) error = %v, want %v", err, ErrTokenType)
+	}
+
+	Revoke(ctx)
+	if _, err := ParseToken(access); err != ErrTokenRevoked {
+		t.Errorf("ParseToken(revoked) error = %v, want %v", err, ErrTokenRevoked)
+	}
+}
+
+func TestParseExpired(t *testing.T) {
+	config.Safety = &config.SafetyConfig{JwtSecret: "test-secret"}
+
+	expired := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":       1,
+		"username": "admin",
+		"jti":      "expired",
+		"typ":      TypeAccess,
+		"iat":      time.Now().Add(-2 * time.Hour).Unix(),
+		"exp":      time.Now().Add(-time.Hour).Unix(),
+	})
+	tokenString, err := expired.SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	if _, err := ParseToken(tokenString); err == nil {
+		t.Error("ParseToken(expired) error = nil, want error")
+	}
+
+	// token without expiry time signed by old version
+	forever := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": 1, "username": "admin"})
+	tokenString, err = forever.SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	if _, err := ParseToken(tokenString); err == nil {
+		t.Error("ParseToken(no expiry) error = nil, want error")
+	}
+}
@@ -0,0 +1,69 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// test vectors of RFC 6238 appendix B with SHA1, truncated to 6 digits
+func TestCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(secret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+
+	now := time.Now()
+	code, _ := Code(secret, Step(now)-1)
+
+	step, ok := Validate(secret, code, now, 0)
+	if !ok || step != Step(now)-1 {
+		t.Errorf("Validate() = %d, %v, want previous step", step, ok)
+	}
+	if _, ok := Validate(secret, code, now, step); ok {
+		t.Error("Validate() the used code again = true, want false")
+	}
+
+	old, _ := Code(secret, Step(now)-3)
+	if _, ok := Validate(secret, old, now, 0); ok {
+		t.Error("Validate() the expired code = true, want false")
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(2)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != 2 || codes[0] == codes[1] {
+		t.Fatalf("GenerateRecoveryCodes() = %v", codes)
+	}
+
+	if HashRecoveryCode("ABCDE-12345") != HashRecoveryCode(" abcde12345") {
+		t.Error("HashRecoveryCode() is not normalized")
+	}
+}
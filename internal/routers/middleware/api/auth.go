@@ -1,23 +1,77 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/admin/service"
 	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
 	"github.com/puti-projects/puti/internal/pkg/token"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AuthMiddleware middleware of token prase
+// both JWT and personal api token are accepted. Expired and revoked tokens are rejected;
+// the user of the token must be still enabled, and it is set in context as the login user
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		t, err := token.GetRequestToken(c)
+		if err != nil {
+			api.SendResponse(c, errno.ErrTokenInvalid, nil)
+			c.Abort()
+			return
+		}
+
+		svc := service.New(c.Request.Context())
+
+		// personal api token
+		if token.IsAPIToken(t) {
+			u, scopes, err := svc.CheckAPIToken(t)
+			if err != nil {
+				api.SendResponse(c, err, nil)
+				c.Abort()
+				return
+			}
+
+			c.Set(api.LoginUserKey, &api.LoginUser{ID: u.ID, Username: u.Username, Role: u.Roles, Scopes: scopes})
+			c.Next()
+			return
+		}
+
 		// Prase the json view token
-		if _, err := token.ParseRequest(c); err != nil {
+		ctx, err := token.ParseToken(t)
+		if err != nil {
 			api.SendResponse(c, errno.ErrTokenInvalid, nil)
 			c.Abort()
 			return
 		}
 
+		u, err := svc.CheckLoginToken(ctx)
+		if err != nil {
+			api.SendResponse(c, err, nil)
+			c.Abort()
+			return
+		}
+
+		c.Set(api.LoginUserKey, &api.LoginUser{ID: u.ID, Username: u.Username, Role: u.Roles})
+		c.Next()
+	}
+}
+
+// Permission middleware of checking if the role of login user has the capability
+// requests with personal api token are also limited by the token scopes; only GET request is not a write
+// it must be used after AuthMiddleware
+func Permission(capability rbac.Capability) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		write := c.Request.Method != http.MethodGet
+		if !api.GetLoginUser(c).Allow(capability, write) {
+			api.SendResponse(c, errno.ErrPermissionDenied, nil)
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/puti-projects/puti/internal/admin/api"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitWindow request count of one client in the current window
+type rateLimitWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit middleware of limiting the requests of one client IP to limit times in the window duration
+// it is an in-memory fixed window limiter, each route using it has its own counter
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	var lock sync.Mutex
+	clients := make(map[string]*rateLimitWindow)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		lock.Lock()
+		w, ok := clients[ip]
+		if !ok || now.Sub(w.start) >= window {
+			// clean up expired windows before adding new one, so the map will not grow forever
+			for k, v := range clients {
+				if now.Sub(v.start) >= window {
+					delete(clients, k)
+				}
+			}
+			w = &rateLimitWindow{start: now}
+			clients[ip] = w
+		}
+		w.count++
+		exceeded := w.count > limit
+		lock.Unlock()
+
+		if exceeded {
+			api.SendResponse(c, errno.ErrTooManyRequests, nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
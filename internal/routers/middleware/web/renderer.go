@@ -5,6 +5,7 @@ import (
 	"html/template"
 
 	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/markdown"
 
 	"github.com/gin-gonic/gin"
 )
@@ -47,6 +48,11 @@ func renderBasicSetting(c *gin.Context, renderData *RenderData) *gin.Context {
 		"FooterCopyright": template.HTML(cache.Options.Get("footer_copyright")),
 		"SiteLanguage":    cache.Options.Get("site_language"),
 		"CurrentTheme":    cache.Options.Get("current_theme"),
+		"OpenXML":         cache.Options.Get("open_XML") == "on",
+
+		"CodeStyle":       codeStyle(),
+		"MarkdownMath":    markdown.GetOptions().Math,
+		"MarkdownMermaid": markdown.GetOptions().Mermaid,
 
 		"ShowOnFront":          cache.Options.Get("show_on_front"),
 		"ArticleCommentStatus": cache.Options.Get("article_comment_status"),
@@ -57,6 +63,14 @@ func renderBasicSetting(c *gin.Context, renderData *RenderData) *gin.Context {
 	return c
 }
 
+// codeStyle get the code highlight style of the highlight library
+func codeStyle() string {
+	if style := cache.Options.Get("markdown_code_style"); style != "" {
+		return style
+	}
+	return "github"
+}
+
 func renderThemeSetting(c *gin.Context, renderData *RenderData) *gin.Context {
 	themeMap := map[string]interface{}{}
 
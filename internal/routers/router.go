@@ -5,10 +5,12 @@ import (
 	"html/template"
 	"net/http"
 	"path/filepath"
+	"time"
 
 	"github.com/puti-projects/puti/internal/admin/api"
 	"github.com/puti-projects/puti/internal/admin/api/article"
 	"github.com/puti-projects/puti/internal/admin/api/auth"
+	"github.com/puti-projects/puti/internal/admin/api/comment"
 	"github.com/puti-projects/puti/internal/admin/api/knowledge"
 	knowledgeItem "github.com/puti-projects/puti/internal/admin/api/knowledgeitem"
 	"github.com/puti-projects/puti/internal/admin/api/media"
@@ -20,11 +22,13 @@ import (
 	"github.com/puti-projects/puti/internal/admin/api/user"
 	"github.com/puti-projects/puti/internal/pkg/config"
 	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/rbac"
 	"github.com/puti-projects/puti/internal/pkg/theme"
 	"github.com/puti-projects/puti/internal/routers/middleware"
 	apiMiddleware "github.com/puti-projects/puti/internal/routers/middleware/api"
 	webMiddleware "github.com/puti-projects/puti/internal/routers/middleware/web"
 	"github.com/puti-projects/puti/internal/utils"
+	"github.com/puti-projects/puti/internal/web/service"
 	"github.com/puti-projects/puti/internal/web/view"
 
 	"github.com/gin-gonic/gin"
@@ -70,6 +74,20 @@ func setFuncMap(g *gin.Engine) *gin.Engine {
 		"minus": func(a, b int) int {
 			return a - b
 		},
+		// dict builds a map from key value pairs; use for passing more than one value into a sub template
+		"dict": func(values ...interface{}) map[string]interface{} {
+			dict := make(map[string]interface{}, len(values)/2)
+			for i := 0; i+1 < len(values); i += 2 {
+				if key, ok := values[i].(string); ok {
+					dict[key] = values[i+1]
+				}
+			}
+			return dict
+		},
+		// imageSize get the url of the image size, such as thumbnail, medium and large; use for cover pictures
+		"imageSize": func(url, size string) string {
+			return service.SrvEngine.GetImageSizeURL(url, size)
+		},
 		// TODO this function should remove after output of widget standardization
 		"formatNullTime": func(time sql.NullTime, format string) string {
 			return utils.GetFormatNullTime(&time, format)
@@ -97,14 +115,31 @@ func loadWeb(g *gin.Engine) {
 		webGroup.GET("/category/:slug", view.ShowCategoryArticleList)
 		webGroup.GET("/tag/:slug", view.ShowTagArticleList)
 		webGroup.GET("/article/:id", view.ShowArticleDetail)
+		webGroup.POST("/article/:id", view.PostArticleComment)
 		webGroup.GET("/archive", view.ShowArchive)
 		webGroup.GET("/subject", view.ShowTopSubjects)
 		webGroup.GET("/subject/:slug", view.ShowSubjects)
 		webGroup.GET("/knowledge/:type/:slug", view.ShowKnowledgeDetail)
 		webGroup.GET("/knowledge/:type/:slug/:symbol", view.ShowKnowledgeDetail)
+		webGroup.GET("/register", view.ShowRegister)
+
+		// feeds
+		webGroup.GET(config.PathRSS, view.ShowFeed(service.FeedTypeRSS, ""))
+		webGroup.GET(config.PathAtom, view.ShowFeed(service.FeedTypeAtom, ""))
+		webGroup.GET(config.PathCategory+"/:slug"+config.PathRSS, view.ShowFeed(service.FeedTypeRSS, service.FeedScopeCategory))
+		webGroup.GET(config.PathCategory+"/:slug"+config.PathAtom, view.ShowFeed(service.FeedTypeAtom, service.FeedScopeCategory))
+		webGroup.GET(config.PathTag+"/:slug"+config.PathRSS, view.ShowFeed(service.FeedTypeRSS, service.FeedScopeTag))
+		webGroup.GET(config.PathTag+"/:slug"+config.PathAtom, view.ShowFeed(service.FeedTypeAtom, service.FeedScopeTag))
+		webGroup.GET(config.PathSubject+"/:slug"+config.PathRSS, view.ShowFeed(service.FeedTypeRSS, service.FeedScopeSubject))
+		webGroup.GET(config.PathSubject+"/:slug"+config.PathAtom, view.ShowFeed(service.FeedTypeAtom, service.FeedScopeSubject))
+
+		// sitemap
+		webGroup.GET(config.PathSiteMap, view.ShowSitemap)
+		webGroup.GET(config.PathSiteMapPart+"/:page", view.ShowSitemapPart)
+		webGroup.GET(config.PathRobots, view.ShowRobots)
 	}
 
-	// no route handle
+	// no route handle; comment posted on page is also handled here
 	g.NoRoute(webMiddleware.Renderer, view.CheckAndShowPage)
 }
 
@@ -112,7 +147,9 @@ func loadWeb(g *gin.Engine) {
 func loadStatic(g *gin.Engine) {
 	// resource
 	g.Static("/static", config.StaticPath("console/static"))
-	g.Static("/uploads", config.StaticPath("uploads/"))
+	// uploads are served by the handler, which negotiates image formats and works with remote storages
+	g.GET("/uploads/*filepath", view.ShowUpload)
+	g.HEAD("/uploads/*filepath", view.ShowUpload)
 	g.Static("/assets", config.StaticPath("assets/"))
 	g.StaticFile("/favicon.ico", config.StaticPath("assets/favicon.ico")) // default
 
@@ -166,55 +203,98 @@ func loadAPI(g *gin.Engine) {
 	apiGroup.Use(apiMiddleware.RequestID())
 
 	apiGroup.POST("/login", auth.Login)
-	apiGroup.GET("/token", auth.Info)
+	apiGroup.POST("/login/2fa", apiMiddleware.RateLimit(10, 5*time.Minute), auth.LoginTwoFactor)
+	apiGroup.POST("/token/refresh", auth.Refresh)
+	apiGroup.POST("/register", apiMiddleware.RateLimit(5, time.Hour), auth.Register)
 
 	apiGroup.Use(apiMiddleware.AuthMiddleware())
 	{
-		apiGroup.GET("/statistics/dashboard", statistics.Dashboard)
-		apiGroup.GET("/statistics/system", statistics.System)
-		apiGroup.POST("/user/:username", user.Create)
-		apiGroup.GET("/user/:username", user.Get)
-		apiGroup.DELETE("/user/:id", user.Delete)
-		apiGroup.PUT("/user/:id", user.Update)
-		apiGroup.GET("/user", user.List)
-		apiGroup.POST("/avatar", user.Avatar)
-		apiGroup.GET("/article", article.List)
-		apiGroup.GET("/article/:id", article.Get)
-		apiGroup.POST("/article", article.Create)
-		apiGroup.PUT("/article/:id", article.Update)
-		apiGroup.DELETE("/article/:id", article.Delete)
-		apiGroup.GET("/page", page.List)
-		apiGroup.GET("/page/:id", page.Get)
-		apiGroup.POST("/page", page.Create)
-		apiGroup.PUT("/page/:id", page.Update)
-		apiGroup.DELETE("/page/:id", page.Delete)
-		apiGroup.POST("/taxonomy/:name", taxonomy.Create)
-		apiGroup.GET("/taxonomy/:id", taxonomy.Get)
-		apiGroup.DELETE("/taxonomy/:id", taxonomy.Delete)
-		apiGroup.PUT("/taxonomy/:id", taxonomy.Update)
-		apiGroup.GET("/taxonomy", taxonomy.List)
-		apiGroup.GET("/media/:id", media.Detail)
-		apiGroup.GET("/media", media.List)
-		apiGroup.POST("/media", media.Upload)
-		apiGroup.DELETE("/media/:id", media.Delete)
-		apiGroup.PUT("/media/:id", media.Update)
-		apiGroup.GET("/option", option.List)
-		apiGroup.PUT("/option", option.Update)
-		apiGroup.GET("/subject", subject.List)
-		apiGroup.GET("/subject/:id", subject.Detail)
-		apiGroup.POST("/subject/:name", subject.Create)
-		apiGroup.PUT("/subject/:id", subject.Update)
-		apiGroup.DELETE("/subject/:id", subject.Delete)
-		apiGroup.GET("/knowledge", knowledge.List)
-		apiGroup.POST("/knowledge/:name", knowledge.Create)
-		apiGroup.GET("/knowledge/:id", knowledge.Detail)
-		apiGroup.PUT("/knowledge/:id", knowledge.Update)
+		// every route is protected by the capability of the login user's role
+		// ownership of articles, media and user profile is checked in the handler
+		can := apiMiddleware.Permission
+
+		apiGroup.POST("/logout", auth.Logout)
+		apiGroup.GET("/token", auth.Info)
+
+		apiGroup.GET("/statistics/dashboard", can(rbac.CapRead), statistics.Dashboard)
+		apiGroup.GET("/statistics/system", can(rbac.CapManageOptions), statistics.System)
+		apiGroup.POST("/user/:username", can(rbac.CapEditUsers), user.Create)
+		apiGroup.GET("/user/:username", can(rbac.CapRead), user.Get)
+		apiGroup.DELETE("/user/:id", can(rbac.CapEditUsers), user.Delete)
+		apiGroup.PUT("/user/:id", can(rbac.CapRead), user.Update)
+		apiGroup.GET("/user", can(rbac.CapEditUsers), user.List)
+		apiGroup.POST("/avatar", can(rbac.CapRead), user.Avatar)
+		// :username is the user id here, since it shares the wildcard with the routes above in the same method
+		apiGroup.GET("/user/:username/tokens", can(rbac.CapRead), user.ListToken)
+		apiGroup.POST("/user/:username/tokens", can(rbac.CapRead), user.CreateToken)
+		apiGroup.DELETE("/user/:id/tokens/:tokenId", can(rbac.CapRead), user.DeleteToken)
+		apiGroup.DELETE("/user/:id/2fa", can(rbac.CapEditUsers), user.ResetTwoFactor)
+		apiGroup.GET("/2fa", can(rbac.CapRead), auth.TwoFactorStatus)
+		apiGroup.POST("/2fa/enroll", can(rbac.CapRead), auth.TwoFactorEnroll)
+		apiGroup.POST("/2fa/activate", can(rbac.CapRead), auth.TwoFactorActivate)
+		apiGroup.POST("/2fa/recovery-codes", can(rbac.CapRead), auth.TwoFactorRecoveryCodes)
+		apiGroup.DELETE("/2fa", can(rbac.CapRead), auth.TwoFactorDisable)
+		apiGroup.GET("/article", can(rbac.CapEditPosts), article.List)
+		apiGroup.GET("/article/:id", can(rbac.CapEditPosts), article.Get)
+		apiGroup.POST("/article", can(rbac.CapEditPosts), article.Create)
+		apiGroup.PUT("/article/:id", can(rbac.CapEditPosts), article.Update)
+		apiGroup.DELETE("/article/:id", can(rbac.CapEditPosts), article.Delete)
+		apiGroup.GET("/article/:id/revision", can(rbac.CapEditPosts), article.ListRevision)
+		apiGroup.GET("/article/:id/revision-diff", can(rbac.CapEditPosts), article.DiffRevision)
+		apiGroup.PUT("/article/:id/revision/:rid", can(rbac.CapEditPosts), article.RestoreRevision)
+		apiGroup.GET("/page", can(rbac.CapEditPages), page.List)
+		apiGroup.GET("/page/:id", can(rbac.CapEditPages), page.Get)
+		apiGroup.POST("/page", can(rbac.CapEditPages), page.Create)
+		apiGroup.PUT("/page/:id", can(rbac.CapEditPages), page.Update)
+		apiGroup.DELETE("/page/:id", can(rbac.CapEditPages), page.Delete)
+		apiGroup.GET("/page/:id/revision", can(rbac.CapEditPages), page.ListRevision)
+		apiGroup.GET("/page/:id/revision-diff", can(rbac.CapEditPages), page.DiffRevision)
+		apiGroup.PUT("/page/:id/revision/:rid", can(rbac.CapEditPages), page.RestoreRevision)
+		apiGroup.POST("/taxonomy/:name", can(rbac.CapManageCategories), taxonomy.Create)
+		apiGroup.GET("/taxonomy/:id", can(rbac.CapEditPosts), taxonomy.Get)
+		apiGroup.DELETE("/taxonomy/:id", can(rbac.CapManageCategories), taxonomy.Delete)
+		apiGroup.PUT("/taxonomy/:id", can(rbac.CapManageCategories), taxonomy.Update)
+		apiGroup.GET("/taxonomy", can(rbac.CapEditPosts), taxonomy.List)
+		apiGroup.GET("/media/:id", can(rbac.CapUploadFiles), media.Detail)
+		apiGroup.GET("/media", can(rbac.CapUploadFiles), media.List)
+		apiGroup.POST("/media", can(rbac.CapUploadFiles), media.Upload)
+		apiGroup.DELETE("/media/:id", can(rbac.CapUploadFiles), media.Delete)
+		apiGroup.PUT("/media/:id", can(rbac.CapUploadFiles), media.Update)
+		apiGroup.PUT("/media", can(rbac.CapUploadFiles), media.Move)          // bulk move into folder
+		apiGroup.DELETE("/media", can(rbac.CapUploadFiles), media.BulkDelete) // bulk delete
+		apiGroup.GET("/media-folder", can(rbac.CapUploadFiles), media.Folder)
+		// chunked uploads; uploads are only visible to the user who starts them
+		apiGroup.POST("/media-uploads", can(rbac.CapUploadFiles), media.CreateChunkUpload)
+		apiGroup.GET("/media-uploads/:id", can(rbac.CapUploadFiles), media.ChunkUploadStatus)
+		apiGroup.PATCH("/media-uploads/:id", can(rbac.CapUploadFiles), media.AppendChunkUpload)
+		apiGroup.POST("/media-uploads/:id", can(rbac.CapUploadFiles), media.CompleteChunkUpload)
+		apiGroup.DELETE("/media-uploads/:id", can(rbac.CapUploadFiles), media.AbortChunkUpload)
+		apiGroup.GET("/media-orphan", can(rbac.CapManageOptions), media.Orphan)
+		apiGroup.DELETE("/media-orphan", can(rbac.CapManageOptions), media.CleanOrphan)
+		apiGroup.GET("/comment", can(rbac.CapModerateComments), comment.List)
+		apiGroup.GET("/comment/:id", can(rbac.CapModerateComments), comment.Detail)
+		apiGroup.PUT("/comment", can(rbac.CapModerateComments), comment.Status) // bulk status update
+		apiGroup.PUT("/comment/:id", can(rbac.CapModerateComments), comment.Update)
+		apiGroup.POST("/comment/:id/reply", can(rbac.CapModerateComments), comment.Reply)
+		apiGroup.DELETE("/comment/:id", can(rbac.CapModerateComments), comment.Delete)
+		apiGroup.GET("/option", can(rbac.CapManageOptions), option.List)
+		apiGroup.PUT("/option", can(rbac.CapManageOptions), option.Update)
+		apiGroup.POST("/option/render", can(rbac.CapManageOptions), option.Render) // render all posts again after options change
+		apiGroup.GET("/subject", can(rbac.CapEditPosts), subject.List)
+		apiGroup.GET("/subject/:id", can(rbac.CapEditPosts), subject.Detail)
+		apiGroup.POST("/subject/:name", can(rbac.CapManageCategories), subject.Create)
+		apiGroup.PUT("/subject/:id", can(rbac.CapManageCategories), subject.Update)
+		apiGroup.DELETE("/subject/:id", can(rbac.CapManageCategories), subject.Delete)
+		apiGroup.GET("/knowledge", can(rbac.CapManageKnowledge), knowledge.List)
+		apiGroup.POST("/knowledge/:name", can(rbac.CapManageKnowledge), knowledge.Create)
+		apiGroup.GET("/knowledge/:id", can(rbac.CapManageKnowledge), knowledge.Detail)
+		apiGroup.PUT("/knowledge/:id", can(rbac.CapManageKnowledge), knowledge.Update)
 		//apiGroup.DELETE("/knowledge/:id", knowledge.Delete)
-		apiGroup.POST("/knowledge-item", knowledgeItem.Create)
-		apiGroup.GET("/knowledge-list/:id", knowledgeItem.List) // knowledge item list under knowledge :id
-		apiGroup.GET("/knowledge-item/:id", knowledgeItem.Detail)
-		apiGroup.PUT("/knowledge-item/:id", knowledgeItem.Update) // info and content
-		apiGroup.DELETE("/knowledge-item/:id", knowledgeItem.Delete)
+		apiGroup.POST("/knowledge-item", can(rbac.CapManageKnowledge), knowledgeItem.Create)
+		apiGroup.GET("/knowledge-list/:id", can(rbac.CapManageKnowledge), knowledgeItem.List) // knowledge item list under knowledge :id
+		apiGroup.GET("/knowledge-item/:id", can(rbac.CapManageKnowledge), knowledgeItem.Detail)
+		apiGroup.PUT("/knowledge-item/:id", can(rbac.CapManageKnowledge), knowledgeItem.Update) // info and content
+		apiGroup.DELETE("/knowledge-item/:id", can(rbac.CapManageKnowledge), knowledgeItem.Delete)
 	}
 }
 
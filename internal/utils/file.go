@@ -3,6 +3,7 @@ package utils
 import (
 	"mime"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,10 +15,30 @@ var fileMimeType = map[string]string{
 	"image/png":       "picture",
 	"image/gif":       "picture",
 	"text/plain":      "text",
+	"audio/mpeg":      "audio",
+	"audio/ogg":       "audio",
+	"audio/wav":       "audio",
 	"video/mp4":       "video",
+	"video/webm":      "video",
 	"video/3gpp":      "video",
 	"video/x-msvideo": "video",
 	"video/x-ms-wmv":  "video",
+	"application/pdf": "document",
+}
+
+// sniffMimeTypeAlias mime-type detected from file content and the mime-type defined above
+var sniffMimeTypeAlias = map[string]string{
+	"video/avi":       "video/x-msvideo",
+	"audio/wave":      "audio/wav",
+	"application/ogg": "audio/ogg",
+}
+
+// unsniffableMimeType file types which can not be detected from content; the mime-type by ext is trusted
+// MP3 without ID3 tag starts with a frame which is not detected.
+var unsniffableMimeType = map[string]bool{
+	"audio/mpeg":     true,
+	"video/3gpp":     true,
+	"video/x-ms-wmv": true,
 }
 
 // GetFileExt returns the file name extension
@@ -40,14 +61,69 @@ func GetFileExt(file *multipart.FileHeader) string {
 	return ext
 }
 
+// fileExtMimeType mime-type of the file types defined above by ext
+// they are preferred to the system mime table, which has different names for some types, such as audio/x-wav
+var fileExtMimeType = map[string]string{
+	".txt":  "text/plain",
+	".mp3":  "audio/mpeg",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/ogg",
+	".wav":  "audio/wav",
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+	".pdf":  "application/pdf",
+	".3gp":  "video/3gpp",
+	".avi":  "video/x-msvideo",
+	".wmv":  "video/x-ms-wmv",
+}
+
 // GetFileMimeTypeByExt returns the file mine-type by ext
 func GetFileMimeTypeByExt(ext string) string {
+	if mimeType, ok := fileExtMimeType[strings.ToLower(ext)]; ok {
+		return mimeType
+	}
 	return mime.TypeByExtension(ext)
 }
 
+// DetectFileMimeType returns the mime-type detected from the first 512 bytes of file content
+// The mime-type by ext is returned if the content can not be detected and the ext is an unsniffable type.
+func DetectFileMimeType(data []byte, ext string) string {
+	mimeType := trimMimeTypeParams(http.DetectContentType(data))
+	if alias, ok := sniffMimeTypeAlias[mimeType]; ok {
+		mimeType = alias
+	}
+
+	if mimeType == "application/octet-stream" {
+		if extMimeType := trimMimeTypeParams(GetFileMimeTypeByExt(ext)); unsniffableMimeType[extMimeType] {
+			return extMimeType
+		}
+	}
+	return mimeType
+}
+
+// IsFileExtMatch check if the mime-type by ext is the same as the mime-type
+func IsFileExtMatch(ext, mimeType string) bool {
+	return trimMimeTypeParams(GetFileMimeTypeByExt(ext)) == mimeType
+}
+
+// trimMimeTypeParams remove params of the mime-type, such as charset
+func trimMimeTypeParams(mimeType string) string {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+// IsAllowedMimeType check if the mime-type is defined above, which is allowed to upload
+func IsAllowedMimeType(mimeType string) bool {
+	_, exists := fileMimeType[mimeType]
+	return exists
+}
+
 // GetFileType returns file type in word that defined
 func GetFileType(mimeType string) string {
-	fileType, exists := fileMimeType[mimeType]
+	fileType, exists := fileMimeType[trimMimeTypeParams(mimeType)]
 	if exists {
 		return fileType
 	}
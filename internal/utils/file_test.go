@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestDetectFileMimeType(t *testing.T) {
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+	tests := []struct {
+		name string
+		data []byte
+		ext  string
+		want string
+	}{
+		{"png", png, ".png", "image/png"},
+		{"png with jpg ext", png, ".jpg", "image/png"},
+		{"text", []byte("hello world"), ".txt", "text/plain"},
+		{"html", []byte("<html><body></body></html>"), ".txt", "text/html"},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), ".wav", "audio/wav"},
+		{"mp3 without id3 by ext", []byte{0xFF, 0xFB, 0x90, 0x00}, ".mp3", "audio/mpeg"},
+		{"pdf", []byte("%PDF-1.4\n"), ".pdf", "application/pdf"},
+		{"wmv by ext", []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}, ".wmv", "video/x-ms-wmv"},
+		{"unknown binary", []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}, ".exe", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFileMimeType(tt.data, tt.ext); got != tt.want {
+				t.Errorf("DetectFileMimeType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if IsFileExtMatch(".jpg", "image/png") {
+		t.Error("IsFileExtMatch(.jpg, image/png) = true, want false")
+	}
+	if !IsFileExtMatch(".TXT", "text/plain") {
+		t.Error("IsFileExtMatch(.TXT, text/plain) = false, want true")
+	}
+}
@@ -0,0 +1,114 @@
+package dao
+
+import (
+	"github.com/puti-projects/puti/internal/model"
+)
+
+// CommentPostResult post info which is needed before commenting
+type CommentPostResult struct {
+	ID            uint64
+	PostType      string
+	GUID          string
+	CommentStatus uint64
+}
+
+// GetCommentPost get the published post which will be commented
+func (d *Dao) GetCommentPost(postID uint64) (*CommentPostResult, error) {
+	result := &CommentPostResult{}
+	if err := d.db.Model(&model.Post{}).
+		Select("`id`, `post_type`, `guid`, `comment_status`").
+		Where("`id` = ? AND `parent_id` = ? AND `status` = ?", postID, 0, model.PostStatusPublish).
+		First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CheckCommentParent check if the parent comment is an approved comment of the same post
+func (d *Dao) CheckCommentParent(postID, parentID uint64) bool {
+	comment := &model.Comment{}
+	count, err := comment.Count(d.db, "`id` = ? AND `post_id` = ? AND `approved` = ?", []interface{}{parentID, postID, model.CommentStatusApproved})
+	if err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// CheckCommenterApprovedBefore check if the commenter already has an approved comment
+func (d *Dao) CheckCommenterApprovedBefore(email string) bool {
+	comment := &model.Comment{}
+	count, err := comment.Count(d.db, "`commenter_email` = ? AND `approved` = ?", []interface{}{email, model.CommentStatusApproved})
+	if err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// CreateComment create a comment and keep the post's comment count in sync
+func (d *Dao) CreateComment(comment *model.Comment) error {
+	tx := d.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := comment.Create(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if comment.Approved == model.CommentStatusApproved {
+		if err := comment.SyncPostCommentCount(tx, comment.PostID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// CountRootComments count approved top level comments of the post
+func (d *Dao) CountRootComments(postID uint64) (int64, error) {
+	comment := &model.Comment{}
+	return comment.Count(d.db, "`post_id` = ? AND `parent_id` = ? AND `approved` = ?", []interface{}{postID, 0, model.CommentStatusApproved})
+}
+
+// ListRootComments get approved top level comments of the post in page
+func (d *Dao) ListRootComments(postID uint64, offset, number int, order string) ([]*model.Comment, error) {
+	comment := &model.Comment{}
+	return comment.List(d.db, "`post_id` = ? AND `parent_id` = ? AND `approved` = ?", []interface{}{postID, 0, model.CommentStatusApproved}, offset, number, order)
+}
+
+// ListReplyComments get all approved replies of the post
+func (d *Dao) ListReplyComments(postID uint64) ([]*model.Comment, error) {
+	comment := &model.Comment{}
+	return comment.List(d.db, "`post_id` = ? AND `parent_id` != ? AND `approved` = ?", []interface{}{postID, 0, model.CommentStatusApproved}, -1, -1, "`comment_date` ASC")
+}
+
+// GetCommenter get the login user who is commenting
+func (d *Dao) GetCommenter(userID uint64) (*model.User, error) {
+	user := &model.User{}
+	if err := d.db.Where("`id` = ? AND `status` = ?", userID, 1).First(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserAvatars get avatars of users, the key of map is user id
+func (d *Dao) GetUserAvatars(userIDs []uint64) (map[uint64]string, error) {
+	var users []*model.User
+	if err := d.db.Select("`id`, `avatar`").Where("`id` IN (?)", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	avatars := make(map[uint64]string, len(users))
+	for _, u := range users {
+		avatars[u.ID] = u.Avatar
+	}
+	return avatars, nil
+}
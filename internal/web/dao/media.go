@@ -0,0 +1,35 @@
+package dao
+
+import (
+	"github.com/puti-projects/puti/internal/model"
+)
+
+// GetImageSizeMeta get the image size meta value of the media by its url
+// It returns an empty string if the media or the size does not exist
+func (d *Dao) GetImageSizeMeta(guid, size string) (string, error) {
+	var values []string
+	if err := d.db.Table("pt_resource_meta AS rm").
+		Joins("INNER JOIN pt_resource AS r ON r.`id` = rm.`resource_id`").
+		Where("r.`guid` = ? AND r.`deleted_time` IS NULL AND rm.`meta_key` = ?", guid, model.ResourceMetaImageSizePrefix+size).
+		Limit(1).
+		Pluck("rm.`meta_value`", &values).Error; err != nil {
+		return "", err
+	}
+
+	if len(values) == 0 {
+		return "", nil
+	}
+	return values[0], nil
+}
+
+// ListResourceMetaByGUID get all metas of the media by its url, such as image sizes and media info
+func (d *Dao) ListResourceMetaByGUID(guid string) ([]*model.ResourceMeta, error) {
+	metas := make([]*model.ResourceMeta, 0)
+	err := d.db.Table("pt_resource_meta AS rm").
+		Select("rm.*").
+		Joins("INNER JOIN pt_resource AS r ON r.`id` = rm.`resource_id`").
+		Where("r.`guid` = ? AND r.`deleted_time` IS NULL", guid).
+		Order("rm.`meta_id` ASC").
+		Find(&metas).Error
+	return metas, err
+}
@@ -0,0 +1,114 @@
+package dao
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/puti-projects/puti/internal/model"
+)
+
+// SitemapPostResult published article or page for sitemap
+type SitemapPostResult struct {
+	PostType  string
+	GUID      string
+	UpdatedAt time.Time `gorm:"column:updated_time"`
+}
+
+// ListSitemapPosts get all published articles and pages
+func (d *Dao) ListSitemapPosts() ([]*SitemapPostResult, error) {
+	var result []*SitemapPostResult
+	if err := d.db.Model(&model.Post{}).
+		Select("`post_type`, `guid`, `updated_time`").
+		Where("`parent_id` = ? AND `status` = ?", 0, model.PostStatusPublish).
+		Order("`updated_time` DESC").
+		Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SitemapTermResult category or tag which has published articles
+type SitemapTermResult struct {
+	Taxonomy    string
+	Slug        string
+	LastUpdated time.Time
+}
+
+// ListSitemapTerms get categories and tags which have published articles
+// the last updated time is the latest updated time of their articles
+func (d *Dao) ListSitemapTerms() ([]*SitemapTermResult, error) {
+	var result []*SitemapTermResult
+	if err := d.db.Table("pt_term AS t").
+		Select("tt.`taxonomy`, t.`slug`, MAX(p.`updated_time`) AS last_updated").
+		Joins("INNER JOIN pt_term_taxonomy AS tt ON tt.`term_id` = t.`term_id`").
+		Joins("INNER JOIN pt_term_relationships AS tr ON tr.`term_taxonomy_id` = tt.`term_taxonomy_id`").
+		Joins("INNER JOIN pt_post AS p ON p.`id` = tr.`object_id`").
+		Where("tt.`taxonomy` IN (?) AND p.`post_type` = ? AND p.`parent_id` = ? AND p.`status` = ? AND p.`deleted_time` IS NULL",
+			[]string{"category", "tag"}, model.PostTypeArticle, 0, model.PostStatusPublish).
+		Group("tt.`term_taxonomy_id`, tt.`taxonomy`, t.`slug`").
+		Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SitemapSubjectResult subject for sitemap
+type SitemapSubjectResult struct {
+	Slug        string
+	LastUpdated sql.NullTime
+	UpdatedAt   time.Time `gorm:"column:updated_time"`
+}
+
+// ListSitemapSubjects get all subjects
+func (d *Dao) ListSitemapSubjects() ([]*SitemapSubjectResult, error) {
+	var result []*SitemapSubjectResult
+	if err := d.db.Model(&model.Subject{}).
+		Select("`slug`, `last_updated`, `updated_time`").
+		Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SitemapKnowledgeResult knowledge base for sitemap
+type SitemapKnowledgeResult struct {
+	Type        string
+	Slug        string
+	LastUpdated sql.NullTime
+	UpdatedAt   time.Time `gorm:"column:updated_time"`
+}
+
+// ListSitemapKnowledge get all knowledge bases
+func (d *Dao) ListSitemapKnowledge() ([]*SitemapKnowledgeResult, error) {
+	var result []*SitemapKnowledgeResult
+	if err := d.db.Model(&model.Knowledge{}).
+		Select("`type`, `slug`, `last_updated`, `updated_time`").
+		Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SitemapKnowledgeItemResult knowledge item for sitemap
+type SitemapKnowledgeItemResult struct {
+	Type          string
+	Slug          string
+	Symbol        uint64
+	LastPublished sql.NullTime
+	UpdatedAt     time.Time `gorm:"column:updated_time"`
+}
+
+// ListSitemapKnowledgeItems get all published items of knowledge bases
+// items which were never published have no content to show, so they are not listed
+func (d *Dao) ListSitemapKnowledgeItems() ([]*SitemapKnowledgeItemResult, error) {
+	var result []*SitemapKnowledgeItemResult
+	if err := d.db.Table("pt_knowledge_item AS ki").
+		Select("k.`type`, k.`slug`, ki.`symbol`, ki.`last_published`, ki.`updated_time`").
+		Joins("INNER JOIN pt_knowledge AS k ON k.`id` = ki.`knowledge_id`").
+		Where("ki.`deleted_time` IS NULL AND k.`deleted_time` IS NULL AND ki.`last_published` IS NOT NULL").
+		Order("ki.`knowledge_id` ASC, ki.`index` ASC").
+		Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -14,7 +14,10 @@ import (
 	"github.com/puti-projects/puti/internal/pkg/config"
 	"github.com/puti-projects/puti/internal/pkg/db"
 	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/markdown"
 	"github.com/puti-projects/puti/internal/utils"
+
+	"gorm.io/gorm"
 )
 
 // List post list
@@ -31,28 +34,13 @@ func GetArticleListByTaxonomy(currentPage int, taxonomyType, taxonomySlug, keywo
 	var count int64 = 0
 
 	// get term id
-	var termTaxonomyID uint64
-	getTermTaxonomyID := db.Engine.Table("pt_term as t").
-		Select("t.`name`, tt.`term_taxonomy_id`").
-		Joins("INNER JOIN pt_term_taxonomy as tt ON tt.term_id = t.term_id").
-		Where("t.`slug` = ? AND tt.`taxonomy` = ?", taxonomySlug, taxonomyType).
-		Row()
-	getTermTaxonomyID.Scan(&termName, &termTaxonomyID)
+	termName, termTaxonomyID := getTermTaxonomy(taxonomyType, taxonomySlug)
 
 	// get article list
-	where := "p.`deleted_time` IS NULL AND p.`post_type` = ? AND p.`parent_id` = ? AND p.`status` = ? AND tr.`term_taxonomy_id` = ?"
-	whereArgs := []interface{}{model.PostTypeArticle, 0, model.PostStatusPublish, termTaxonomyID}
-	if "" != keyword {
-		where += " AND p.`title` LIKE ?"
-		whereArgs = append(whereArgs, "%"+keyword+"%")
-	}
-
 	var articles []*model.Post
-	result := db.Engine.Table("pt_post AS p").
+	result := publishedTaxonomyArticlesQuery(termTaxonomyID, keyword).
 		Select("p.`id`, p.`title`, p.`if_top`, p.`content_html`, p.`guid`, p.`cover_picture`, p.`comment_count`, p.`view_count`, p.`posted_time`").
-		Joins("INNER JOIN pt_term_relationships AS tr ON tr.object_id = p.id").
-		Unscoped().
-		Where(where, whereArgs...).Count(&count).
+		Count(&count).
 		Order("p.`if_top` DESC, p.`posted_time` DESC").
 		Offset(offset).Limit(pageSize).
 		Find(&articles)
@@ -139,17 +127,10 @@ func GetArticleList(currentPage int, keyword string) (articleResult []*ShowArtic
 	offset := (currentPage - 1) * pageSize
 	var count int64 = 0
 
-	where := "`post_type` = ? AND `parent_id` = ? AND `status` = ?"
-	whereArgs := []interface{}{model.PostTypeArticle, 0, model.PostStatusPublish}
-	if "" != keyword {
-		where += " AND `title` LIKE ?"
-		whereArgs = append(whereArgs, "%"+keyword+"%")
-	}
-
 	var articles []*model.Post
-	result := db.Engine.Model(&model.Post{}).
+	result := publishedArticlesQuery(keyword).
 		Select("`id`, `title`, `if_top`, `content_html`, `guid`, `cover_picture`, `comment_count`, `view_count`, `posted_time`").
-		Where(where, whereArgs...).Count(&count).
+		Count(&count).
 		Order("`if_top` DESC, `posted_time` DESC").
 		Offset(offset).Limit(pageSize).
 		Find(&articles)
@@ -229,6 +210,56 @@ func GetArticleList(currentPage int, keyword string) (articleResult []*ShowArtic
 	return
 }
 
+// getTermTaxonomy get term name and term taxonomy id by taxonomy type and term slug
+// termTaxonomyID is 0 if the term does not exist
+func getTermTaxonomy(taxonomyType, taxonomySlug string) (termName string, termTaxonomyID uint64) {
+	getTermTaxonomyID := db.Engine.Table("pt_term as t").
+		Select("t.`name`, tt.`term_taxonomy_id`").
+		Joins("INNER JOIN pt_term_taxonomy as tt ON tt.term_id = t.term_id").
+		Where("t.`slug` = ? AND tt.`taxonomy` = ?", taxonomySlug, taxonomyType).
+		Row()
+	getTermTaxonomyID.Scan(&termName, &termTaxonomyID)
+
+	return
+}
+
+// publishedArticlesQuery the query of published articles; it is shared by article list and feed
+func publishedArticlesQuery(keyword string) *gorm.DB {
+	where := "`post_type` = ? AND `parent_id` = ? AND `status` = ?"
+	whereArgs := []interface{}{model.PostTypeArticle, 0, model.PostStatusPublish}
+	if "" != keyword {
+		where += " AND `title` LIKE ?"
+		whereArgs = append(whereArgs, "%"+keyword+"%")
+	}
+
+	return db.Engine.Model(&model.Post{}).Where(where, whereArgs...)
+}
+
+// publishedTaxonomyArticlesQuery the query of published articles under the term taxonomy (aliased as p)
+func publishedTaxonomyArticlesQuery(termTaxonomyID uint64, keyword string) *gorm.DB {
+	where := "p.`deleted_time` IS NULL AND p.`post_type` = ? AND p.`parent_id` = ? AND p.`status` = ? AND tr.`term_taxonomy_id` = ?"
+	whereArgs := []interface{}{model.PostTypeArticle, 0, model.PostStatusPublish, termTaxonomyID}
+	if "" != keyword {
+		where += " AND p.`title` LIKE ?"
+		whereArgs = append(whereArgs, "%"+keyword+"%")
+	}
+
+	return db.Engine.Table("pt_post AS p").
+		Joins("INNER JOIN pt_term_relationships AS tr ON tr.object_id = p.id").
+		Unscoped().
+		Where(where, whereArgs...)
+}
+
+// publishedSubjectArticlesQuery the query of published articles connected to subject (aliased as p)
+func publishedSubjectArticlesQuery(subjectID uint64) *gorm.DB {
+	postModel := &model.Post{}
+	srModel := &model.SubjectRelationships{}
+	return db.Engine.Table(postModel.TableName()+" p").
+		Joins("INNER JOIN "+srModel.TableName()+" sr ON sr.`object_id` = p.`id`").
+		Where("p.`post_type` = ? AND p.`parent_id` = ? AND p.`status` = ? AND sr.`subject_id` = ? AND p.`deleted_time` is null",
+			model.PostTypeArticle, 0, model.PostStatusPublish, subjectID)
+}
+
 func getArticleTaxonomyInfo(articleID uint64, siteURL string) ([]*ShowCategory, []*ShowTag, error) {
 	rawSQL := "SELECT t.`name`, t.`slug`, tt.`taxonomy` FROM pt_term t LEFT JOIN pt_term_taxonomy tt ON tt.`term_id` = t.`term_id` LEFT JOIN pt_term_relationships tr ON tr.`term_taxonomy_id` = tt.`term_taxonomy_id` WHERE tr.`object_id` = ?"
 	rows, err := db.Engine.Raw(rawSQL, articleID).Rows()
@@ -313,7 +344,8 @@ func GetArticleDetailByID(articleID uint64) (*ShowArticleDetail, error) {
 	articleDetail := &ShowArticleDetail{
 		ID:            a.ID,
 		Title:         a.Title,
-		ContentHTML:   template.HTML(a.ContentHTML),
+		ContentHTML:   template.HTML(SrvEngine.rewriteContentImages(a.ContentHTML)),
+		ToC:           markdown.ToC(a.ContentHTML),
 		CommentStatus: a.CommentStatus,
 		GUID:          siteURL + a.GUID,
 		CommentCount:  a.CommentCount,
@@ -377,13 +409,8 @@ func GetNextArticle(currentArticleID uint64) *ShowLastOrNextArticle {
 func GetSubjectArticleList(subjectID uint64) ([]*map[string]interface{}, error) {
 	var articleList []*map[string]interface{}
 
-	postModel := &model.Post{}
-	srModel := &model.SubjectRelationships{}
-	rows, err := db.Engine.Table(postModel.TableName()+" p").
+	rows, err := publishedSubjectArticlesQuery(subjectID).
 		Select("p.`id`, p.`title`, p.`guid`, p.`comment_count`, p.`view_count`, p.`posted_time`").
-		Joins("INNER JOIN "+srModel.TableName()+" sr ON sr.`object_id` = p.`id`").
-		Where("p.`post_type` = ? AND p.`parent_id` = ? AND p.`status` = ? AND sr.`subject_id` = ? AND p.`deleted_time` is null",
-			model.PostTypeArticle, 0, model.PostStatusPublish, subjectID).
 		Order("p.`posted_time` DESC").
 		Rows()
 	if err != nil {
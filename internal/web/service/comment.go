@@ -0,0 +1,288 @@
+package service
+
+import (
+	"html/template"
+	"math"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/token"
+	"github.com/puti-projects/puti/internal/utils"
+)
+
+// CommentCreateRequest comment params posted by visitor
+type CommentCreateRequest struct {
+	ParentID uint64 `form:"parent_id" json:"parent_id"`
+	Content  string `form:"content" json:"content"`
+	Name     string `form:"name" json:"name"`
+	Email    string `form:"email" json:"email"`
+	URL      string `form:"url" json:"url"`
+}
+
+// CommentCreateResponse return the new comment id and whether it is waiting for moderation
+type CommentCreateResponse struct {
+	ID      uint64 `json:"id"`
+	Pending bool   `json:"pending"`
+	URL     string `json:"url"`
+}
+
+const (
+	commentMaxLength       = 5000
+	commenterNameMaxLength = 60
+	commenterURLMaxLength  = 200
+	commentAgentMaxLength  = 255
+)
+
+// CreateComment check and save the comment of article or page
+// user is the login user context, it is nil for visitor
+func (svc *Engine) CreateComment(postID uint64, r *CommentCreateRequest, user *token.Context, ip, agent string) (*CommentCreateResponse, error) {
+	post, err := svc.dao.GetCommentPost(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !commentOpen(post.PostType, post.CommentStatus) {
+		return nil, errno.ErrCommentClosed
+	}
+
+	if user == nil && cache.Options.Get("comment_need_register") == "yes" {
+		return nil, errno.ErrCommentNeedLogin
+	}
+
+	comment := &model.Comment{
+		ParentID:    r.ParentID,
+		PostID:      post.ID,
+		Content:     strings.TrimSpace(r.Content),
+		IfVisitor:   1,
+		CommenterIP: ip,
+		CommentDate: time.Now(),
+		Agent:       agent,
+	}
+	if len(comment.Agent) > commentAgentMaxLength {
+		comment.Agent = comment.Agent[:commentAgentMaxLength]
+	}
+
+	if user != nil {
+		u, err := svc.dao.GetCommenter(user.ID)
+		if err != nil {
+			return nil, errno.ErrCommentNeedLogin
+		}
+		comment.IfVisitor = 0
+		comment.CommenterUserID = u.ID
+		comment.CommenterName = u.Nickname
+		comment.CommenterEmail = u.Email
+		comment.CommenterURL = u.PageURL
+	} else {
+		comment.CommenterName = strings.TrimSpace(r.Name)
+		comment.CommenterEmail = strings.TrimSpace(r.Email)
+		comment.CommenterURL = strings.TrimSpace(r.URL)
+	}
+
+	if err := checkComment(comment); err != nil {
+		return nil, err
+	}
+
+	if comment.ParentID != 0 && !svc.dao.CheckCommentParent(post.ID, comment.ParentID) {
+		return nil, errno.ErrCommentParentID
+	}
+
+	comment.Approved = svc.getNewCommentStatus(comment.CommenterEmail)
+	if err := svc.dao.CreateComment(comment); err != nil {
+		return nil, errno.New(errno.ErrCommentCreateFailed, err)
+	}
+
+	// comment count changed; clean the cache of post detail
+	if comment.Approved == model.CommentStatusApproved {
+		svc.cleanPostCache(post.PostType, post.ID)
+	}
+
+	rsp := &CommentCreateResponse{
+		ID:      comment.ID,
+		Pending: comment.Approved != model.CommentStatusApproved,
+		URL:     post.GUID + "#comment-" + strconv.Itoa(int(comment.ID)),
+	}
+	return rsp, nil
+}
+
+// commentOpen check both the post comment status and the global comment setting
+func commentOpen(postType string, commentStatus uint64) bool {
+	if commentStatus != 1 {
+		return false
+	}
+
+	return cache.Options.Get(postType+"_comment_status") == "open"
+}
+
+// checkComment validate comment content and commenter info
+func checkComment(comment *model.Comment) error {
+	if comment.Content == "" {
+		return errno.New(errno.ErrValidation, nil).Add("content can not be empty.")
+	}
+	if len([]rune(comment.Content)) > commentMaxLength {
+		return errno.New(errno.ErrValidation, nil).Add("content is too long.")
+	}
+
+	if comment.CommenterName == "" {
+		return errno.New(errno.ErrValidation, nil).Add("name can not be empty.")
+	}
+	if len([]rune(comment.CommenterName)) > commenterNameMaxLength {
+		return errno.New(errno.ErrValidation, nil).Add("name is too long.")
+	}
+
+	if comment.CommenterEmail == "" {
+		return errno.New(errno.ErrValidation, nil).Add("email can not be empty.")
+	}
+	if _, err := mail.ParseAddress(comment.CommenterEmail); err != nil {
+		return errno.New(errno.ErrValidation, nil).Add("email is incorrect.")
+	}
+
+	if comment.CommenterURL != "" {
+		u, err := url.Parse(comment.CommenterURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(comment.CommenterURL) > commenterURLMaxLength {
+			return errno.New(errno.ErrValidation, nil).Add("site url is incorrect.")
+		}
+	}
+
+	return nil
+}
+
+// getNewCommentStatus get the status of new comment by the comment_before_show setting
+func (svc *Engine) getNewCommentStatus(email string) string {
+	switch cache.Options.Get("comment_before_show") {
+	case "manualApproval":
+		return model.CommentStatusPending
+	case "commentPass":
+		if svc.dao.CheckCommenterApprovedBefore(email) {
+			return model.CommentStatusApproved
+		}
+		return model.CommentStatusPending
+	default:
+		return model.CommentStatusApproved
+	}
+}
+
+// cleanPostCache clean the detail cache of article or page
+func (svc *Engine) cleanPostCache(postType string, postID uint64) {
+	if postType == model.PostTypePage {
+		svc.DeleteCache(config.CachePageDetailPrefix + strconv.Itoa(int(postID)))
+		return
+	}
+	svc.DeleteCache(config.CacheArticleDetailPrefix + strconv.Itoa(int(postID)))
+}
+
+// GetCommentList get approved comments of the post in thread and page
+// currentPage is 0 if it is not specified, then the first page is decided by the comment_page_first setting
+func (svc *Engine) GetCommentList(postID uint64, postType string, commentStatus uint64, pageURL string, currentPage int) (*ShowCommentList, error) {
+	list := &ShowCommentList{
+		PostID:     postID,
+		Open:       commentOpen(postType, commentStatus),
+		NeedLogin:  cache.Options.Get("comment_need_register") == "yes",
+		ShowAvatar: cache.Options.Get("show_avatar") == "on",
+		Comments:   make([]*ShowComment, 0),
+		PageURL:    pageURL,
+	}
+
+	total, err := svc.dao.CountRootComments(postID)
+	if err != nil {
+		return nil, err
+	}
+	list.Total = total
+
+	// page settings
+	pageSize := int(total)
+	if cache.Options.Get("show_comment_page") == "on" {
+		if perPage, _ := strconv.Atoi(cache.Options.Get("comment_per_page")); perPage > 0 {
+			pageSize = perPage
+		}
+	}
+	if pageSize == 0 {
+		pageSize = 1
+	}
+	totalPage := int(math.Ceil(float64(total) / float64(pageSize)))
+	if currentPage <= 0 || currentPage > totalPage {
+		currentPage = 1
+		if cache.Options.Get("comment_page_first") == "last" && totalPage > 0 {
+			currentPage = totalPage
+		}
+	}
+	list.Pagination = utils.GetPagination(int(total), currentPage, pageSize, 0).Page
+
+	order := "`comment_date` ASC"
+	if cache.Options.Get("comment_page_top") == "new" {
+		order = "`comment_date` DESC"
+	}
+
+	roots, err := svc.dao.ListRootComments(postID, (currentPage-1)*pageSize, pageSize, order)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return list, nil
+	}
+
+	replies, err := svc.dao.ListReplyComments(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	avatars, err := svc.getCommenterAvatars(append(roots, replies...))
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint64][]*model.Comment)
+	for _, reply := range replies {
+		children[reply.ParentID] = append(children[reply.ParentID], reply)
+	}
+	for _, root := range roots {
+		list.Comments = append(list.Comments, generateCommentTree(root, children, avatars))
+	}
+
+	return list, nil
+}
+
+// getCommenterAvatars get avatars of the registered commenters
+func (svc *Engine) getCommenterAvatars(comments []*model.Comment) (map[uint64]string, error) {
+	var userIDs []uint64
+	for _, c := range comments {
+		if c.CommenterUserID != 0 {
+			userIDs = append(userIDs, c.CommenterUserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return map[uint64]string{}, nil
+	}
+
+	return svc.dao.GetUserAvatars(userIDs)
+}
+
+func generateCommentTree(c *model.Comment, children map[uint64][]*model.Comment, avatars map[uint64]string) *ShowComment {
+	node := &ShowComment{
+		ID:          c.ID,
+		ParentID:    c.ParentID,
+		Author:      c.CommenterName,
+		AuthorURL:   c.CommenterURL,
+		Avatar:      avatars[c.CommenterUserID],
+		Content:     formatCommentContent(c.Content),
+		CommentDate: utils.GetFormatTime(&c.CommentDate, "2006-01-02 15:04"),
+		Children:    make([]*ShowComment, 0),
+	}
+
+	for _, child := range children[c.ID] {
+		node.Children = append(node.Children, generateCommentTree(child, children, avatars))
+	}
+	return node
+}
+
+// formatCommentContent escape the comment and keep the line breaks
+func formatCommentContent(content string) template.HTML {
+	escaped := template.HTMLEscapeString(content)
+	return template.HTML(strings.ReplaceAll(escaped, "\n", "<br>"))
+}
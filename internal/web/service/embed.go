@@ -0,0 +1,95 @@
+package service
+
+import (
+	"html"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/storage"
+	"github.com/puti-projects/puti/internal/utils"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// renderMediaEmbed render the image whose src is audio, video or PDF into a player or viewer
+// It is the embed shortcut of markdown, such as ![My talk](/uploads/2020/01/abc.mp4).
+// The alt text is the fallback link for browsers which can not play the media.
+// Only the uploaded media is embedded; it returns an empty string if the src is not an embeddable media,
+// so that media of other sites are left as images, like the elements removed by the sanitizer.
+func (svc *Engine) renderMediaEmbed(token *xhtml.Token) string {
+	var src, alt string
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "src":
+			src = attr.Val
+		case "alt":
+			alt = attr.Val
+		}
+	}
+
+	srcPath := src
+	if i := strings.IndexAny(srcPath, "?#"); i >= 0 {
+		srcPath = srcPath[:i]
+	}
+	mimeType := utils.GetFileMimeTypeByExt(path.Ext(srcPath))
+	fileType := utils.GetFileType(mimeType)
+	if fileType != model.ResourceTypeAudio && fileType != model.ResourceTypeVideo && fileType != model.ResourceTypeDocument {
+		return ""
+	}
+	media := svc.getContentImage(storage.PathOf(src))
+	if media == nil {
+		return ""
+	}
+
+	// the fallback link
+	if alt == "" {
+		alt = path.Base(srcPath)
+	}
+	fallback := `<a href="` + html.EscapeString(src) + `">` + html.EscapeString(alt) + `</a>`
+
+	attrs := []xhtml.Attribute{{Key: "class", Val: "puti-embed puti-embed-" + fileType}}
+
+	var tag string
+	switch fileType {
+	case model.ResourceTypeAudio:
+		tag = "audio"
+		attrs = append(attrs,
+			xhtml.Attribute{Key: "src", Val: src},
+			xhtml.Attribute{Key: "controls"},
+			xhtml.Attribute{Key: "preload", Val: "metadata"},
+		)
+	case model.ResourceTypeVideo:
+		tag = "video"
+		attrs = append(attrs,
+			xhtml.Attribute{Key: "src", Val: src},
+			xhtml.Attribute{Key: "controls"},
+			xhtml.Attribute{Key: "preload", Val: "metadata"},
+			xhtml.Attribute{Key: "playsinline"},
+		)
+		if media.Poster != "" {
+			attrs = append(attrs, xhtml.Attribute{Key: "poster", Val: media.Poster})
+		}
+		if media.Width > 0 && media.Height > 0 {
+			attrs = append(attrs,
+				xhtml.Attribute{Key: "width", Val: strconv.Itoa(media.Width)},
+				xhtml.Attribute{Key: "height", Val: strconv.Itoa(media.Height)},
+			)
+		}
+	case model.ResourceTypeDocument:
+		tag = "object"
+		attrs = append(attrs,
+			xhtml.Attribute{Key: "data", Val: src},
+			xhtml.Attribute{Key: "type", Val: mimeType},
+		)
+		// the first page preview is shown if the browser can not view PDF
+		if media.Poster != "" {
+			fallback = `<a href="` + html.EscapeString(src) + `"><img src="` + html.EscapeString(media.Poster) +
+				`" alt="` + html.EscapeString(alt) + `" loading="lazy"></a>`
+		}
+	}
+
+	start := xhtml.Token{Type: xhtml.StartTagToken, Data: tag, Attr: attrs}
+	return start.String() + fallback + "</" + tag + ">"
+}
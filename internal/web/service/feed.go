@@ -0,0 +1,356 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/config"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// FeedTypeRSS RSS 2.0 feed
+	FeedTypeRSS = "rss"
+	// FeedTypeAtom Atom 1.0 feed
+	FeedTypeAtom = "atom"
+
+	// FeedScopeCategory feed of articles in category
+	FeedScopeCategory = "category"
+	// FeedScopeTag feed of articles in tag
+	FeedScopeTag = "tag"
+	// FeedScopeSubject feed of articles in subject
+	FeedScopeSubject = "subject"
+
+	feedGenerator      = "Puti"
+	feedDefaultItemNum = 10
+)
+
+// feedArticleResult use for scan article of feed
+type feedArticleResult struct {
+	ID          uint64
+	Title       string
+	ContentHTML string
+	GUID        string
+	PostDate    sql.NullTime `gorm:"column:posted_time"`
+	UpdatedAt   time.Time    `gorm:"column:updated_time"`
+}
+
+// feedChannel feed data before it is encoded as RSS or Atom
+type feedChannel struct {
+	Title       string
+	Link        string
+	FeedLink    string
+	Description string
+	Author      string
+	Updated     time.Time
+	Items       []*feedItem
+}
+
+type feedItem struct {
+	Title      string
+	Link       string
+	Abstract   string
+	Content    string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+type rss struct {
+	XMLName   xml.Name    `xml:"rss"`
+	Version   string      `xml:"version,attr"`
+	ContentNS string      `xml:"xmlns:content,attr"`
+	AtomNS    string      `xml:"xmlns:atom,attr"`
+	Channel   *rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	AtomLink      *atomLink  `xml:"atom:link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Generator     string     `xml:"generator"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string      `xml:"title"`
+	Link        string      `xml:"link"`
+	GUID        string      `xml:"guid"`
+	Description string      `xml:"description"`
+	Content     *rssContent `xml:"content:encoded"`
+	Categories  []string    `xml:"category"`
+	PubDate     string      `xml:"pubDate"`
+}
+
+type rssContent struct {
+	Content string `xml:",cdata"`
+}
+
+type atomFeed struct {
+	XMLName   xml.Name     `xml:"feed"`
+	NS        string       `xml:"xmlns,attr"`
+	Title     string       `xml:"title"`
+	Subtitle  string       `xml:"subtitle,omitempty"`
+	ID        string       `xml:"id"`
+	Updated   string       `xml:"updated"`
+	Links     []*atomLink  `xml:"link"`
+	Author    *atomPerson  `xml:"author"`
+	Generator string       `xml:"generator"`
+	Entries   []*atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title      string          `xml:"title"`
+	ID         string          `xml:"id"`
+	Link       *atomLink       `xml:"link"`
+	Published  string          `xml:"published"`
+	Updated    string          `xml:"updated"`
+	Categories []*atomCategory `xml:"category"`
+	Summary    *atomText       `xml:"summary"`
+	Content    *atomText       `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// GetFeed get the RSS or Atom feed in XML
+// scope is empty for the site-wide feed, otherwise slug is the category, tag or subject slug
+// It returns gorm.ErrRecordNotFound if the category, tag or subject does not exist
+func (svc *Engine) GetFeed(feedType, scope, slug string) ([]byte, error) {
+	cacheKey := config.CacheFeedPrefix + svc.getFeedVersion() + "_" + feedType + "_" + scope + "_" + slug
+	if data, exist := svc.GetCache(cacheKey); exist {
+		return data, nil
+	}
+
+	channel, err := getFeedChannel(feedType, scope, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if feedType == FeedTypeAtom {
+		data, err = encodeAtom(channel)
+	} else {
+		data, err = encodeRSS(channel)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.SetCache(cacheKey, data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// getFeedVersion get the feed cache version which is changed after the content was edited in console
+func (svc *Engine) getFeedVersion() string {
+	if version, exist := svc.GetCache(config.CacheFeedVersionKey); exist {
+		return string(version)
+	}
+	return "0"
+}
+
+// getFeedChannel get the channel info and the latest published articles of the feed
+func getFeedChannel(feedType, scope, slug string) (*feedChannel, error) {
+	siteURL := strings.TrimSuffix(cache.Options.Get("site_url"), "/")
+	blogName := cache.Options.Get("blog_name")
+
+	itemNum, _ := strconv.Atoi(cache.Options.Get("posts_per_page"))
+	if itemNum <= 0 {
+		itemNum = feedDefaultItemNum
+	}
+
+	feedPath := config.PathRSS
+	if feedType == FeedTypeAtom {
+		feedPath = config.PathAtom
+	}
+
+	channel := &feedChannel{
+		Title:       blogName,
+		Link:        siteURL + "/",
+		FeedLink:    siteURL + feedPath,
+		Description: cache.Options.Get("blog_description"),
+		Author:      blogName,
+	}
+
+	var query *gorm.DB
+	switch scope {
+	case FeedScopeCategory, FeedScopeTag:
+		termName, termTaxonomyID := getTermTaxonomy(scope, slug)
+		if termTaxonomyID == 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+
+		basePath := config.PathCategory
+		if scope == FeedScopeTag {
+			basePath = config.PathTag
+		}
+		channel.Title = termName + " - " + blogName
+		channel.Link = siteURL + basePath + "/" + slug
+		channel.FeedLink = siteURL + basePath + "/" + slug + feedPath
+		query = publishedTaxonomyArticlesQuery(termTaxonomyID, "")
+	case FeedScopeSubject:
+		subject, err := GetSubjectInfoBySlug(slug)
+		if err != nil {
+			return nil, err
+		}
+		if subject.ID == 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+
+		channel.Title = subject.Name + " - " + blogName
+		channel.Link = siteURL + config.PathSubject + "/" + slug
+		channel.FeedLink = siteURL + config.PathSubject + "/" + slug + feedPath
+		if subject.Description != "" {
+			channel.Description = subject.Description
+		}
+		query = publishedSubjectArticlesQuery(subject.ID)
+	default:
+		query = publishedArticlesQuery("").Table("pt_post AS p")
+	}
+
+	var articles []*feedArticleResult
+	if err := query.
+		Select("p.`id`, p.`title`, p.`content_html`, p.`guid`, p.`posted_time`, p.`updated_time`").
+		Order("p.`posted_time` DESC").
+		Limit(itemNum).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	for _, a := range articles {
+		articleCategory, articleTag, err := getArticleTaxonomyInfo(a.ID, siteURL)
+		if err != nil {
+			return nil, err
+		}
+
+		item := &feedItem{
+			Title:     a.Title,
+			Link:      siteURL + a.GUID,
+			Abstract:  getArticleAbstract(a.ContentHTML),
+			Content:   a.ContentHTML,
+			Published: a.PostDate.Time,
+			Updated:   a.UpdatedAt,
+		}
+		if !a.PostDate.Valid {
+			item.Published = a.UpdatedAt
+		}
+		for _, c := range articleCategory {
+			item.Categories = append(item.Categories, c.Title)
+		}
+		for _, t := range articleTag {
+			item.Categories = append(item.Categories, t.Title)
+		}
+
+		if item.Updated.After(channel.Updated) {
+			channel.Updated = item.Updated
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	if channel.Updated.IsZero() {
+		channel.Updated = time.Now()
+	}
+
+	return channel, nil
+}
+
+// encodeRSS encode the feed as RSS 2.0
+func encodeRSS(channel *feedChannel) ([]byte, error) {
+	r := &rss{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		Channel: &rssChannel{
+			Title:         channel.Title,
+			Link:          channel.Link,
+			AtomLink:      &atomLink{Href: channel.FeedLink, Rel: "self", Type: "application/rss+xml"},
+			Description:   channel.Description,
+			LastBuildDate: channel.Updated.Format(time.RFC1123Z),
+			Generator:     feedGenerator,
+			Items:         make([]*rssItem, 0, len(channel.Items)),
+		},
+	}
+
+	for _, item := range channel.Items {
+		r.Channel.Items = append(r.Channel.Items, &rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        item.Link,
+			Description: item.Abstract,
+			Content:     &rssContent{Content: item.Content},
+			Categories:  item.Categories,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		})
+	}
+
+	return marshalFeed(r)
+}
+
+// encodeAtom encode the feed as Atom 1.0
+func encodeAtom(channel *feedChannel) ([]byte, error) {
+	f := &atomFeed{
+		NS:       "http://www.w3.org/2005/Atom",
+		Title:    channel.Title,
+		Subtitle: channel.Description,
+		ID:       channel.Link,
+		Updated:  channel.Updated.Format(time.RFC3339),
+		Links: []*atomLink{
+			{Href: channel.Link, Rel: "alternate", Type: "text/html"},
+			{Href: channel.FeedLink, Rel: "self", Type: "application/atom+xml"},
+		},
+		Author:    &atomPerson{Name: channel.Author},
+		Generator: feedGenerator,
+		Entries:   make([]*atomEntry, 0, len(channel.Items)),
+	}
+
+	for _, item := range channel.Items {
+		entry := &atomEntry{
+			Title:     item.Title,
+			ID:        item.Link,
+			Link:      &atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"},
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Updated.Format(time.RFC3339),
+			Summary:   &atomText{Type: "text", Body: item.Abstract},
+			Content:   &atomText{Type: "html", Body: item.Content},
+		}
+		for _, c := range item.Categories {
+			entry.Categories = append(entry.Categories, &atomCategory{Term: c})
+		}
+		f.Entries = append(f.Entries, entry)
+	}
+
+	return marshalFeed(f)
+}
+
+func marshalFeed(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/storage"
+
+	"golang.org/x/net/html"
+)
+
+// imageSizePathRegexp matches the path of image derivative, such as /uploads/2020/01/abc-150x150.jpg
+var imageSizePathRegexp = regexp.MustCompile(`-(\d+)x(\d+)\.[^./]+$`)
+
+// contentImage dimensions and responsive candidates of an uploaded image, or dimensions and poster of a video
+type contentImage struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Srcset candidates with the same aspect ratio as the image, from the smallest to the largest
+	Srcset []*srcsetCandidate `json:"srcset,omitempty"`
+	// Poster url of the poster of video and document
+	Poster string `json:"poster,omitempty"`
+}
+
+type srcsetCandidate struct {
+	URL   string `json:"url"`
+	Width int    `json:"width"`
+}
+
+// rewriteContentImages rewrite the images of uploaded media in the content HTML into responsive markup
+// width and height are added so that the browser can reserve the space before the images are loaded,
+// srcset and sizes are built from the image derivatives and the images are lazy loaded.
+// Attributes already set in the content are left as they are.
+// Images whose src is audio, video or PDF are rendered as players and viewers, see renderMediaEmbed.
+func (svc *Engine) rewriteContentImages(content string) string {
+	if !strings.Contains(content, "<img") {
+		return content
+	}
+
+	var buf bytes.Buffer
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				logger.Errorf("parse content html failed. %s", z.Err())
+				return content
+			}
+			return buf.String()
+		}
+
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			buf.Write(z.Raw())
+			continue
+		}
+
+		// Token unescapes the attributes in the buffer of Raw, so the raw bytes are copied first
+		raw := append([]byte(nil), z.Raw()...)
+		token := z.Token()
+		if token.Data == "img" {
+			if embed := svc.renderMediaEmbed(&token); embed != "" {
+				buf.WriteString(embed)
+				continue
+			}
+		}
+		if token.Data != "img" || !svc.rewriteImage(&token) {
+			buf.Write(raw)
+			continue
+		}
+		buf.WriteString(token.String())
+	}
+}
+
+// rewriteImage add the responsive attributes to the img token, it returns false if the token is not changed
+func (svc *Engine) rewriteImage(token *html.Token) bool {
+	attrs := make(map[string]string, len(token.Attr))
+	for _, attr := range token.Attr {
+		attrs[attr.Key] = attr.Val
+	}
+
+	img := svc.getContentImage(storage.PathOf(attrs["src"]))
+	if img == nil {
+		return false
+	}
+
+	add := func(key, val string) {
+		if _, exist := attrs[key]; !exist {
+			token.Attr = append(token.Attr, html.Attribute{Key: key, Val: val})
+		}
+	}
+
+	_, hasWidth := attrs["width"]
+	_, hasHeight := attrs["height"]
+	if !hasWidth && !hasHeight && img.Width > 0 && img.Height > 0 {
+		add("width", strconv.Itoa(img.Width))
+		add("height", strconv.Itoa(img.Height))
+	}
+
+	if len(img.Srcset) > 1 {
+		candidates := make([]string, 0, len(img.Srcset))
+		for _, c := range img.Srcset {
+			candidates = append(candidates, c.URL+" "+strconv.Itoa(c.Width)+"w")
+		}
+		add("srcset", strings.Join(candidates, ", "))
+		// the image is displayed in its own width at most
+		add("sizes", "(max-width: "+strconv.Itoa(img.Width)+"px) 100vw, "+strconv.Itoa(img.Width)+"px")
+	}
+
+	add("loading", "lazy")
+	return true
+}
+
+// getContentImage get the dimensions and derivatives of the uploaded image by its path
+// The dimensions of the original image are stored in its image size metas, and the dimensions of the derivatives
+// are in their paths. It returns nil if the path is not an uploaded image.
+func (svc *Engine) getContentImage(path string) *contentImage {
+	if !strings.HasPrefix(path, config.UploadPath) {
+		return nil
+	}
+
+	cacheKey := config.CacheContentImagePrefix + path
+	if value, exist := svc.GetCache(cacheKey); exist {
+		img := &contentImage{}
+		svc.JSONUnmarshal(value, img)
+		return img
+	}
+
+	metas, err := svc.dao.ListResourceMetaByGUID(path)
+	if err != nil {
+		logger.Errorf("get image sizes of %s failed. %s", path, err)
+		return nil
+	}
+
+	img := &contentImage{}
+	if len(metas) > 0 {
+		img = newContentImage(metas)
+	} else if m := imageSizePathRegexp.FindStringSubmatch(path); m != nil {
+		img.Width, _ = strconv.Atoi(m[1])
+		img.Height, _ = strconv.Atoi(m[2])
+	}
+
+	// images without sizes are cached too, so that they are not queried again
+	svc.MarshalAndSetCache(cacheKey, img)
+	return img
+}
+
+// newContentImage build the content image from its image size metas and media info
+// Cropped sizes such as thumbnail are not in srcset, since they do not have the same aspect ratio.
+func newContentImage(metas []*model.ResourceMeta) *contentImage {
+	img := &contentImage{}
+	sizes := make(map[string]*model.ImageSizeMeta, len(metas))
+	for _, meta := range metas {
+		if meta.MetaKey == model.ResourceMetaMediaInfo {
+			info := &model.MediaInfoMeta{}
+			if err := json.Unmarshal([]byte(meta.MetaValue), info); err != nil {
+				logger.Errorf("unmarshal media info meta %d failed. %s", meta.ID, err)
+				continue
+			}
+			img.Width, img.Height = info.Width, info.Height
+			continue
+		}
+		if !strings.HasPrefix(meta.MetaKey, model.ResourceMetaImageSizePrefix) {
+			continue
+		}
+
+		size := &model.ImageSizeMeta{}
+		if err := json.Unmarshal([]byte(meta.MetaValue), size); err != nil {
+			logger.Errorf("unmarshal image size meta %d failed. %s", meta.ID, err)
+			continue
+		}
+		sizes[strings.TrimPrefix(meta.MetaKey, model.ResourceMetaImageSizePrefix)] = size
+	}
+
+	if poster := sizes[model.ImageSizePoster]; poster != nil {
+		img.Poster = storage.URL(poster.Path)
+	}
+
+	full := sizes[model.ImageSizeFull]
+	if full == nil || full.Width == 0 || full.Height == 0 {
+		return img
+	}
+	img.Width, img.Height = full.Width, full.Height
+
+	for name, size := range sizes {
+		if name == model.ImageSizePoster || size.Width == 0 || size.Height == 0 || !isSameAspectRatio(full, size) {
+			continue
+		}
+		img.Srcset = append(img.Srcset, &srcsetCandidate{URL: storage.URL(size.Path), Width: size.Width})
+	}
+	sort.Slice(img.Srcset, func(i, j int) bool {
+		return img.Srcset[i].Width < img.Srcset[j].Width
+	})
+
+	return img
+}
+
+// isSameAspectRatio check if the scaled size has the same aspect ratio as the original, allowing the rounding error
+func isSameAspectRatio(full, size *model.ImageSizeMeta) bool {
+	expectedHeight := float64(full.Height) * float64(size.Width) / float64(full.Width)
+	diff := expectedHeight - float64(size.Height)
+	return diff > -1.5 && diff < 1.5
+}
@@ -133,10 +133,12 @@ func (svc *Engine) GetKnowledgeItemContentBySymbol(kiSymbol string) (*ShowKnowle
 	}
 
 	if result != nil {
+		contentHTML := markdown.Markdown2HTML(result.Title, result.Content)
 		output := &ShowKnowledgeItemContent{
 			Symbol:  result.Symbol,
 			Title:   template.HTMLEscapeString(result.Title),
-			Content: template.HTML(markdown.Markdown2HTML(result.Title, result.Content)),
+			Content: template.HTML(svc.rewriteContentImages(contentHTML)),
+			ToC:     markdown.ToC(contentHTML),
 		}
 		return output, nil
 	}
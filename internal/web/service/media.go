@@ -0,0 +1,44 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/storage"
+)
+
+// GetImageSizeURL get the url of the image size, such as thumbnail, medium and large
+// guid is the path or the url of the original image.
+// It returns the original url if the image does not have the size, for example the image is smaller than the size.
+func (svc *Engine) GetImageSizeURL(guid, size string) string {
+	if guid == "" || size == "" || size == model.ImageSizeFull {
+		return guid
+	}
+
+	cacheKey := config.CacheImageSizePrefix + size + "_" + guid
+	if url, exist := svc.GetCache(cacheKey); exist {
+		return string(url)
+	}
+
+	url := guid
+	value, err := svc.dao.GetImageSizeMeta(storage.PathOf(guid), size)
+	if err != nil {
+		logger.Errorf("get %s size of image %s failed. %s", size, guid, err)
+		return guid
+	}
+	if value != "" {
+		meta := &model.ImageSizeMeta{}
+		if err := json.Unmarshal([]byte(value), meta); err != nil {
+			logger.Errorf("unmarshal %s size of image %s failed. %s", size, guid, err)
+		} else if meta.Path != "" {
+			url = storage.URL(meta.Path)
+		}
+	}
+
+	if err := svc.SetCache(cacheKey, []byte(url)); err != nil {
+		logger.Errorf("set cache failed. %s", err)
+	}
+	return url
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/cache"
 	"github.com/puti-projects/puti/internal/pkg/db"
+	"github.com/puti-projects/puti/internal/pkg/markdown"
 	"github.com/puti-projects/puti/internal/utils"
 )
 
@@ -35,7 +36,8 @@ func GetPageDetailByID(pageID uint64) (*ShowPageDetail, error) {
 	pageDetail := &ShowPageDetail{
 		ID:            p.ID,
 		Title:         p.Title,
-		ContentHTML:   template.HTML(p.ContentHTML),
+		ContentHTML:   template.HTML(SrvEngine.rewriteContentImages(p.ContentHTML)),
+		ToC:           markdown.ToC(p.ContentHTML),
 		CommentStatus: p.CommentStatus,
 		GUID:          siteURL + p.GUID,
 		CommentCount:  p.CommentCount,
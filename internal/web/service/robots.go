@@ -0,0 +1,28 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/config"
+)
+
+// GetRobots generate the default robots.txt
+// console and api are disallowed; extra rules are set by the robots_txt option,
+// and the sitemap is always linked, it does not depend on the feed switch open_XML
+func (svc *Engine) GetRobots() string {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	b.WriteString("Disallow: " + config.PathBackend + "\n")
+	b.WriteString("Disallow: " + config.PathAPI + "/\n")
+
+	if rules := strings.TrimSpace(cache.Options.Get("robots_txt")); rules != "" {
+		b.WriteString(strings.ReplaceAll(rules, "\r\n", "\n"))
+		b.WriteString("\n")
+	}
+
+	siteURL := strings.TrimSuffix(cache.Options.Get("site_url"), "/")
+	b.WriteString("\nSitemap: " + siteURL + config.PathSiteMap + "\n")
+
+	return b.String()
+}
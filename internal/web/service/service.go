@@ -42,6 +42,13 @@ func (svc *Engine) SetCache(key string, value []byte) error {
 	return svc.Cache.SetCacheWithByte(key, value)
 }
 
+// DeleteCache delete cache by key during service engine
+func (svc *Engine) DeleteCache(key string) {
+	if err := svc.Cache.DeleteCache(key); err != nil {
+		logger.Errorf("error deleting cache. %s", err)
+	}
+}
+
 // JSONUnmarshal json unmarshal
 func (svc *Engine) JSONUnmarshal(data []byte, v interface{}) {
 	if err := svc.JSON.Unmarshal(data, v); err != nil {
@@ -3,6 +3,9 @@ package service
 import (
 	"database/sql"
 	"html/template"
+
+	"github.com/puti-projects/puti/internal/pkg/markdown"
+	"github.com/puti-projects/puti/internal/utils"
 )
 
 // ShowArticle output article model for list
@@ -64,6 +67,7 @@ type ShowArticleDetail struct {
 	ID            uint64
 	Title         string
 	ContentHTML   template.HTML
+	ToC           []*markdown.Heading
 	CommentStatus uint64
 	GUID          string
 	CommentCount  uint64
@@ -79,6 +83,7 @@ type ShowPageDetail struct {
 	ID            uint64
 	Title         string
 	ContentHTML   template.HTML
+	ToC           []*markdown.Heading
 	CommentStatus uint64
 	GUID          string
 	CommentCount  uint64
@@ -156,4 +161,29 @@ type ShowKnowledgeItemContent struct {
 	Symbol  uint64
 	Title   string
 	Content template.HTML
+	ToC     []*markdown.Heading
+}
+
+// ShowComment comment output model, replies are in children
+type ShowComment struct {
+	ID          uint64
+	ParentID    uint64
+	Author      string
+	AuthorURL   string
+	Avatar      string
+	Content     template.HTML
+	CommentDate string
+	Children    []*ShowComment
+}
+
+// ShowCommentList comment list output model of article or page
+type ShowCommentList struct {
+	PostID     uint64
+	Open       bool
+	NeedLogin  bool
+	ShowAvatar bool
+	Total      int64
+	Comments   []*ShowComment
+	Pagination *utils.Page
+	PageURL    string
 }
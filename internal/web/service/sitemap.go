@@ -0,0 +1,230 @@
+package service
+
+import (
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/config"
+
+	"gorm.io/gorm"
+)
+
+// sitemapMaxURLs the max number of urls in a single sitemap file according to the sitemap protocol
+const sitemapMaxURLs = 50000
+
+type sitemapURL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name           `xml:"urlset"`
+	NS      string             `xml:"xmlns,attr"`
+	URLs    []*sitemapURLEntry `xml:"url"`
+}
+
+type sitemapURLEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name           `xml:"sitemapindex"`
+	NS       string             `xml:"xmlns,attr"`
+	Sitemaps []*sitemapURLEntry `xml:"sitemap"`
+}
+
+// GetSitemap get the sitemap in XML
+// page 0 is the /sitemap.xml, which is a sitemap index if urls are more than the limit of a single sitemap;
+// in that case page n is the nth part of the sitemap.
+// It returns gorm.ErrRecordNotFound if the page does not exist
+func (svc *Engine) GetSitemap(page int) ([]byte, error) {
+	cacheKey := config.CacheSitemapPrefix + svc.getSitemapVersion() + "_" + strconv.Itoa(page)
+	if data, exist := svc.GetCache(cacheKey); exist {
+		return data, nil
+	}
+
+	siteURL := strings.TrimSuffix(cache.Options.Get("site_url"), "/")
+	urls, err := svc.getSitemapURLs(siteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPage := int(math.Ceil(float64(len(urls)) / sitemapMaxURLs))
+	var data []byte
+	switch {
+	case page == 0 && totalPage <= 1:
+		data, err = encodeSitemapURLSet(urls)
+	case page == 0:
+		data, err = encodeSitemapIndex(siteURL, urls, totalPage)
+	case totalPage > 1 && page <= totalPage:
+		end := page * sitemapMaxURLs
+		if end > len(urls) {
+			end = len(urls)
+		}
+		data, err = encodeSitemapURLSet(urls[(page-1)*sitemapMaxURLs : end])
+	default:
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.SetCache(cacheKey, data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// getSitemapVersion get the sitemap cache version which is changed after the content was edited in console
+func (svc *Engine) getSitemapVersion() string {
+	if version, exist := svc.GetCache(config.CacheSitemapVersionKey); exist {
+		return string(version)
+	}
+	return "0"
+}
+
+// getSitemapURLs get urls of home, articles, pages, categories, tags, subjects, knowledge bases and knowledge items
+func (svc *Engine) getSitemapURLs(siteURL string) ([]*sitemapURL, error) {
+	posts, err := svc.dao.ListSitemapPosts()
+	if err != nil {
+		return nil, err
+	}
+	terms, err := svc.dao.ListSitemapTerms()
+	if err != nil {
+		return nil, err
+	}
+	subjects, err := svc.dao.ListSitemapSubjects()
+	if err != nil {
+		return nil, err
+	}
+	knowledge, err := svc.dao.ListSitemapKnowledge()
+	if err != nil {
+		return nil, err
+	}
+	knowledgeItems, err := svc.dao.ListSitemapKnowledgeItems()
+	if err != nil {
+		return nil, err
+	}
+
+	home := &sitemapURL{Loc: siteURL + config.PathRoot}
+	urls := []*sitemapURL{home}
+
+	for _, p := range posts {
+		if p.UpdatedAt.After(home.LastMod) {
+			home.LastMod = p.UpdatedAt
+		}
+		urls = append(urls, &sitemapURL{Loc: siteURL + p.GUID, LastMod: p.UpdatedAt})
+	}
+
+	for _, t := range terms {
+		basePath := config.PathCategory
+		if t.Taxonomy == "tag" {
+			basePath = config.PathTag
+		}
+		urls = append(urls, &sitemapURL{Loc: siteURL + basePath + "/" + t.Slug, LastMod: t.LastUpdated})
+	}
+
+	for _, s := range subjects {
+		lastMod := s.UpdatedAt
+		if s.LastUpdated.Valid && s.LastUpdated.Time.After(lastMod) {
+			lastMod = s.LastUpdated.Time
+		}
+		urls = append(urls, &sitemapURL{Loc: siteURL + config.PathSubject + "/" + s.Slug, LastMod: lastMod})
+	}
+
+	for _, k := range knowledge {
+		lastMod := k.UpdatedAt
+		if k.LastUpdated.Valid && k.LastUpdated.Time.After(lastMod) {
+			lastMod = k.LastUpdated.Time
+		}
+		urls = append(urls, &sitemapURL{Loc: siteURL + "/knowledge/" + k.Type + "/" + k.Slug, LastMod: lastMod})
+	}
+
+	for _, ki := range knowledgeItems {
+		lastMod := ki.UpdatedAt
+		if ki.LastPublished.Valid {
+			lastMod = ki.LastPublished.Time
+		}
+		urls = append(urls, &sitemapURL{
+			Loc:     siteURL + "/knowledge/" + ki.Type + "/" + ki.Slug + "/" + strconv.FormatUint(ki.Symbol, 10),
+			LastMod: lastMod,
+		})
+	}
+
+	return urls, nil
+}
+
+// encodeSitemapURLSet encode urls as a sitemap
+func encodeSitemapURLSet(urls []*sitemapURL) ([]byte, error) {
+	set := &sitemapURLSet{
+		NS:   "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs: make([]*sitemapURLEntry, 0, len(urls)),
+	}
+	for _, u := range urls {
+		set.URLs = append(set.URLs, &sitemapURLEntry{Loc: u.Loc, LastMod: formatSitemapTime(u.LastMod)})
+	}
+
+	return marshalSitemap(set)
+}
+
+// encodeSitemapIndex encode the sitemap index which links to every part of the sitemap
+// lastmod of the part is the latest lastmod of its urls
+func encodeSitemapIndex(siteURL string, urls []*sitemapURL, totalPage int) ([]byte, error) {
+	index := &sitemapIndex{
+		NS:       "http://www.sitemaps.org/schemas/sitemap/0.9",
+		Sitemaps: make([]*sitemapURLEntry, 0, totalPage),
+	}
+	for page := 1; page <= totalPage; page++ {
+		var lastMod time.Time
+		for i := (page - 1) * sitemapMaxURLs; i < page*sitemapMaxURLs && i < len(urls); i++ {
+			if urls[i].LastMod.After(lastMod) {
+				lastMod = urls[i].LastMod
+			}
+		}
+
+		index.Sitemaps = append(index.Sitemaps, &sitemapURLEntry{
+			Loc:     siteURL + SitemapPartPath(page),
+			LastMod: formatSitemapTime(lastMod),
+		})
+	}
+
+	return marshalSitemap(index)
+}
+
+// SitemapPartPath the path of the nth part of the sitemap
+func SitemapPartPath(page int) string {
+	return config.PathSiteMapPart + "/" + strconv.Itoa(page) + ".xml"
+}
+
+// ParseSitemapPart parse the page number from the file name of the sitemap part, such as "1.xml"
+func ParseSitemapPart(name string) (int, error) {
+	if !strings.HasSuffix(name, ".xml") {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	page, err := strconv.Atoi(strings.TrimSuffix(name, ".xml"))
+	if err != nil || page <= 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return page, nil
+}
+
+func formatSitemapTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func marshalSitemap(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
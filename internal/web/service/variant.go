@@ -0,0 +1,83 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/imaging"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/storage"
+)
+
+// GetImageVariant get the path of the image variant which is accepted by the browser, such as WebP and AVIF
+// It returns an empty string if the image does not have a variant in the accepted formats.
+func (svc *Engine) GetImageVariant(path, accept string) string {
+	if !imaging.IsVariantSource(imaging.FormatOf(path)) {
+		return ""
+	}
+
+	formats := svc.getImageVariantFormats(path)
+	for _, format := range imaging.VariantFormats {
+		if formats[format] && isAcceptedType(accept, "image/"+format) {
+			return imaging.VariantPath(path, format)
+		}
+	}
+	return ""
+}
+
+// getImageVariantFormats get the variant formats of the image which exist in the storage
+func (svc *Engine) getImageVariantFormats(path string) map[string]bool {
+	formats := make(map[string]bool)
+
+	cacheKey := config.CacheImageVariantPrefix + path
+	if value, exist := svc.GetCache(cacheKey); exist {
+		for _, format := range strings.Split(string(value), ",") {
+			formats[format] = true
+		}
+		return formats
+	}
+
+	found := make([]string, 0, len(imaging.VariantFormats))
+	for _, format := range imaging.VariantFormats {
+		_, err := storage.Engine.Stat(imaging.VariantPath(path, format))
+		if err == storage.ErrNotExist {
+			continue
+		}
+		if err != nil {
+			// not cached, try again in the next request
+			logger.Errorf("stat %s variant of %s failed. %s", format, path, err)
+			return formats
+		}
+		formats[format] = true
+		found = append(found, format)
+	}
+
+	if err := svc.SetCache(cacheKey, []byte(strings.Join(found, ","))); err != nil {
+		logger.Errorf("set cache failed. %s", err)
+	}
+	return formats
+}
+
+// isAcceptedType check if the mime-type is accepted by the Accept header with q > 0
+// wildcards such as image/* are not counted, since browsers send them without supporting all images.
+func isAcceptedType(accept, mimeType string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), mimeType) {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q <= 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -2,12 +2,13 @@ package view
 
 import (
 	"errors"
-	"github.com/puti-projects/puti/internal/pkg/config"
 	"html/template"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/puti-projects/puti/internal/model"
+	"github.com/puti-projects/puti/internal/pkg/config"
 	"github.com/puti-projects/puti/internal/pkg/counter"
 	"github.com/puti-projects/puti/internal/web/service"
 
@@ -116,6 +117,7 @@ func ShowArticleDetail(c *gin.Context) {
 		renderData["LastArticle"] = (*s)["LastArticle"]
 		renderData["NextArticle"] = (*s)["NextArticle"]
 		renderData["Title"] = articleDetail["Title"].(string) + " - " + renderData["Setting"].(map[string]interface{})["BlogName"].(string)
+		renderData["Comments"] = getComments(c, aID, model.PostTypeArticle, uint64(articleDetail["CommentStatus"].(float64)))
 	} else {
 		articleDetail, err := service.GetArticleDetailByID(aID)
 		if err != nil {
@@ -132,6 +134,7 @@ func ShowArticleDetail(c *gin.Context) {
 		renderData["LastArticle"] = service.GetLastArticle(aID)
 		renderData["NextArticle"] = service.GetNextArticle(aID)
 		renderData["Title"] = renderData["Article"].(*service.ShowArticleDetail).Title + " - " + renderData["Setting"].(map[string]interface{})["BlogName"].(string)
+		renderData["Comments"] = getComments(c, aID, model.PostTypeArticle, articleDetail.CommentStatus)
 
 		// set cache
 		articleDetailCache := map[string]interface{}{
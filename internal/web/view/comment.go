@@ -0,0 +1,84 @@
+package view
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/puti-projects/puti/internal/pkg/errno"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/token"
+	"github.com/puti-projects/puti/internal/web/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PostArticleComment handle comment posted on article detail
+func PostArticleComment(c *gin.Context) {
+	articleID := strings.Split(c.Param("id"), ".")[0]
+	ID, _ := strconv.Atoi(articleID)
+
+	createComment(c, uint64(ID))
+}
+
+// PostPageComment handle comment posted on page detail
+func PostPageComment(c *gin.Context, pageID uint64) {
+	createComment(c, pageID)
+}
+
+func createComment(c *gin.Context, postID uint64) {
+	var r service.CommentCreateRequest
+	if err := c.ShouldBind(&r); err != nil {
+		sendCommentResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	// login user comment as himself; visitor context is nil
+	var user *token.Context
+	if u, err := token.ParseCookie(c); err == nil {
+		user = u
+	}
+
+	rsp, err := service.SrvEngine.CreateComment(postID, &r, user, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			sendCommentResponse(c, errno.ErrCommentClosed, nil)
+			return
+		}
+
+		sendCommentResponse(c, err, nil)
+		return
+	}
+
+	sendCommentResponse(c, nil, rsp)
+}
+
+// sendCommentResponse send response in the same format as api
+func sendCommentResponse(c *gin.Context, err error, data interface{}) {
+	code, message := errno.DecodeErr(err)
+	if code == errno.InternalServerError.Code {
+		logger.Errorf("create comment failed. %s", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    code,
+		"message": message,
+		"data":    data,
+	})
+}
+
+// getComments get comment list of the post for rendering
+// comment page is specified by query "cpage"
+func getComments(c *gin.Context, postID uint64, postType string, commentStatus uint64) *service.ShowCommentList {
+	currentPage, _ := strconv.Atoi(c.Query("cpage"))
+
+	comments, err := service.SrvEngine.GetCommentList(postID, postType, commentStatus, c.Request.URL.Path, currentPage)
+	if err != nil {
+		logger.Errorf("get comment list failed. %s", err)
+		return nil
+	}
+
+	return comments
+}
@@ -0,0 +1,43 @@
+package view
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/web/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ShowFeed returns the feed handler of RSS or Atom
+// scope is empty for the site-wide feed, or category, tag and subject with the slug param
+func ShowFeed(feedType, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// feed output is switched by the open_XML option
+		if cache.Options.Get("open_XML") != "on" {
+			ShowNotFound(c)
+			return
+		}
+
+		data, err := service.SrvEngine.GetFeed(feedType, scope, c.Param("slug"))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				ShowNotFound(c)
+				return
+			}
+
+			logger.Errorf("get feed failed. %s", err)
+			ShowInternalServerError(c)
+			return
+		}
+
+		contentType := "application/rss+xml; charset=utf-8"
+		if feedType == service.FeedTypeAtom {
+			contentType = "application/atom+xml; charset=utf-8"
+		}
+		c.Data(http.StatusOK, contentType, data)
+	}
+}
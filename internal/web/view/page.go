@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/puti-projects/puti/internal/model"
 	"github.com/puti-projects/puti/internal/pkg/config"
 	"github.com/puti-projects/puti/internal/pkg/counter"
 	"github.com/puti-projects/puti/internal/web/service"
@@ -14,11 +15,16 @@ import (
 
 // CheckAndShowPage check whether page exist
 func CheckAndShowPage(c *gin.Context) {
-	if !strings.HasPrefix(c.Request.RequestURI, "/themes") {
-		// no static, and get slug
-		slug := strings.TrimLeft(c.Request.RequestURI, "/")
+	if !strings.HasPrefix(c.Request.URL.Path, "/themes") {
+		// no static, and get slug (query string such as comment page is excluded)
+		slug := strings.TrimLeft(c.Request.URL.Path, "/")
 		if !strings.Contains(slug, "/") {
 			if pageID := service.GetPageIDBySlug(slug); pageID > 0 {
+				if c.Request.Method == http.MethodPost {
+					PostPageComment(c, pageID)
+					return
+				}
+
 				ShowPageDetail(c, pageID)
 				return
 			}
@@ -47,6 +53,7 @@ func ShowPageDetail(c *gin.Context, pageID uint64) {
 		service.SrvEngine.MarshalAndSetCache(config.CachePageDetailPrefix+strconv.Itoa(int(pageID)), pageDetail)
 	}
 	renderData["Page"] = pageDetail
+	renderData["Comments"] = getComments(c, pageID, model.PostTypePage, pageDetail.CommentStatus)
 
 	counter.CounterCache.CountOne(c.ClientIP(), pageID)
 
@@ -0,0 +1,25 @@
+package view
+
+import (
+	"net/http"
+
+	"github.com/puti-projects/puti/internal/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShowRegister show the registration page of theme
+// the page is not found if the users_can_register option is off
+func ShowRegister(c *gin.Context) {
+	if cache.Options.Get("users_can_register") != "on" {
+		ShowNotFound(c)
+		return
+	}
+
+	// get renderer data include basic data
+	renderData := getRenderData(c)
+
+	renderData["Widgets"] = getWidgets()
+	renderData["Title"] = "注册" + " - " + renderData["Setting"].(map[string]interface{})["BlogName"].(string)
+	c.HTML(http.StatusOK, getTheme(c)+"/register.html", renderData)
+}
@@ -0,0 +1,25 @@
+package view
+
+import (
+	"net/http"
+
+	"github.com/puti-projects/puti/internal/pkg/cache"
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/theme"
+	"github.com/puti-projects/puti/internal/web/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShowRobots robots.txt handler
+// robots.txt of the current theme is preferred, otherwise it is generated by setting
+func ShowRobots(c *gin.Context) {
+	// theme is got from option cache every time, so switching theme takes effect immediately
+	currentTheme := cache.Options.Get("current_theme")
+	if t, ok := theme.Themes[currentTheme]; ok && t.RobotsExist {
+		c.File(config.StaticPath(config.StaticPathTheme + "/" + t.Name + config.PathRobots))
+		return
+	}
+
+	c.String(http.StatusOK, service.SrvEngine.GetRobots())
+}
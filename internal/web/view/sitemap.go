@@ -0,0 +1,44 @@
+package view
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/web/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ShowSitemap sitemap or sitemap index handler
+func ShowSitemap(c *gin.Context) {
+	showSitemap(c, 0)
+}
+
+// ShowSitemapPart part of sitemap handler, which is linked by sitemap index
+func ShowSitemapPart(c *gin.Context) {
+	page, err := service.ParseSitemapPart(c.Param("page"))
+	if err != nil {
+		ShowNotFound(c)
+		return
+	}
+
+	showSitemap(c, page)
+}
+
+func showSitemap(c *gin.Context, page int) {
+	data, err := service.SrvEngine.GetSitemap(page)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ShowNotFound(c)
+			return
+		}
+
+		logger.Errorf("get sitemap failed. %s", err)
+		ShowInternalServerError(c)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
@@ -0,0 +1,80 @@
+package view
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/puti-projects/puti/internal/pkg/config"
+	"github.com/puti-projects/puti/internal/pkg/imaging"
+	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/storage"
+	"github.com/puti-projects/puti/internal/web/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShowUpload uploaded file handler, which serves the file from the storage
+// JPEG and PNG images are served as WebP or AVIF if the browser accepts them and the variants were generated,
+// and the original file is served otherwise.
+func ShowUpload(c *gin.Context) {
+	path := config.UploadPath + strings.TrimPrefix(c.Param("filepath"), "/")
+
+	// responses of images depend on the Accept header
+	if imaging.IsVariantSource(imaging.FormatOf(path)) {
+		c.Header("Vary", "Accept")
+	}
+	variant := service.SrvEngine.GetImageVariant(path, c.GetHeader("Accept"))
+
+	if url := storage.URL(path); url != path {
+		if variant != "" {
+			url = storage.URL(variant)
+		}
+		c.Redirect(http.StatusFound, url)
+		return
+	}
+
+	var r io.ReadCloser
+	var info *storage.FileInfo
+	var err error
+	if variant != "" {
+		// fall back to the original file if the variant is removed
+		r, info, err = storage.Engine.Get(variant)
+	}
+	if variant == "" || err == storage.ErrNotExist {
+		r, info, err = storage.Engine.Get(path)
+	}
+	if err == storage.ErrNotExist || err == storage.ErrInvalidPath {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("get uploaded file %s failed. %s", path, err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	defer r.Close()
+
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "public, max-age=2592000")
+
+	// local files support range requests, such as seeking in video
+	if rs, ok := r.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, "", info.ModTime, rs)
+		return
+	}
+
+	if !info.ModTime.IsZero() {
+		if t, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !info.ModTime.Truncate(time.Second).After(t) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.Header("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+	c.DataFromReader(http.StatusOK, info.Size, contentType, r, nil)
+}
@@ -10,11 +10,13 @@ import (
 	"syscall"
 	"time"
 
+	adminService "github.com/puti-projects/puti/internal/admin/service"
 	"github.com/puti-projects/puti/internal/pkg/cache"
 	"github.com/puti-projects/puti/internal/pkg/config"
 	"github.com/puti-projects/puti/internal/pkg/counter"
 	"github.com/puti-projects/puti/internal/pkg/db"
 	"github.com/puti-projects/puti/internal/pkg/logger"
+	"github.com/puti-projects/puti/internal/pkg/storage"
 	"github.com/puti-projects/puti/internal/pkg/theme"
 	v "github.com/puti-projects/puti/internal/pkg/version"
 	"github.com/puti-projects/puti/internal/routers"
@@ -64,6 +66,11 @@ func init() {
 		logger.Panicf("database connection failed. error(%v)", err)
 	}
 
+	// init storage of uploads
+	if err := storage.Init(); err != nil {
+		logger.Panicf("storage initialization failed. error(%v)", err)
+	}
+
 	// load theme path
 	theme.LoadInstalled()
 }
@@ -82,6 +89,9 @@ func main() {
 	}
 	logger.Info("options has been deployed successfully")
 
+	// markdown engine uses the markdown options
+	adminService.ApplyMarkdownOptions()
+
 	// new service engine for frontend as a global engine
 	if err := service.NewServiceEngine(); err != nil {
 		logger.Panicf("new service engine failed, %v", err)
@@ -99,6 +109,8 @@ func main() {
 
 	// init ticker
 	counter.InitCountTicker()
+	adminService.InitPublishTicker()
+	adminService.InitChunkUploadCleanTicker()
 
 	// listen and serve http
 	httpServe(router)
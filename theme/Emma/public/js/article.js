@@ -0,0 +1,16 @@
+$(document).ready(function() {
+    /* 回到顶部 */
+    var $backToTop = $(".bottom-tools");
+    /* 隐藏回顶部按钮 */
+    $backToTop.hide();
+    $(window).on('scroll', function() {
+        if ($(this).scrollTop() > 200) {
+            $backToTop.fadeIn();
+        } else {
+            $backToTop.fadeOut();
+        }
+    });
+    $backToTop.on('click', function(e) {
+        $("html, body").animate({scrollTop: 0}, 500);
+    });
+});
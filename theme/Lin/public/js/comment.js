@@ -0,0 +1,46 @@
+$(document).ready(function() {
+    /**
+     * 回复：把主评论框移动到被回复的评论下
+     */
+    var $form = $('#main-reply');
+    var $home = $('<div id="main-reply-home"></div>').insertBefore($form);
+
+    $('.reply').click(function(){
+        var id = $(this).data('id');
+        $form.find('input[name="parent_id"]').val(id);
+        $form.insertAfter($(this).closest('.content'))
+            .transition('pulse')
+        ;
+    });
+
+    /**
+     * 提交评论
+     */
+    $form.submit(function(event) {
+        event.preventDefault();
+
+        var $message = $form.find('.comment-message');
+        var $button = $form.find('button[type="submit"]');
+        $button.addClass('loading disabled');
+
+        $.post($form.attr('action'), $form.serialize(), function(rsp) {
+            $button.removeClass('loading disabled');
+            $message.removeClass('hidden negative positive');
+            if (rsp.code !== 0) {
+                $message.addClass('negative').text(rsp.message);
+                return;
+            }
+
+            if (rsp.data.pending) {
+                $message.addClass('positive').text('评论已提交，审核通过后显示');
+                $form.find('textarea').val('');
+                $form.insertAfter($home);
+                $form.find('input[name="parent_id"]').val(0);
+                return;
+            }
+
+            window.location.href = rsp.data.url;
+            window.location.reload();
+        }, 'json');
+    });
+});